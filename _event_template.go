@@ -19,16 +19,11 @@ type templateListener func(info string)
 
 // OnTemplate in order to receive Template changes.
 func (c *Client) OnTemplate(listener templateListener) error {
-	if _, found := c.events["OnTemplate"]; !found {
-
+	return c.addSubscribedListener("OnTemplate", listener, func() error {
 		// Subscribe to the template collection
-		if err := c.ddpSubscribe(bbb.template, c.updateTemplate); err != nil {
-			return err
-		}
-	}
-
-	c.events["OnTemplate"] = append(c.events["OnStatus"], listener)
-	return nil
+		_, err := c.ddpSubscribe(bbb.template, c.updateTemplate)
+		return err
+	})
 }
 
 // informs all listeners with the new info
@@ -36,7 +31,7 @@ func (c *Client) updateTemplate(collection string, operation string, id string,
 	//Read data from doc
 	info := convert.String(doc["info"], "")
 	// Inform all listeners
-	for _, event := range c.events["OnTemplate"] {
+	for _, event := range c.getListeners("OnTemplate") {
 		// call event(info)
 		f := reflect.TypeOf(event)
 		if f.Kind() == reflect.Func { //is function
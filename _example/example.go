@@ -152,7 +152,7 @@ func main() {
 	}
 	defer oggFile.Close()
 
-	audio.OnTrack(func(status *bot.StatusType, track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
+	audio.OnTrack(func(status func() bot.StatusType, track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
 		// Only handle audio tracks
 		if track.Kind() != webrtc.RTPCodecTypeAudio {
 			return
@@ -163,7 +163,7 @@ func main() {
 			for {
 				n, _, readErr := track.Read(buffer)
 
-				if *status == bot.DISCONNECTED {
+				if status() == bot.DISCONNECTED {
 					return
 				}
 
@@ -11,6 +11,8 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
 )
 
 type SHA string
@@ -24,6 +26,24 @@ type ApiRequest struct {
 	Url     string
 	Secret  string
 	Shatype SHA
+
+	logger logger.Logger // nil until SetLogger is called (see log)
+}
+
+// SetLogger sets the logger for the api requests. *slog.Logger can be used.
+func (api *ApiRequest) SetLogger(l logger.Logger) {
+	if l == nil {
+		l = logger.Nop()
+	}
+	api.logger = l
+}
+
+// Returns the logger of the api requests
+func (api *ApiRequest) log() logger.Logger {
+	if api.logger == nil {
+		return logger.Default()
+	}
+	return api.logger
 }
 
 // Create an object for making http get api requests to the BBB server.
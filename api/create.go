@@ -2,7 +2,6 @@ package api
 
 import (
 	"errors"
-	"fmt"
 	"strconv"
 )
 
@@ -96,7 +95,7 @@ func (api *ApiRequest) CreateMeeting(name string, meetingID string, attendeePW s
 
 	// Check if meeting already exists (duplicateWarning)
 	if response.MessageKey == "duplicateWarning" {
-		fmt.Println("Warning: Meeting already exists")
+		api.log().Warn("meeting already exists", "meeting", meetingID)
 	}
 
 	return meetings[response.MeetingID], nil
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+)
+
+type responseEnter struct {
+	Response struct {
+		ReturnCode      string `json:"returncode"`
+		MessageKey      string `json:"messageKey"`
+		Message         string `json:"message"`
+		MeetingID       string `json:"meetingID"` // internal meeting id
+		ExternMeetingID string `json:"externMeetingID"`
+		InternalUserID  string `json:"internalUserID"`
+		AuthToken       string `json:"authToken"`
+		FullName        string `json:"fullname"`
+	} `json:"response"`
+}
+
+// Opens a join url the server created (e.g. the redirectToHtml5JoinURL of a breakout room) and makes a
+// http get request to the enter endpoint of the BigBlueButton API (like the HTML5 client does). Returns:
+// - cookie
+// - userid
+// - auth_token
+// - session_token
+// - internal_meeting_id
+// - external_meeting_id
+// - full_name
+// - error
+// The join url already has its checksum. It redirects to the HTML5 client with the session token.
+func (api *ApiRequest) Enter(joinURL string) ([]*http.Cookie, string, string, string, string, string, string, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, "", "", "", "", "", "", err
+	}
+	client := &http.Client{Jar: jar}
+
+	//Make a http get request to the join url and follow the redirect
+	resp, err := client.Get(joinURL)
+	if err != nil {
+		return nil, "", "", "", "", "", "", err
+	}
+	resp.Body.Close()
+	sessionToken := resp.Request.URL.Query().Get("sessionToken")
+	if sessionToken == "" {
+		return nil, "", "", "", "", "", "", errors.New("join url did not redirect with a session token. Server returned: " + resp.Status)
+	}
+
+	requestURL := api.Url + "enter?sessionToken=" + url.QueryEscape(sessionToken)
+	resp, err = client.Get(requestURL)
+	if err != nil {
+		return nil, "", "", "", "", "", "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", "", "", "", "", "", err
+	}
+
+	//Unmarshal json
+	var response responseEnter
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, "", "", "", "", "", "", errors.New("Server returned: " + resp.Status)
+	}
+
+	//Check if the request was successful
+	if response.Response.ReturnCode != "SUCCESS" {
+		return nil, "", "", "", "", "", "", errors.New(response.Response.MessageKey + ": " + response.Response.Message)
+	}
+
+	r := response.Response
+	return jar.Cookies(resp.Request.URL), r.InternalUserID, r.AuthToken, sessionToken, r.MeetingID, r.ExternMeetingID, r.FullName, nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+type responseGuestWait struct {
+	Response struct {
+		ReturnCode   string `json:"returncode"`
+		MessageKey   string `json:"messageKey"`
+		Message      string `json:"message"`
+		GuestStatus  string `json:"guestStatus"`
+		LobbyMessage string `json:"lobbyMessage"`
+		URL          string `json:"url"`
+	} `json:"response"`
+}
+
+// Makes a http get request to the guestWait endpoint of the BigBlueButton API (like the HTML5 client does
+// while a guest waits in the lobby) and returns:
+// - guest_status (ALLOW, WAIT or DENY)
+// - lobby_message
+// - error
+// The endpoint does not need a checksum. The session token of the join response is used instead.
+func (api *ApiRequest) GuestWait(sessionToken string) (string, string, error) {
+	requestURL := api.Url + "guestWait?sessionToken=" + url.QueryEscape(sessionToken) + "&redirect=false"
+
+	//Make a http get request to the BigBlueButton API
+	client := new(http.Client)
+	req, _ := http.NewRequest("GET", requestURL, nil)
+	resp, err := client.Do(req) //send request
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+
+	//Unmarshal json
+	var response responseGuestWait
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return "", "", errors.New("Server returned: " + resp.Status)
+	}
+
+	//Check if the request was successful
+	if response.Response.ReturnCode != "SUCCESS" && response.Response.GuestStatus == "" {
+		return "", "", errors.New(response.Response.MessageKey + ": " + response.Response.Message)
+	}
+
+	return response.Response.GuestStatus, response.Response.LobbyMessage, nil
+}
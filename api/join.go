@@ -28,10 +28,24 @@ type responseJoin struct {
 // - internal_meeting_id
 // - error
 func (api *ApiRequest) Join(meetingID string, userName string, moderator bool) (string, []*http.Cookie, string, string, string, string, error) {
+	url, cookie, userID, authToken, sessionToken, internalMeetingID, _, err := api.JoinWithGuestStatus(meetingID, userName, moderator)
+	return url, cookie, userID, authToken, sessionToken, internalMeetingID, err
+}
+
+// Makes a http get request to the BigBlueButton API to join a meeting and returs:
+// - url
+// - cookie
+// - userid
+// - auth_token
+// - session_token
+// - internal_meeting_id
+// - guest_status (ALLOW, WAIT or DENY. If it is WAIT, the user has to wait for a moderator. See GuestWait)
+// - error
+func (api *ApiRequest) JoinWithGuestStatus(meetingID string, userName string, moderator bool) (string, []*http.Cookie, string, string, string, string, string, error) {
 
 	meetings, err := api.GetMeetings()
 	if err != nil {
-		return "", nil, "", "", "", "", err
+		return "", nil, "", "", "", "", "", err
 	}
 
 	m := meetings[meetingID]
@@ -65,10 +79,10 @@ func (api *ApiRequest) Join(meetingID string, userName string, moderator bool) (
 	var response responseJoin
 	err = api.makeRequest(&response, JOIN, Params...)
 	if err != nil {
-		return "", nil, "", "", "", "", err
+		return "", nil, "", "", "", "", "", err
 	}
 
-	return response.URL, response.Cookie, response.UserID, response.AuthToken, response.SessionToken, response.MeetingID, nil
+	return response.URL, response.Cookie, response.UserID, response.AuthToken, response.SessionToken, response.MeetingID, response.GuestStatus, nil
 }
 
 // Makes a http get request to the BigBlueButton API to join a meeting and returs:
@@ -3,153 +3,596 @@ package bot
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
-	"net/http/cookiejar"
-	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 
 	"time"
 
 	"github.com/pion/interceptor"
+	"github.com/pion/interceptor/pkg/stats"
 
 	"github.com/pion/sdp/v3"
 	"github.com/pion/webrtc/v3"
 
-	"github.com/gorilla/websocket"
-
-
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
 )
 
 type AudioClient struct {
-	Client
+	// the client that joined the meeting (see Client)
+	client *Client
+
+	// how long ListenToAudio and PublishAudio (and a rejoin) wait until the audio flows
+	ConnectTimeout time.Duration
+	// how often a ping is sent to the signalling server and how long to wait for the pong
+	PingInterval time.Duration
+	PingTimeout  time.Duration
+	// exchange the ICE candidates with iceCandidate messages instead of waiting for all of them
+	TrickleICE bool
+	// rejoin the audio if the peer connection fails or the signalling server closes the connection
+	AutoRejoin bool
+	// delay before the first rejoin. It is doubled after every failed rejoin (up to maxRejoinDelay).
+	RejoinDelay time.Duration
+	// how often to try to rejoin (0 tries until Close is called)
+	MaxRejoins int
+	// how often the stats are collected (see OnStats). 0 does not collect stats.
+	StatsInterval time.Duration
+	// the STUN/TURN servers and the network of the peer connection
+	ICE ICEConfig
+
+	mutex *sync.Mutex
+
+	// the current connection (signalling and peer connection)
+	connection     *audioConnection
 	peerConnection *webrtc.PeerConnection
+	closing        bool
+	// counts the sessions (ListenToAudio and PublishAudio), so the rejoins of an old session stop
+	session int
+
+	status          StatusType
+	statusListeners []statusListener
+
+	// the last stats (see OnStats)
+	stats          AudioStats
+	statsListeners []audioStatsListener
+
+	// listeners for the remote tracks. Tracks that arrive without a listener are kept for the next listener.
+	trackListeners []func(*webrtc.TrackRemote, *webrtc.RTPReceiver)
+	pendingTracks  []remoteTrack
+	// receivers of ReceiveAudio (closed by Close)
+	receivers []*audio.Receiver
+
+	// how the audio was joined (see ListenToAudio and PublishAudio)
+	role AudioRole
+	// the audio track of the bot (only if the bot publishes audio)
+	localTrack  *webrtc.TrackLocalStaticSample
+	frameWriter *OpusFrameWriter
+}
 
-	pingStopChan chan bool
-
-	status StatusType
+type remoteTrack struct {
+	track    *webrtc.TrackRemote
+	receiver *webrtc.RTPReceiver
 }
 
+// The delay between the rejoins does not get longer than this
+const maxRejoinDelay = 30 * time.Second
+
+// The meeting of the audio is not running anymore (no rejoin)
+var errMeetingNotRunning = errors.New("meeting is not running")
+
+// CreateAudioChannel creates an AudioClient for the meeting of the client (see ListenToAudio and PublishAudio)
 func (c* Client) CreateAudioChannel() (*AudioClient) {
 	return &AudioClient{
-		Client: *c,
+		client: c,
+
+		ConnectTimeout: 30 * time.Second,
+		PingInterval:   15 * time.Second,
+		PingTimeout:    5 * time.Second,
+		TrickleICE:     true,
+		AutoRejoin:     true,
+		RejoinDelay:    2 * time.Second,
+		MaxRejoins:     0,
+		StatsInterval:  5 * time.Second,
+		ICE:            DefaultICEConfig(),
+
+		mutex: new(sync.Mutex),
+
+		connection:     nil,
 		peerConnection: nil,
 
-		pingStopChan: nil,
+		status:          DISCONNECTED,
+		statusListeners: []statusListener{},
+
+		statsListeners: []audioStatsListener{},
+
+		trackListeners: []func(*webrtc.TrackRemote, *webrtc.RTPReceiver){},
+		pendingTracks:  []remoteTrack{},
+		receivers:      []*audio.Receiver{},
+
+		role:        AudioRoleRecv,
+		localTrack:  nil,
+		frameWriter: nil,
+	}
+}
+
+// Remember the connected audio client, so it can be closed with the session
+func (c *Client) addAudioClient(audioClient *AudioClient) {
+	c.audioMutex.Lock()
+	defer c.audioMutex.Unlock()
+	for _, a := range c.audioClients {
+		if a == audioClient {
+			return
+		}
+	}
+	c.audioClients = append(c.audioClients, audioClient)
+}
+
+func (c *Client) removeAudioClient(audioClient *AudioClient) {
+	c.audioMutex.Lock()
+	defer c.audioMutex.Unlock()
+	for i, a := range c.audioClients {
+		if a == audioClient {
+			c.audioClients = append(c.audioClients[:i], c.audioClients[i+1:]...)
+			return
+		}
+	}
+}
+
+// Client returns the client the audio belongs to
+func (c *AudioClient) Client() *Client {
+	return c.client
+}
+
+// Returns the logger with the fields of the audio client
+func (c *AudioClient) log() logger.Logger {
+	return logger.With(c.client.log(), "component", "audio")
+}
+
+// OnStatus in order to receive the status of the audio (CONNECTING, CONNECTED, RECONNECTING, DISCONNECTING, DISCONNECTED)
+func (c *AudioClient) OnStatus(listener statusListener) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.statusListeners = append(c.statusListeners, listener)
+}
+
+// GetStatus returns the status of the audio
+func (c *AudioClient) GetStatus() StatusType {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.status
+}
+
+func (c *AudioClient) setStatus(status StatusType) {
+	c.mutex.Lock()
+	listeners := c.changeStatus(status)
+	c.mutex.Unlock()
+
+	emitStatus(status, listeners)
+}
+
+// Change the status (c.mutex has to be locked). Returns the listeners that have to be called.
+func (c *AudioClient) changeStatus(status StatusType) []statusListener {
+	if c.status == status {
+		return nil
+	}
+	c.status = status
+	c.log().Debug("audio status changed", "status", string(status))
+	return append([]statusListener{}, c.statusListeners...)
+}
 
-		status: DISCONNECTED,
+func emitStatus(status StatusType, listeners []statusListener) {
+	for _, listener := range listeners {
+		go listener(status)
 	}
 }
 
 // ListenToAudio joins the audio channel of the meeting and starts listening to the audio stream.
+// After Close it can be called again for a new session.
 func (c *AudioClient) ListenToAudio() error {
+	return c.join(AudioRoleRecv, nil)
+}
+
+// Join the audio and keep the connection (see AutoRejoin). track is the audio of the bot, if it publishes audio.
+func (c *AudioClient) join(role AudioRole, track *webrtc.TrackLocalStaticSample) error {
+	if !c.client.isSessionActive() {
+		return errors.New("could not join audio: the client has not joined a meeting")
+	}
+
+	c.mutex.Lock()
+	if c.status != DISCONNECTED {
+		c.mutex.Unlock()
+		return errors.New("audio is already connected (status " + string(c.status) + ")")
+	}
+	c.closing = false
+	c.session++
+	session := c.session
+	c.role = role
+	c.localTrack = track
+	listeners := c.changeStatus(CONNECTING)
+	c.mutex.Unlock()
+	emitStatus(CONNECTING, listeners)
 
-	c.status = CONNECTING
+	// the audio is closed when the client leaves the meeting
+	c.client.addAudioClient(c)
 
-	// Get the STUN and TURN servers
-	stunServers, turnServers, err := c.GetStunTurnServers()
+	connection, err := c.connect(role)
 	if err != nil {
+		c.client.removeAudioClient(c)
+		c.mutex.Lock()
+		c.localTrack = nil
+		c.mutex.Unlock()
+		c.setStatus(DISCONNECTED)
 		return err
 	}
-	if len(turnServers) == 0 {
-		return errors.New("bbb api: No turn servers provided")
+
+	c.mutex.Lock()
+	if c.closing {
+		// Close was called while connecting
+		c.mutex.Unlock()
+		connection.close()
+		c.setStatus(DISCONNECTED)
+		return errors.New("audio was closed while connecting")
 	}
+	c.connection = connection
+	c.peerConnection = connection.peerConnection
+	c.mutex.Unlock()
 
+	c.setStatus(CONNECTED)
+	go c.supervise(connection, role, session)
 
-	// Make api request to get all information of this meeting (VoiceBridge, CaleeName, UserID, UserName)
-	meetings, err := c.API.GetMeetings()
-	if err != nil {
-		return err
+	return nil
+}
+
+// Wait until the connection fails and rejoin
+func (c *AudioClient) supervise(connection *audioConnection, role AudioRole, session int) {
+	go c.collectStats(connection)
+	<-connection.failed
+
+	c.mutex.Lock()
+	if c.closing || c.connection != connection {
+		c.mutex.Unlock()
+		return
 	}
-	meeting := meetings[c.ExternalMeetingID]
-	voiceBridge := meeting.VoiceBridge
-	caleeName := "GLOBAL_AUDIO_" + strconv.FormatInt(int64(voiceBridge), 10)
+	c.connection = nil
+	c.mutex.Unlock()
 
+	c.log().Warn("audio connection failed", "reason", connection.reason)
+	connection.close()
 
+	if !c.AutoRejoin {
+		c.setStatus(DISCONNECTED)
+		return
+	}
+	c.rejoin(role, session)
+}
 
-	// Connect to the signalling server
-	wscon, err := connectToWebSocketSignallingServer(c.WebRTCWSURL, c.SessionToken, c.SessionCookie)
-	if err != nil {
-		return err
+// Rejoin the audio until it works, Close is called or MaxRejoins is reached
+func (c *AudioClient) rejoin(role AudioRole, session int) {
+	delay := c.RejoinDelay
+	for try := 1; c.MaxRejoins == 0 || try <= c.MaxRejoins; try++ {
+		if c.sessionEnded(session) {
+			return
+		}
+		c.setStatus(RECONNECTING)
+		time.Sleep(delay)
+		if c.sessionEnded(session) {
+			return
+		}
+
+		if !c.client.isSessionActive() {
+			c.log().Info("not rejoining audio: the client left the meeting")
+			break
+		}
+
+		c.log().Info("rejoining audio", "try", try)
+		connection, err := c.connect(role)
+		if err == nil {
+			c.mutex.Lock()
+			if c.closing || c.session != session {
+				c.mutex.Unlock()
+				connection.close()
+				return
+			}
+			c.connection = connection
+			c.peerConnection = connection.peerConnection
+			c.mutex.Unlock()
+
+			c.setStatus(CONNECTED)
+			go c.supervise(connection, role, session)
+			return
+		}
+
+		c.log().Warn("could not rejoin audio", "try", try, "error", err)
+		if errors.Is(err, errMeetingNotRunning) {
+			break
+		}
+		delay *= 2
+		if delay > maxRejoinDelay {
+			delay = maxRejoinDelay
+		}
 	}
 
-	// Send join message
-	err = sendJoinMessage(wscon, c.InternalMeetingID, voiceBridge, caleeName, c.InternalUserID, c.UserName)
-	if err != nil {
-		return err
+	if !c.sessionEnded(session) {
+		c.setStatus(DISCONNECTED)
 	}
+}
 
-	// Read join response
-	joinResponse, err := readJoinSDPResponse(wscon)
-	if err != nil {
-		return err
+// true after Close was called or a new session was started
+func (c *AudioClient) sessionEnded(session int) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.closing || c.session != session
+}
+
+// audioConnection is one connection to the audio (signalling and peer connection)
+type audioConnection struct {
+	signalling     *signalling
+	peerConnection *webrtc.PeerConnection
+	stats          stats.Getter // the RTP stats of the peer connection
+
+	failOnce *sync.Once
+	failed   chan struct{} // closed when the connection failed
+	reason   string
+}
+
+func newAudioConnection() *audioConnection {
+	return &audioConnection{
+		failOnce: new(sync.Once),
+		failed:   make(chan struct{}),
 	}
-	sdpOffer := joinResponse.SdpAnswer
+}
 
+// Mark the connection as failed
+func (a *audioConnection) fail(reason string) {
+	a.failOnce.Do(func() {
+		a.reason = reason
+		close(a.failed)
+	})
+}
+
+// Close the signalling and the peer connection
+func (a *audioConnection) close() error {
+	a.fail("closed")
 
+	if a.signalling != nil {
+		a.signalling.close(errors.New("closed"))
+	}
+	if a.peerConnection != nil {
+		if err := a.peerConnection.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Watch the signalling and the peer connection
+func (a *audioConnection) watch() {
+	go func() {
+		select {
+		case <-a.signalling.done:
+			a.fail(a.signalling.err.Error())
+		case <-a.failed:
+		}
+	}()
+
+	a.peerConnection.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		if state == webrtc.PeerConnectionStateFailed || state == webrtc.PeerConnectionStateClosed {
+			a.fail("peer connection " + state.String())
+		}
+	})
+}
 
-	// Create a PeerConnection and set the remote description (sdpAnswer)
-	c.peerConnection, err = createPeerConnection(stunServers, turnServers, sdpOffer)
+// Get everything that is needed to connect to the audio. requireTURN fails if there is no TURN server.
+func (c *AudioClient) audioParameters(requireTURN bool) ([]webrtc.ICEServer, int, error) {
+	// Get the STUN and TURN servers (if they are not configured, see ICEConfig)
+	var stunServers []stunServers
+	var turnServers []turnServers
+	if c.ICE.ICEServers == nil {
+		var err error
+		stunServers, turnServers, err = c.GetStunTurnServers()
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	iceServers, err := c.ICE.servers(stunServers, turnServers, requireTURN)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 
-	// Generate SDP-Offer
-	sdpAnswer, err := generateSDPAnswer(c.peerConnection)
+	// Make api request to get all information of this meeting (VoiceBridge)
+	meetings, err := c.client.API.GetMeetings()
 	if err != nil {
-		return err
+		return nil, 0, err
+	}
+	meeting, found := meetings[c.client.ExternalMeetingID]
+	if !found {
+		return nil, 0, errMeetingNotRunning
 	}
 
+	return iceServers, meeting.VoiceBridge, nil
+}
+
+// Connect to the audio with the role
+func (c *AudioClient) connect(role AudioRole) (*audioConnection, error) {
+	if role == AudioRoleRecv {
+		return c.connectListenOnly()
+	}
+	return c.connectPublisher(role)
+}
 
+// Connect to the audio as listen only: the sfu makes the offer
+func (c *AudioClient) connectListenOnly() (*audioConnection, error) {
+	timeout := time.After(c.ConnectTimeout)
 
-	// Send SDP offer
-	err = sendSubscriberSDPAnswer(wscon, voiceBridge, sdpAnswer)
+	iceServers, voiceBridge, err := c.audioParameters(c.ICE.RequireTURN)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	caleeName := "GLOBAL_AUDIO_" + strconv.FormatInt(int64(voiceBridge), 10)
+
+	connection := newAudioConnection()
+
+	// Connect to the signalling server
+	connection.signalling, err = newSignalling(c.log(), c.client.WebRTCWSURL, c.client.SessionToken, c.client.SessionCookie, string(AudioRoleRecv), voiceBridge, c.ConnectTimeout, c.PingInterval+c.PingTimeout)
+	if err != nil {
+		return nil, err
 	}
+	c.log().Debug("connected to signalling server", "url", c.client.WebRTCWSURL)
 
+	// Send join message
+	err = sendJoinMessage(connection.signalling, AudioRoleRecv, c.client.InternalMeetingID, voiceBridge, caleeName, c.client.InternalUserID, c.client.UserName, "")
+	if err != nil {
+		connection.close()
+		return nil, err
+	}
 
-	// Start ping loop
-	c.pingStopChan = pingloop(wscon)
+	// Read join response (the offer of the sfu)
+	joinResponse, err := connection.signalling.waitForStartResponse(timeout)
+	if err != nil {
+		connection.close()
+		return nil, err
+	}
+	sdpOffer := joinResponse.SdpAnswer
 
+	// Create a PeerConnection and set the remote description (the offer)
+	connection.peerConnection, connection.stats, err = createPeerConnection(c.log(), c.ICE, iceServers, sdpOffer)
+	if err != nil {
+		connection.close()
+		return nil, err
+	}
+	connection.watch()
+	c.handleTracks(connection.peerConnection)
+	connection.signalling.addCandidatesTo(connection.peerConnection)
+	if c.TrickleICE {
+		c.sendCandidates(connection)
+	}
 
-	// Read SDP answer
-	status, err := readStatusResponse(wscon)
+	// Generate SDP-Answer
+	sdpAnswer, err := generateSDPAnswer(connection.peerConnection, c.TrickleICE, timeout)
 	if err != nil {
-		return err
+		connection.close()
+		return nil, err
 	}
-	if status.Success != "MEDIA_FLOWING" {
-		return errors.New("status response was not successful. Unable to establish webrtc audio connection. ID: " + status.ID + " ,Type: " + status.Type + " ,Success: " + status.Success)
+
+	// Send SDP answer
+	err = sendSubscriberSDPAnswer(connection.signalling, voiceBridge, sdpAnswer)
+	if err != nil {
+		connection.close()
+		return nil, err
+	}
+	if err := connection.signalling.startTrickle(); err != nil {
+		connection.close()
+		return nil, errors.New("failed to send ice candidates: " + err.Error())
 	}
 
-	c.status = CONNECTED
+	// Start ping loop
+	connection.signalling.pingLoop(c.PingInterval)
 
-	return nil
+	// Wait until the audio flows
+	if err := connection.signalling.waitForMediaFlowing(timeout); err != nil {
+		connection.close()
+		return nil, err
+	}
+
+	return connection, nil
+}
+
+// Send the local ICE candidates to the sfu (trickle ICE)
+func (c *AudioClient) sendCandidates(connection *audioConnection) {
+	connection.peerConnection.OnICECandidate(func(candidate *webrtc.ICECandidate) {
+		if candidate == nil {
+			return
+		}
+		if err := connection.signalling.sendCandidate(candidate.ToJSON()); err != nil {
+			c.log().Warn("could not send ice candidate", "error", err)
+		}
+	})
 }
 
+// Give the tracks of the peer connection to the track listeners
+func (c *AudioClient) handleTracks(peerConnection *webrtc.PeerConnection) {
+	peerConnection.OnTrack(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
+		c.mutex.Lock()
+		listeners := append([]func(*webrtc.TrackRemote, *webrtc.RTPReceiver){}, c.trackListeners...)
+		if len(listeners) == 0 {
+			c.pendingTracks = append(c.pendingTracks, remoteTrack{track: track, receiver: receiver})
+		}
+		c.mutex.Unlock()
 
-func (c* AudioClient) Close() error {
+		for _, listener := range listeners {
+			go listener(track, receiver)
+		}
+	})
+}
 
-	c.status = DISCONNECTING
+// Add a track listener. It gets the tracks that arrived before, too.
+func (c *AudioClient) addTrackListener(listener func(*webrtc.TrackRemote, *webrtc.RTPReceiver)) {
+	c.mutex.Lock()
+	c.trackListeners = append(c.trackListeners, listener)
+	pending := c.pendingTracks
+	c.pendingTracks = []remoteTrack{}
+	c.mutex.Unlock()
 
-	if err := c.peerConnection.Close(); err != nil {
-		return err
+	for _, t := range pending {
+		go listener(t.track, t.receiver)
 	}
+}
 
-	c.pingStopChan <- true
 
-	c.status = DISCONNECTED
+// Close leaves the audio. It can be called more than once. The listeners of OnTrack and ReceiveAudio
+// are removed, so they have to be added again before the next ListenToAudio or PublishAudio.
+func (c* AudioClient) Close() error {
+	c.mutex.Lock()
+	if c.closing {
+		c.mutex.Unlock()
+		return nil
+	}
+	active := c.status != DISCONNECTED
+	c.closing = true
+	connection := c.connection
+	c.connection = nil
+	receivers := c.receivers
+	c.receivers = []*audio.Receiver{}
+	c.mutex.Unlock()
+
+	if active {
+		c.setStatus(DISCONNECTING)
+	}
+	c.client.removeAudioClient(c)
+
+	var err error
+	if connection != nil {
+		err = connection.close()
+	}
+	for _, receiver := range receivers {
+		if closeErr := receiver.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
 
-	return nil
+	// the next session starts without the tracks and listeners of this one
+	c.mutex.Lock()
+	c.peerConnection = nil
+	c.trackListeners = []func(*webrtc.TrackRemote, *webrtc.RTPReceiver){}
+	c.pendingTracks = []remoteTrack{}
+	c.localTrack = nil
+	c.frameWriter = nil
+	c.mutex.Unlock()
+	c.setStatus(DISCONNECTED)
+
+	return err
 }
 
 
 
-func (c *AudioClient) OnTrack(onfunc func(*StatusType, *webrtc.TrackRemote, *webrtc.RTPReceiver)) error {
-	c.peerConnection.OnTrack(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
-		onfunc(&c.status, track, receiver)
+// OnTrack is called with every audio track. The track is the same until a rejoin, then there is a new track.
+// status returns the current status of the audio (GetStatus), e.g. to stop reading the track when it is DISCONNECTED.
+func (c *AudioClient) OnTrack(onfunc func(status func() StatusType, track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver)) error {
+	c.addTrackListener(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
+		onfunc(c.GetStatus, track, receiver)
 	})
 	return nil
 }
@@ -181,9 +624,9 @@ func (c *AudioClient) GetStunTurnServers() ([]stunServers, []turnServers, error)
 	// Make request to https://example.com/bigbluebutton/api/stuns?sessionToken=TOKEN
 	// to get the STUN server address
 	httpclient := new(http.Client)
-	req, _ := http.NewRequest("GET", c.API.Url + "stuns?sessionToken="+c.SessionToken, nil)
+	req, _ := http.NewRequest("GET", c.client.API.Url + "stuns?sessionToken="+c.client.SessionToken, nil)
 	// Add cookies
-	for _, cookie := range c.SessionCookie {
+	for _, cookie := range c.client.SessionCookie {
 		req.AddCookie(cookie)
 	}
 	
@@ -316,34 +759,31 @@ func ExtractRTCPFeedbackFromSDP(sdpStr string) ([]webrtc.RTCPFeedback, error) {
 }
 
 // Create a PeerConnection
-func createPeerConnection(stunServers []stunServers, turnServers []turnServers, sdpOffer string) (*webrtc.PeerConnection, error) {
+func createPeerConnection(log logger.Logger, ice ICEConfig, iceServers []webrtc.ICEServer, sdpOffer string) (*webrtc.PeerConnection, stats.Getter, error) {
 
 	// Extract the clock rate, channels, fmtp and rtcp feedback from the sdp offer
 	clockRate, err := ExtractClockRateFromSDP(sdpOffer)
 	if err != nil {
-		return nil, errors.New("failed to extract clock rate from sdp offer: " + err.Error())
+		return nil, nil, errors.New("failed to extract clock rate from sdp offer: " + err.Error())
 	}
 
 	channels, err := ExtractChannelsFromSDP(sdpOffer)
 	if err != nil {
-		return nil, errors.New("failed to extract channels from sdp offer: " + err.Error())
+		return nil, nil, errors.New("failed to extract channels from sdp offer: " + err.Error())
 	}
 
 	fmtpValue, err := ExtractFmtpFromSDP(sdpOffer)
 	if err != nil {
-		return nil, errors.New("failed to extract fmtp from sdp offer: " + err.Error())
+		return nil, nil, errors.New("failed to extract fmtp from sdp offer: " + err.Error())
 	}
 
 	rtcpFeedback, err := ExtractRTCPFeedbackFromSDP(sdpOffer)
 	if err != nil {
-		return nil, errors.New("failed to extract rtcp feedback from sdp offer: " + err.Error())
+		return nil, nil, errors.New("failed to extract rtcp feedback from sdp offer: " + err.Error())
 	}
 
 
-	fmt.Println("clockRate:", clockRate)
-	fmt.Println("channels:", channels)
-	fmt.Println("fmtpValue:", fmtpValue)
-	fmt.Println("rtcpFeedback:", rtcpFeedback)
+	log.Debug("opus codec of the sdp offer", "clockRate", clockRate, "channels", channels, "fmtp", fmtpValue, "rtcpFeedback", rtcpFeedback)
 
 	// Setup the codecs
 	m := &webrtc.MediaEngine{}
@@ -356,7 +796,7 @@ func createPeerConnection(stunServers []stunServers, turnServers []turnServers,
 			RTCPFeedback: rtcpFeedback,
 		},
 	}, webrtc.RTPCodecTypeAudio); err != nil {
-		panic(err)
+		return nil, nil, errors.New("failed to register opus codec: " + err.Error())
 	}
 
 	// Create a InterceptorRegistry. This is the user configurable RTP/RTCP Pipeline.
@@ -367,48 +807,35 @@ func createPeerConnection(stunServers []stunServers, turnServers []turnServers,
 
 	// Use the default set of Interceptors
 	if err := webrtc.RegisterDefaultInterceptors(m, i); err != nil {
-		panic(err)
+		return nil, nil, errors.New("failed to register interceptors: " + err.Error())
 	}
 
-	// Create the API object with the MediaEngine
-	webrtcapi := webrtc.NewAPI(webrtc.WithMediaEngine(m), webrtc.WithInterceptorRegistry(i))
-
-	// Create ice servers
-    iceServers := []webrtc.ICEServer{}
+	// Collect the stats of the RTP streams (see AudioClient.OnStats)
+	statsGetter, err := registerStatsInterceptor(i)
+	if err != nil {
+		return nil, nil, err
+	}
 
-    for _, stun := range stunServers {
-        iceServers = append(iceServers, webrtc.ICEServer{
-            URLs: []string{stun.URL},
-        })
-    }
+	// The network settings (see AudioClient.ICE)
+	settings, err := ice.settingEngine()
+	if err != nil {
+		return nil, nil, err
+	}
 
-    for _, turn := range turnServers {
-        iceServers = append(iceServers, webrtc.ICEServer{
-            URLs:     []string{turn.URL},
-            Username: turn.Username,
-            Credential: turn.Password,
-        })
-    }
+	// Create the API object with the MediaEngine
+	webrtcapi := webrtc.NewAPI(webrtc.WithMediaEngine(m), webrtc.WithInterceptorRegistry(i), webrtc.WithSettingEngine(settings))
 
 	// Create a new RTCPeerConnection
-	peerConnection, err := webrtcapi.NewPeerConnection(webrtc.Configuration{
-		ICEServers: iceServers,
-	})
+	peerConnection, err := webrtcapi.NewPeerConnection(ice.configuration(iceServers))
 	if err != nil {
-		return nil, errors.New("failed to create new peer connection: " + err.Error())
+		return nil, nil, errors.New("failed to create new peer connection: " + err.Error())
 	}
 
 
 	// Set the handler for ICE connection state
-	// This will notify you when the peer has connected/disconnected
+	// This will notify you when the peer has connected/disconnected (a failed connection is rejoined, see AudioClient.AutoRejoin)
 	peerConnection.OnICEConnectionStateChange(func(connectionState webrtc.ICEConnectionState) {
-		fmt.Printf("Connection State has changed %s \n", connectionState.String())
-
-		if connectionState == webrtc.ICEConnectionStateFailed {
-			if closeErr := peerConnection.Close(); closeErr != nil {
-				panic(closeErr)
-			}
-		}
+		log.Info("ice connection state has changed", "state", connectionState.String())
 	})
 
 
@@ -418,17 +845,19 @@ func createPeerConnection(stunServers []stunServers, turnServers []turnServers,
 		SDP:  sdpOffer, // This is the SDP offer from the join response
 	})
 	if err != nil {
-		return nil, errors.New("failed to set remote description: " + err.Error())
+		peerConnection.Close()
+		return nil, nil, errors.New("failed to set remote description: " + err.Error())
 	}
 
 
 	// Create an audio transmition
 	_, err = peerConnection.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio)
 	if err != nil {
-		return nil, errors.New("failed to add audio transceiver: " + err.Error())
+		peerConnection.Close()
+		return nil, nil, errors.New("failed to add audio transceiver: " + err.Error())
 	}
 
-	return peerConnection, nil
+	return peerConnection, statsGetter(), nil
 }
 
 
@@ -436,8 +865,8 @@ func createPeerConnection(stunServers []stunServers, turnServers []turnServers,
 
 
 
-// Generate a SDP offer and
-func generateSDPAnswer(peerConnection *webrtc.PeerConnection) (string, error) {
+// Generate the SDP answer. Without trickle ICE it waits until all ICE candidates are gathered.
+func generateSDPAnswer(peerConnection *webrtc.PeerConnection, trickle bool, timeout <-chan time.Time) (string, error) {
 	// Create channel that is blocked until ICE Gathering is complete
 	gatherComplete := webrtc.GatheringCompletePromise(peerConnection)
 
@@ -445,7 +874,7 @@ func generateSDPAnswer(peerConnection *webrtc.PeerConnection) (string, error) {
 	// Create an answer
 	answer, err := peerConnection.CreateAnswer(nil)
 	if err != nil {
-		return "", errors.New("failed to create SDP offer: " + err.Error())
+		return "", errors.New("failed to create SDP answer: " + err.Error())
 	}
 
 
@@ -456,25 +885,31 @@ func generateSDPAnswer(peerConnection *webrtc.PeerConnection) (string, error) {
 	}
 
 
-	// Block until ICE Gathering is complete, disabling trickle ICE
-	// we do this because we only can exchange one signaling message
-	// in a production application you should exchange ICE Candidates via OnICECandidate
-	<-gatherComplete
-
-	// modify the SDP offer for freeswitch
-	answer.SDP = rewriteSDP(answer.SDP)
+	// With trickle ICE the candidates are sent with iceCandidate messages (see AudioClient.TrickleICE).
+	// Otherwise block until ICE Gathering is complete, so all candidates are in the answer.
+	if !trickle {
+		select {
+		case <-gatherComplete:
+		case <-timeout:
+			return "", errors.New("timeout while gathering ice candidates")
+		}
+	}
 
-	return answer.SDP, nil
+	// modify the SDP answer for freeswitch
+	return rewriteSDP(peerConnection.LocalDescription().SDP)
 }
 
 // Apply the following transformations for FreeSWITCH
 // * Add fake srflx candidate to each media section
 // * Add msid to each media section
 // * Make bundle first attribute at session level.
-func rewriteSDP(in string) string {
+func rewriteSDP(in string) (string, error) {
 	parsed := &sdp.SessionDescription{}
 	if err := parsed.Unmarshal([]byte(in)); err != nil {
-		panic(err)
+		return "", errors.New("failed to parse SDP: " + err.Error())
+	}
+	if len(parsed.MediaDescriptions) == 0 {
+		return "", errors.New("failed to rewrite SDP: no media description")
 	}
 
 	// Reverse global attributes
@@ -489,10 +924,10 @@ func rewriteSDP(in string) string {
 
 	out, err := parsed.Marshal()
 	if err != nil {
-		panic(err)
+		return "", errors.New("failed to write SDP: " + err.Error())
 	}
 
-	return string(out)
+	return string(out), nil
 }
 
 
@@ -500,45 +935,6 @@ func rewriteSDP(in string) string {
 
 
 
-// 
-func connectToWebSocketSignallingServer(webrtcwsurl, token string, cookies []*http.Cookie) (*websocket.Conn, error) {
-	// Parse url
-	u, err := url.Parse(webrtcwsurl + "?sessionToken=" + token)
-	if err != nil {
-		return nil, errors.New("failed to parse WebRTC WebSocket URL: " + err.Error())
-	}
-	wsurl := u.String()
-
-
-	// Create header
-	header := http.Header{}
-
-	// Create CookieJar
-	coockieJar, err := cookiejar.New(nil)
-	if err != nil {
-		return nil, errors.New("failed to create cookie jar: " + err.Error())
-	}
-	tempurl := url.URL(*u)
-	tempurl.Scheme = "https"
-	coockieJar.SetCookies(&tempurl, cookies)
-
-
-	// Create dialer
-	wsdialer := websocket.DefaultDialer
-	wsdialer.Jar = coockieJar
-
-
-	// Connect to the WebSocket signalling server
-	conn, _, err := wsdialer.Dial(wsurl, header)
-	if err != nil {
-		return nil, errors.New("failed to connect to WebSocket: " + err.Error())
-	}
-	fmt.Println("Connected to WebSocket server!")
-	
-	return conn, nil
-}
-
-
 type joinMessage struct {
 	ID                string `json:"id"`
 	Type              string `json:"type"`
@@ -548,23 +944,25 @@ type joinMessage struct {
 	CaleeName         string `json:"caleeName"`
 	UserID            string `json:"userId"`
 	UserName          string `json:"userName"`
+	SdpOffer          string `json:"sdpOffer,omitempty"` // only if the bot sends audio. Then the offer is made by the bot.
 }
 // Send join message
-func sendJoinMessage(wscon *websocket.Conn, internalMeetingID string, voiceBridge int, caleeName, userID, userName string) error {
+func sendJoinMessage(s *signalling, role AudioRole, internalMeetingID string, voiceBridge int, caleeName, userID, userName string, sdpOffer string) error {
 	// Create join message
 	joinMsg := joinMessage{
 		ID:                "start",
 		Type:              "audio",
-		Role:              "recv",
+		Role:              string(role),
 		InternalMeetingID: internalMeetingID,
 		VoiceBridge:       voiceBridge,
 		CaleeName:         caleeName,
 		UserID:            userID,
 		UserName:          userName,
+		SdpOffer:          sdpOffer,
 	}
 
 	// Marshal join message and send it
-	err := wscon.WriteJSON(joinMsg)
+	err := s.send(joinMsg)
 	if err != nil {
 		return errors.New("failed to send joinMessage: " + err.Error())
 	}
@@ -572,27 +970,6 @@ func sendJoinMessage(wscon *websocket.Conn, internalMeetingID string, voiceBridg
 	return nil
 }
 
-type joinSDPResponse struct {
-	Type      string `json:"type"`
-	ID        string `json:"id"`
-	Response  string `json:"response"`
-	SdpAnswer string `json:"sdpAnswer"`
-}
-// Read join response
-func readJoinSDPResponse(wscon *websocket.Conn) (joinSDPResponse, error) {
-	// Read join response
-	var joinResp joinSDPResponse
-	err := wscon.ReadJSON(&joinResp)
-	if err != nil {
-		return joinResp, errors.New("failed to read join sdp response: " + err.Error())
-	}
-
-	return joinResp, nil
-}
-
-
-
-
 type subscriberSDPAnswer struct {
 	ID          string `json:"id"`
 	Type        string `json:"type"`
@@ -601,7 +978,7 @@ type subscriberSDPAnswer struct {
 	SdpOffer    string `json:"sdpOffer"`
 }
 // Send SDP offer
-func sendSubscriberSDPAnswer(wscon *websocket.Conn, voiceBridge int, answer string) error {
+func sendSubscriberSDPAnswer(s *signalling, voiceBridge int, answer string) error {
 	// Create SDP offer message
 	sdpOfferMsg := subscriberSDPAnswer{
 		ID:          "subscriberAnswer",
@@ -612,81 +989,10 @@ func sendSubscriberSDPAnswer(wscon *websocket.Conn, voiceBridge int, answer stri
 	}
 
 	// Marshal SDP offer message and send it
-	err := wscon.WriteJSON(sdpOfferMsg)
+	err := s.send(sdpOfferMsg)
 	if err != nil {
 		return errors.New("failed to send SDP offer: " + err.Error())
 	}
 
 	return nil
 }
-
-
-type statusResponse struct {
-	Type    string `json:"type"`
-	ID      string `json:"id"`
-	Success string `json:"success"`
-}
-// Read status answer
-func readStatusResponse(wscon *websocket.Conn) (statusResponse, error) {
-	// Read SDP answer
-	var sdpRespMsg statusResponse
-	err := wscon.ReadJSON(&sdpRespMsg)
-	if err != nil {
-		return sdpRespMsg, errors.New("failed to read status response: " + err.Error())
-	}
-
-	return sdpRespMsg, nil
-}
-
-
-
-
-
-type pingpong struct {
-	ID string `json:"id"`
-}
-
-// Ping loop
-func pingloop(wscon *websocket.Conn) chan bool {
-	// Create ping message
-	pingMsg := pingpong{
-		ID: "ping",
-	}
-
-	stopChan := make(chan bool)
-
-	// Start ping loop
-	go func() {
-		for {
-			select {
-			case <-stopChan:
-				fmt.Println("Stopping ping loop")
-				return
-			case <-time.After(15 * time.Second):
-				err := wscon.WriteJSON(pingMsg)
-				if err != nil {
-					fmt.Errorf("failed to send ping message: %v", err)
-					return  // Exit the goroutine if there's an error
-				}
-				fmt.Println("sent ping message!")
-
-				// Set a deadline for reading the next pong message
-				wscon.SetReadDeadline(time.Now().Add(5 * time.Second))
-				var pongResp pingpong
-				err = wscon.ReadJSON(&pongResp)
-				if err != nil {
-					fmt.Errorf("failed to read pong response: %v", err)
-					return  // Exit the goroutine if there's an error
-				}
-
-				if pongResp.ID == "pong" {
-					fmt.Println("received pong response!")
-				} else {
-					fmt.Println("received msg, but not pong response!")
-				}
-			}
-		}
-	}()
-
-	return stopChan
-}
\ No newline at end of file
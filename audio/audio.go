@@ -0,0 +1,52 @@
+// Package audio turns the RTP packets of the meeting audio into PCM and hands it to sinks
+// (WAV files, Ogg/Opus files, channels, resamplers for speech recognition, ...).
+package audio
+
+import (
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+// PCMFrame is a block of decoded audio. The samples are 16 bit signed and interleaved by channel.
+type PCMFrame struct {
+	Samples    []int16
+	SampleRate int
+	Channels   int
+	// Lost is true if the packet of this frame did not arrive. Then the samples are concealed or silence.
+	Lost bool
+}
+
+// Duration returns how long the frame plays
+func (f PCMFrame) Duration() time.Duration {
+	if f.SampleRate == 0 || f.Channels == 0 {
+		return 0
+	}
+	return time.Duration(len(f.Samples)/f.Channels) * time.Second / time.Duration(f.SampleRate)
+}
+
+// Sink receives the decoded audio
+type Sink interface {
+	WritePCM(frame PCMFrame) error
+	Close() error
+}
+
+// OpusSink receives the opus packets in the right order (e.g. *oggwriter.OggWriter of pion)
+type OpusSink interface {
+	WriteRTP(packet *rtp.Packet) error
+	Close() error
+}
+
+// PacketSink receives every opus packet together with its decoded frame (e.g. a Recorder).
+// A lost packet is nil. Without a Decoder the frame has no samples.
+type PacketSink interface {
+	WritePacket(packet *rtp.Packet, frame PCMFrame) error
+	Close() error
+}
+
+// Decoder decodes one opus packet into pcm and returns the number of samples per channel.
+// If data is nil, the decoder should conceal the lost packet.
+// *opus.Decoder of gopkg.in/hraban/opus.v2 implements this interface (see NewOpusDecoder).
+type Decoder interface {
+	Decode(data []byte, pcm []int16) (int, error)
+}
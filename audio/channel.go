@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"sync"
+)
+
+// ChannelSink is a Sink that sends the frames to a channel.
+// If the reader is too slow, frames are dropped instead of blocking the audio.
+type ChannelSink struct {
+	C chan PCMFrame
+
+	mutex   *sync.Mutex
+	closed  bool
+	dropped int
+}
+
+// NewChannelSink creates a ChannelSink with a channel that buffers up to size frames
+func NewChannelSink(size int) *ChannelSink {
+	return &ChannelSink{
+		C:     make(chan PCMFrame, size),
+		mutex: new(sync.Mutex),
+	}
+}
+
+// WritePCM sends the frame to the channel
+func (s *ChannelSink) WritePCM(frame PCMFrame) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	select {
+	case s.C <- frame:
+	default:
+		s.dropped++
+	}
+	return nil
+}
+
+// Dropped returns how many frames were dropped because the channel was full
+func (s *ChannelSink) Dropped() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.dropped
+}
+
+// Close closes the channel
+func (s *ChannelSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.C)
+	}
+	return nil
+}
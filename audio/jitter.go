@@ -0,0 +1,91 @@
+package audio
+
+import (
+	"github.com/pion/rtp"
+)
+
+// JitterBuffer puts RTP packets back into the order of their sequence numbers.
+// Up to depth packets are held back to wait for late packets. A missing packet is given up
+// when the buffer is full and is returned as nil, so the decoder can conceal it.
+type JitterBuffer struct {
+	depth   int
+	packets map[uint16]*rtp.Packet
+	next    uint16 // sequence number of the next packet to return
+	ssrc    uint32 // the stream of the packets
+	started bool
+}
+
+// NewJitterBuffer creates a JitterBuffer that holds back up to depth packets (e.g. 5 packets are 100ms of opus)
+func NewJitterBuffer(depth int) *JitterBuffer {
+	if depth < 1 {
+		depth = 1
+	}
+	return &JitterBuffer{
+		depth:   depth,
+		packets: make(map[uint16]*rtp.Packet),
+	}
+}
+
+// Push adds the packet and returns all packets that are ready, in order. Lost packets are nil.
+// Packets that arrive after they were given up are dropped. A packet of a new stream (SSRC) starts again.
+func (j *JitterBuffer) Push(packet *rtp.Packet) []*rtp.Packet {
+	seq := packet.SequenceNumber
+	if !j.started || packet.SSRC != j.ssrc {
+		// a new stream (e.g. after a rejoin) has its own sequence numbers
+		ready := j.Flush()
+		j.next = seq
+		j.ssrc = packet.SSRC
+		j.started = true
+		j.packets[seq] = packet
+		return append(ready, j.pop()...)
+	}
+
+	// older than the next packet (with wrap around of the sequence number)
+	if int16(seq-j.next) < 0 {
+		return nil
+	}
+	// a jump too big to be reordering (e.g. the sender restarted): start again
+	if int(seq-j.next) > 10*j.depth+len(j.packets) {
+		ready := j.Flush()
+		j.next = seq
+		j.packets[seq] = packet
+		return append(ready, j.pop()...)
+	}
+
+	j.packets[seq] = packet
+	return j.pop()
+}
+
+// Returns the packets that are ready
+func (j *JitterBuffer) pop() []*rtp.Packet {
+	ready := []*rtp.Packet{}
+	for len(j.packets) > 0 {
+		if packet, found := j.packets[j.next]; found {
+			delete(j.packets, j.next)
+			ready = append(ready, packet)
+			j.next++
+			continue
+		}
+		if len(j.packets) <= j.depth {
+			break
+		}
+		// the buffer is full: the next packet is lost
+		ready = append(ready, nil)
+		j.next++
+	}
+	return ready
+}
+
+// Flush returns all held back packets in order (lost packets in between are nil)
+func (j *JitterBuffer) Flush() []*rtp.Packet {
+	ready := []*rtp.Packet{}
+	for len(j.packets) > 0 {
+		packet, found := j.packets[j.next]
+		if found {
+			delete(j.packets, j.next)
+		}
+		ready = append(ready, packet)
+		j.next++
+	}
+	return ready
+}
@@ -0,0 +1,78 @@
+package audio
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pion/rtp"
+)
+
+// Test for JitterBuffer (reordering, loss and late packets)
+func TestJitterBuffer(t *testing.T) {
+	tests := []struct {
+		depth    int
+		input    []uint16
+		ssrcs    []uint32 // SSRC of every packet (nil is 0)
+		expected []int    // sequence numbers returned by Push and Flush, -1 for a lost packet
+	}{
+		{
+			depth:    2,
+			input:    []uint16{1, 2, 3},
+			expected: []int{1, 2, 3},
+		},
+		{
+			depth:    2,
+			input:    []uint16{1, 3, 2, 4},
+			expected: []int{1, 2, 3, 4},
+		},
+		{
+			depth:    2,
+			input:    []uint16{1, 3, 4, 5, 2, 6},
+			expected: []int{1, -1, 3, 4, 5, 6},
+		},
+		{
+			depth:    2,
+			input:    []uint16{65534, 65535, 0, 1},
+			expected: []int{65534, 65535, 0, 1},
+		},
+		{
+			depth:    3,
+			input:    []uint16{1, 3},
+			expected: []int{1, -1, 3},
+		},
+		{
+			depth:    2,
+			input:    []uint16{100, 101, 102, 7, 8, 9},
+			ssrcs:    []uint32{1, 1, 1, 2, 2, 2},
+			expected: []int{100, 101, 102, 7, 8, 9},
+		},
+	}
+
+	for num, test := range tests {
+		jitter := NewJitterBuffer(test.depth)
+		result := []int{}
+		add := func(packets []*rtp.Packet) {
+			for _, packet := range packets {
+				if packet == nil {
+					result = append(result, -1)
+				} else {
+					result = append(result, int(packet.SequenceNumber))
+				}
+			}
+		}
+		for i, seq := range test.input {
+			var ssrc uint32
+			if test.ssrcs != nil {
+				ssrc = test.ssrcs[i]
+			}
+			add(jitter.Push(&rtp.Packet{Header: rtp.Header{SequenceNumber: seq, SSRC: ssrc}}))
+		}
+		add(jitter.Flush())
+
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("JitterBuffer() %d FAILED: got %v, expected %v", num, result, test.expected)
+		} else {
+			t.Logf("JitterBuffer() %d PASSED", num)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package audio
+
+import (
+	"errors"
+	"io"
+
+	"github.com/pion/webrtc/v3/pkg/media/oggwriter"
+)
+
+// NewOggFile creates an Ogg/Opus file for the opus packets (see Receiver.AddOpusSink).
+// The opus packets are not decoded, so no Decoder is needed.
+func NewOggFile(path string, sampleRate uint32, channels uint16) (OpusSink, error) {
+	writer, err := oggwriter.New(path, sampleRate, channels)
+	if err != nil {
+		return nil, errors.New("could not create ogg file: " + err.Error())
+	}
+	return writer, nil
+}
+
+// NewOggWriter creates an Ogg/Opus writer into w for the opus packets
+func NewOggWriter(w io.Writer, sampleRate uint32, channels uint16) (OpusSink, error) {
+	writer, err := oggwriter.NewWith(w, sampleRate, channels)
+	if err != nil {
+		return nil, errors.New("could not create ogg writer: " + err.Error())
+	}
+	return writer, nil
+}
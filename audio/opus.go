@@ -0,0 +1,13 @@
+//go:build !opus
+
+package audio
+
+import (
+	"errors"
+)
+
+// NewOpusDecoder creates an opus decoder. Decoding needs libopus (found with pkg-config): build with the
+// tags opus and nolibopusfile (go build -tags opus,nolibopusfile), or use a Decoder of your own.
+func NewOpusDecoder(sampleRate int, channels int) (Decoder, error) {
+	return nil, errors.New("opus decoder is not available: build with -tags opus or use a Decoder of your own")
+}
@@ -0,0 +1,34 @@
+//go:build opus
+
+package audio
+
+import (
+	"errors"
+
+	"gopkg.in/hraban/opus.v2"
+)
+
+// NewOpusDecoder creates an opus decoder (libopus)
+func NewOpusDecoder(sampleRate int, channels int) (Decoder, error) {
+	decoder, err := opus.NewDecoder(sampleRate, channels)
+	if err != nil {
+		return nil, errors.New("could not create opus decoder: " + err.Error())
+	}
+	return &libopusDecoder{decoder: decoder, channels: channels}, nil
+}
+
+type libopusDecoder struct {
+	decoder  *opus.Decoder
+	channels int
+}
+
+// Decode decodes the packet. A lost packet (nil) is concealed for the length of pcm.
+func (d *libopusDecoder) Decode(data []byte, pcm []int16) (int, error) {
+	if data == nil {
+		if err := d.decoder.DecodePLC(pcm); err != nil {
+			return 0, err
+		}
+		return len(pcm) / d.channels, nil
+	}
+	return d.decoder.Decode(data, pcm)
+}
@@ -0,0 +1,212 @@
+package audio
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/pion/rtp"
+)
+
+// The size of the jitter buffer of a Receiver (100ms of opus with 20ms packets)
+const DefaultJitterDepth = 5
+
+// Receiver puts the RTP packets in order, decodes them and writes them to the sinks.
+type Receiver struct {
+	mutex *sync.Mutex
+
+	jitter     *JitterBuffer
+	decoder    Decoder
+	sampleRate int
+	channels   int
+
+	pcm         []int16 // decode buffer (120ms, the longest opus packet)
+	lastSize    int     // samples per channel of the last packet (used for lost packets)
+	sinks       []Sink
+	opusSinks   []OpusSink
+	packetSinks []PacketSink
+	stats       ReceiverStats
+	closed      bool
+}
+
+// ReceiverStats counts the packets of a Receiver
+type ReceiverStats struct {
+	Frames    uint64 // all frames (received and concealed)
+	Concealed uint64 // frames of lost packets (concealed by the decoder or silence)
+}
+
+// ErrReceiverClosed is returned by WriteRTP after Close
+var ErrReceiverClosed = errors.New("receiver is closed")
+
+// NewReceiver creates a Receiver. The decoder has to decode to sampleRate and channels.
+// The decoder can be nil if there are only OpusSinks.
+func NewReceiver(decoder Decoder, sampleRate int, channels int) *Receiver {
+	return &Receiver{
+		mutex: new(sync.Mutex),
+
+		jitter:     NewJitterBuffer(DefaultJitterDepth),
+		decoder:    decoder,
+		sampleRate: sampleRate,
+		channels:   channels,
+
+		pcm:         make([]int16, sampleRate*120/1000*channels),
+		lastSize:    sampleRate * 20 / 1000,
+		sinks:       []Sink{},
+		opusSinks:   []OpusSink{},
+		packetSinks: []PacketSink{},
+	}
+}
+
+// AddSink adds a sink for the decoded audio
+func (r *Receiver) AddSink(sink Sink) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.sinks = append(r.sinks, sink)
+}
+
+// AddOpusSink adds a sink for the opus packets (e.g. an ogg file)
+func (r *Receiver) AddOpusSink(sink OpusSink) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.opusSinks = append(r.opusSinks, sink)
+}
+
+// AddPacketSink adds a sink for the opus packets with their decoded audio (e.g. a Recorder)
+func (r *Receiver) AddPacketSink(sink PacketSink) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.packetSinks = append(r.packetSinks, sink)
+}
+
+// WriteRTP adds a packet of the audio track. The sinks are written once the packet is in order.
+func (r *Receiver) WriteRTP(packet *rtp.Packet) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return ErrReceiverClosed
+	}
+
+	var firstErr error
+	for _, ready := range r.jitter.Push(packet) {
+		if err := r.handle(ready); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close writes the held back packets and closes all sinks
+func (r *Receiver) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	var firstErr error
+	for _, ready := range r.jitter.Flush() {
+		if err := r.handle(ready); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	r.closed = true
+	for _, sink := range r.opusSinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, sink := range r.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, sink := range r.packetSinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Stats returns the counters since the Receiver was created
+func (r *Receiver) Stats() ReceiverStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.stats
+}
+
+// Write one packet (nil if lost) to the sinks
+func (r *Receiver) handle(packet *rtp.Packet) error {
+	var firstErr error
+
+	r.stats.Frames++
+	if packet == nil {
+		r.stats.Concealed++
+	}
+
+	if packet != nil {
+		for _, sink := range r.opusSinks {
+			if err := sink.WriteRTP(packet); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if len(r.sinks) == 0 && len(r.packetSinks) == 0 {
+		return firstErr
+	}
+
+	frame := PCMFrame{SampleRate: r.sampleRate, Channels: r.channels, Lost: packet == nil}
+	if r.decoder != nil {
+		var err error
+		frame, err = r.decode(packet)
+		if err != nil {
+			return err
+		}
+		for _, sink := range r.sinks {
+			if err := sink.WritePCM(frame); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	for _, sink := range r.packetSinks {
+		if err := sink.WritePacket(packet, frame); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Decode the packet. A lost packet has the length of the last packet.
+func (r *Receiver) decode(packet *rtp.Packet) (PCMFrame, error) {
+	frame := PCMFrame{
+		SampleRate: r.sampleRate,
+		Channels:   r.channels,
+		Lost:       packet == nil,
+	}
+
+	var n int
+	var err error
+	if packet == nil {
+		n, err = r.decoder.Decode(nil, r.pcm[:r.lastSize*r.channels])
+		if err != nil {
+			// no concealment: silence
+			for i := range r.pcm[:r.lastSize*r.channels] {
+				r.pcm[i] = 0
+			}
+			n, err = r.lastSize, nil
+		}
+	} else {
+		n, err = r.decoder.Decode(packet.Payload, r.pcm)
+		if err != nil {
+			return frame, errors.New("could not decode opus packet: " + err.Error())
+		}
+		r.lastSize = n
+	}
+
+	// the sinks get their own copy
+	frame.Samples = append([]int16{}, r.pcm[:n*r.channels]...)
+	return frame, nil
+}
@@ -0,0 +1,380 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/media/oggwriter"
+)
+
+// RecordFormat is the file format of a Recorder
+type RecordFormat string
+
+const (
+	RecordOgg RecordFormat = "ogg" // the opus packets as they are received (no Decoder needed, except for TrimSilence)
+	RecordWAV RecordFormat = "wav" // the decoded audio (needs a Decoder)
+)
+
+// The clock rate of opus in RTP (always 48 kHz)
+const opusRTPClockRate = 48000
+
+// RecorderConfig configures a Recorder
+type RecorderConfig struct {
+	// The files are written to Directory and named Name-0001.ogg, Name-0002.ogg, ...
+	Directory string
+	Name      string
+	Format    RecordFormat
+	// Format of the audio of the Receiver
+	SampleRate int
+	Channels   int
+	// A new file (segment) is started after MaxDuration of audio or MaxSize bytes. 0 does not rotate.
+	MaxDuration time.Duration
+	MaxSize     int64
+	// Do not record the silence. The Threshold, the EndDuration (silence that is still recorded after
+	// the speech) and the PreRoll (silence before the speech) of Silence are used.
+	TrimSilence bool
+	Silence     VADConfig
+}
+
+// DefaultRecorderConfig returns a config for Ogg/Opus files of one hour each
+func DefaultRecorderConfig(directory string, name string) RecorderConfig {
+	return RecorderConfig{
+		Directory:   directory,
+		Name:        name,
+		Format:      RecordOgg,
+		SampleRate:  48000,
+		Channels:    2,
+		MaxDuration: time.Hour,
+		MaxSize:     0,
+		TrimSilence: false,
+		Silence:     DefaultVADConfig(),
+	}
+}
+
+// Span is a part of a segment without a gap (e.g. between trimmed silence)
+type Span struct {
+	Time     time.Time     // wall clock time of the start
+	Offset   time.Duration // position in the file
+	Duration time.Duration
+}
+
+// Segment is one file of a Recorder
+type Segment struct {
+	Index    int
+	Path     string
+	Start    time.Time // wall clock time of the first audio
+	End      time.Time // wall clock time of the end of the last audio
+	Duration time.Duration
+	Size     int64
+	Spans    []Span
+}
+
+// Offset returns the position of the wall clock time t in the file. It is false if t was not recorded.
+func (s Segment) Offset(t time.Time) (time.Duration, bool) {
+	for _, span := range s.Spans {
+		if !t.Before(span.Time) && !t.After(span.Time.Add(span.Duration)) {
+			return span.Offset + t.Sub(span.Time), true
+		}
+	}
+	return 0, false
+}
+
+// Recorder is a PacketSink that writes the audio into files (segments).
+// The last segment is finished by Close (e.g. when the AudioClient is closed or the meeting ends).
+type Recorder struct {
+	mutex  *sync.Mutex
+	config RecorderConfig
+
+	segment   *segmentWriter // nil until the first audio of a segment
+	index     int
+	segments  []Segment
+	onSegment []func(Segment)
+
+	lastTimestamp uint32
+	hasTimestamp  bool
+
+	// silence trimming
+	inSpeech bool
+	silence  time.Duration
+	held     []recordedPacket // the silence before the speech (PreRoll)
+	gap      bool             // audio was left out since the last write
+
+	closed bool
+}
+
+type recordedPacket struct {
+	packet   *rtp.Packet
+	frame    PCMFrame
+	duration time.Duration
+	time     time.Time
+}
+
+// NewRecorder creates a Recorder. The directory is created if it does not exist.
+func NewRecorder(config RecorderConfig) (*Recorder, error) {
+	if config.Format != RecordOgg && config.Format != RecordWAV {
+		return nil, errors.New("could not create recorder: unknown format " + string(config.Format))
+	}
+	if config.SampleRate <= 0 || config.Channels <= 0 {
+		return nil, errors.New("could not create recorder: invalid sample rate or channels")
+	}
+	if config.Name == "" {
+		config.Name = "audio"
+	}
+	if err := os.MkdirAll(config.Directory, 0o755); err != nil {
+		return nil, errors.New("could not create recorder directory: " + err.Error())
+	}
+
+	return &Recorder{
+		mutex:  new(sync.Mutex),
+		config: config,
+
+		segments:  []Segment{},
+		onSegment: []func(Segment){},
+
+		held: []recordedPacket{},
+	}, nil
+}
+
+// OnSegment is called when a segment is finished (after a rotation and on Close).
+// It is called before Close returns, so it can write files that belong to the segment.
+func (r *Recorder) OnSegment(fun func(Segment)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.onSegment = append(r.onSegment, fun)
+}
+
+// Segments returns the finished segments
+func (r *Recorder) Segments() []Segment {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]Segment{}, r.segments...)
+}
+
+// WritePacket records the packet (Ogg) or the frame (WAV)
+func (r *Recorder) WritePacket(packet *rtp.Packet, frame PCMFrame) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return errors.New("recorder is closed")
+	}
+
+	item := recordedPacket{
+		packet:   packet,
+		frame:    frame,
+		duration: r.packetDuration(packet, frame),
+		time:     time.Now(),
+	}
+
+	if !r.config.TrimSilence {
+		return r.write(item)
+	}
+
+	// Without samples the level is unknown, so everything is recorded
+	voiced := len(frame.Samples) == 0 || Level(frame.Samples) >= r.config.Silence.Threshold
+	switch {
+	case voiced:
+		if !r.inSpeech {
+			r.inSpeech = true
+			for _, h := range r.held {
+				if err := r.write(h); err != nil {
+					return err
+				}
+			}
+			r.held = []recordedPacket{}
+		}
+		r.silence = 0
+		return r.write(item)
+	case r.inSpeech:
+		r.silence += item.duration
+		if r.silence >= r.config.Silence.EndDuration {
+			r.inSpeech = false
+		}
+		return r.write(item)
+	default:
+		r.held = append(r.held, item)
+		total := time.Duration(0)
+		for _, h := range r.held {
+			total += h.duration
+		}
+		for len(r.held) > 0 && total > r.config.Silence.PreRoll {
+			total -= r.held[0].duration
+			r.held = r.held[1:]
+			r.gap = true
+		}
+		return nil
+	}
+}
+
+// Close finishes the last segment
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.finishSegment()
+}
+
+// The duration of the packet: by the frame or by the timestamps
+func (r *Recorder) packetDuration(packet *rtp.Packet, frame PCMFrame) time.Duration {
+	if duration := frame.Duration(); duration > 0 {
+		return duration
+	}
+
+	duration := 20 * time.Millisecond
+	if packet == nil {
+		return duration
+	}
+	if r.hasTimestamp {
+		delta := packet.Timestamp - r.lastTimestamp
+		// only a plausible delta (a lost packet or a new stream is not)
+		if delta > 0 && delta <= opusRTPClockRate*120/1000 {
+			duration = time.Duration(delta) * time.Second / opusRTPClockRate
+		}
+	}
+	r.lastTimestamp = packet.Timestamp
+	r.hasTimestamp = true
+	return duration
+}
+
+// Write the packet into the current segment (rotate first if it is full)
+func (r *Recorder) write(item recordedPacket) error {
+	if r.segment != nil && r.segment.full(r.config, item.duration) {
+		if err := r.finishSegment(); err != nil {
+			return err
+		}
+	}
+	if r.segment == nil {
+		if item.packet == nil && r.config.Format == RecordOgg {
+			// do not start a segment with a lost packet
+			return nil
+		}
+		if err := r.startSegment(); err != nil {
+			return err
+		}
+	}
+
+	if err := r.segment.write(item); err != nil {
+		return err
+	}
+	if r.gap || len(r.segment.info.Spans) == 0 {
+		r.segment.info.Spans = append(r.segment.info.Spans, Span{Time: item.time, Offset: r.segment.info.Duration})
+		r.gap = false
+	}
+	r.segment.info.Spans[len(r.segment.info.Spans)-1].Duration += item.duration
+	if r.segment.info.Start.IsZero() {
+		r.segment.info.Start = item.time
+	}
+	r.segment.info.End = item.time.Add(item.duration)
+	r.segment.info.Duration += item.duration
+	return nil
+}
+
+func (r *Recorder) startSegment() error {
+	r.index++
+	path := filepath.Join(r.config.Directory, fmt.Sprintf("%s-%04d.%s", r.config.Name, r.index, r.config.Format))
+
+	segment := &segmentWriter{
+		info: Segment{
+			Index: r.index,
+			Path:  path,
+			Spans: []Span{},
+		},
+	}
+
+	var err error
+	switch r.config.Format {
+	case RecordOgg:
+		segment.ogg, err = oggwriter.New(path, opusRTPClockRate, uint16(r.config.Channels))
+	case RecordWAV:
+		segment.wav, err = NewWAVFile(path, r.config.SampleRate, r.config.Channels)
+		segment.info.Size = 44
+	}
+	if err != nil {
+		return errors.New("could not create segment: " + err.Error())
+	}
+
+	r.segment = segment
+	return nil
+}
+
+// Close the current segment and call the OnSegment listeners
+func (r *Recorder) finishSegment() error {
+	if r.segment == nil {
+		return nil
+	}
+	segment := r.segment
+	r.segment = nil
+
+	err := segment.close()
+	if stat, statErr := os.Stat(segment.info.Path); statErr == nil {
+		segment.info.Size = stat.Size()
+	}
+	r.segments = append(r.segments, segment.info)
+	r.gap = true
+
+	for _, fun := range r.onSegment {
+		fun(segment.info)
+	}
+	return err
+}
+
+// segmentWriter writes one segment
+type segmentWriter struct {
+	info Segment
+
+	ogg       *oggwriter.OggWriter
+	timestamp uint32 // the timestamps in the file are without gaps
+
+	wav *WAVWriter
+}
+
+// true if the packet does not fit into the segment anymore
+func (s *segmentWriter) full(config RecorderConfig, duration time.Duration) bool {
+	if config.MaxDuration > 0 && s.info.Duration+duration > config.MaxDuration {
+		return true
+	}
+	return config.MaxSize > 0 && s.info.Size >= config.MaxSize
+}
+
+func (s *segmentWriter) write(item recordedPacket) error {
+	if s.ogg != nil {
+		if item.packet != nil {
+			// The timestamp is rewritten, so the trimmed silence is not in the file.
+			packet := *item.packet
+			packet.Timestamp = s.timestamp
+			if err := s.ogg.WriteRTP(&packet); err != nil {
+				return errors.New("could not write ogg: " + err.Error())
+			}
+			// page header, segment table and payload
+			s.info.Size += int64(27 + len(packet.Payload)/255 + 1 + len(packet.Payload))
+		}
+		// a lost packet is a gap in the timestamps, so the player conceals it
+		s.timestamp += uint32(item.duration * opusRTPClockRate / time.Second)
+		return nil
+	}
+
+	if len(item.frame.Samples) == 0 {
+		return errors.New("could not write wav: the audio is not decoded (a Decoder is needed)")
+	}
+	if err := s.wav.WritePCM(item.frame); err != nil {
+		return err
+	}
+	s.info.Size += int64(len(item.frame.Samples) * 2)
+	return nil
+}
+
+func (s *segmentWriter) close() error {
+	if s.ogg != nil {
+		return s.ogg.Close()
+	}
+	return s.wav.Close()
+}
@@ -0,0 +1,131 @@
+package audio
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+// Test for Segment.Offset
+func TestSegmentOffset(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	segment := Segment{
+		Spans: []Span{
+			{Time: start, Offset: 0, Duration: 2 * time.Second},
+			{Time: start.Add(10 * time.Second), Offset: 2 * time.Second, Duration: 3 * time.Second},
+		},
+	}
+
+	tests := []struct {
+		time     time.Time
+		expected time.Duration
+		found    bool
+	}{
+		{time: start, expected: 0, found: true},
+		{time: start.Add(time.Second), expected: time.Second, found: true},
+		{time: start.Add(5 * time.Second), expected: 0, found: false}, // trimmed
+		{time: start.Add(11 * time.Second), expected: 3 * time.Second, found: true},
+		{time: start.Add(-time.Second), expected: 0, found: false},
+	}
+
+	for num, test := range tests {
+		result, found := segment.Offset(test.time)
+		if result != test.expected || found != test.found {
+			t.Errorf("Segment.Offset() %d FAILED: got %v %v, expected %v %v", num, result, found, test.expected, test.found)
+		} else {
+			t.Logf("Segment.Offset() %d PASSED", num)
+		}
+	}
+}
+
+// Test for Recorder (rotation and silence trimming)
+func TestRecorder(t *testing.T) {
+	loud := make([]int16, 160)
+	for i := range loud {
+		loud[i] = 10000
+	}
+	silent := make([]int16, 160)
+
+	tests := []struct {
+		maxDuration time.Duration
+		trim        bool
+		input       string            // L loud, S silent, - no samples (20ms each)
+		expected    [][]time.Duration // offset and duration of the spans of every segment
+	}{
+		{
+			maxDuration: 100 * time.Millisecond,
+			input:       "----------",
+			expected: [][]time.Duration{
+				{0, 100 * time.Millisecond},
+				{0, 100 * time.Millisecond},
+			},
+		},
+		{
+			trim:  true,
+			input: "SSSLLSSSSSL",
+			expected: [][]time.Duration{
+				{0, 100 * time.Millisecond, 100 * time.Millisecond, 40 * time.Millisecond},
+			},
+		},
+		{
+			trim:     true,
+			input:    "SSSS",
+			expected: [][]time.Duration{},
+		},
+	}
+
+	for num, test := range tests {
+		config := DefaultRecorderConfig(t.TempDir(), "test")
+		config.SampleRate = 8000
+		config.Channels = 1
+		config.MaxDuration = test.maxDuration
+		config.TrimSilence = test.trim
+		config.Silence.EndDuration = 40 * time.Millisecond
+		config.Silence.PreRoll = 20 * time.Millisecond
+
+		recorder, err := NewRecorder(config)
+		if err != nil {
+			t.Fatalf("Recorder() %d FAILED: %v", num, err)
+		}
+		for i, c := range test.input {
+			packet := &rtp.Packet{
+				Header:  rtp.Header{SequenceNumber: uint16(i), Timestamp: uint32(i * 960)},
+				Payload: []byte{0xfc, 0xff, 0xfe},
+			}
+			frame := PCMFrame{SampleRate: 8000, Channels: 1}
+			switch c {
+			case 'L':
+				frame.Samples = loud
+			case 'S':
+				frame.Samples = silent
+			}
+			if err := recorder.WritePacket(packet, frame); err != nil {
+				t.Fatalf("Recorder() %d FAILED: %v", num, err)
+			}
+		}
+		if err := recorder.Close(); err != nil {
+			t.Fatalf("Recorder() %d FAILED: %v", num, err)
+		}
+
+		result := [][]time.Duration{}
+		for _, segment := range recorder.Segments() {
+			spans := []time.Duration{}
+			for _, span := range segment.Spans {
+				spans = append(spans, span.Offset, span.Duration)
+			}
+			result = append(result, spans)
+			if _, err := os.Stat(segment.Path); err != nil {
+				t.Errorf("Recorder() %d FAILED: %v", num, err)
+			}
+		}
+
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Recorder() %d FAILED: got %v, expected %v", num, result, test.expected)
+		} else {
+			t.Logf("Recorder() %d PASSED", num)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package audio
+
+// The format most speech recognition engines expect
+const (
+	SpeechSampleRate = 16000
+	SpeechChannels   = 1
+)
+
+// Resampler is a Sink that converts the frames to mono with another sample rate and writes them to the next sink.
+// When the sample rate is reduced, the samples are averaged (a simple low-pass filter), otherwise they are interpolated.
+type Resampler struct {
+	next       Sink
+	sampleRate int
+
+	pending []float64 // mono samples of the input that are not used yet
+	pos     float64   // position of the next output sample in pending
+}
+
+// NewResampler creates a Resampler that writes mono frames with sampleRate to next
+func NewResampler(next Sink, sampleRate int) *Resampler {
+	return &Resampler{
+		next:       next,
+		sampleRate: sampleRate,
+		pending:    []float64{},
+	}
+}
+
+// NewSpeechResampler creates a Resampler that writes 16 kHz mono frames to next (e.g. for speech recognition)
+func NewSpeechResampler(next Sink) *Resampler {
+	return NewResampler(next, SpeechSampleRate)
+}
+
+// WritePCM converts the frame and writes it to the next sink
+func (r *Resampler) WritePCM(frame PCMFrame) error {
+	if frame.Channels < 1 || frame.SampleRate < 1 {
+		return nil
+	}
+
+	// mix all channels into one
+	for i := 0; i+frame.Channels <= len(frame.Samples); i += frame.Channels {
+		sum := 0.0
+		for ch := 0; ch < frame.Channels; ch++ {
+			sum += float64(frame.Samples[i+ch])
+		}
+		r.pending = append(r.pending, sum/float64(frame.Channels))
+	}
+
+	step := float64(frame.SampleRate) / float64(r.sampleRate)
+	out := []int16{}
+	for {
+		if step >= 1 {
+			// average all input samples of this output sample
+			end := r.pos + step
+			if int(end) > len(r.pending) {
+				break
+			}
+			start := int(r.pos)
+			sum := 0.0
+			for _, sample := range r.pending[start:int(end)] {
+				sum += sample
+			}
+			out = append(out, clip(sum/float64(int(end)-start)))
+		} else {
+			// interpolate between the two input samples
+			i := int(r.pos)
+			if i+1 >= len(r.pending) {
+				break
+			}
+			frac := r.pos - float64(i)
+			out = append(out, clip(r.pending[i]*(1-frac)+r.pending[i+1]*frac))
+		}
+		r.pos += step
+	}
+
+	// remove the used samples
+	used := int(r.pos)
+	if used > len(r.pending) {
+		used = len(r.pending)
+	}
+	r.pending = r.pending[used:]
+	r.pos -= float64(used)
+
+	if len(out) == 0 {
+		return nil
+	}
+	return r.next.WritePCM(PCMFrame{
+		Samples:    out,
+		SampleRate: r.sampleRate,
+		Channels:   1,
+		Lost:       frame.Lost,
+	})
+}
+
+// Close closes the next sink
+func (r *Resampler) Close() error {
+	return r.next.Close()
+}
+
+func clip(sample float64) int16 {
+	if sample > 32767 {
+		return 32767
+	}
+	if sample < -32768 {
+		return -32768
+	}
+	return int16(sample)
+}
@@ -0,0 +1,75 @@
+package audio
+
+import (
+	"reflect"
+	"testing"
+)
+
+type collectSink struct {
+	frames []PCMFrame
+}
+
+func (s *collectSink) WritePCM(frame PCMFrame) error {
+	s.frames = append(s.frames, frame)
+	return nil
+}
+
+func (s *collectSink) Close() error {
+	return nil
+}
+
+// Test for Resampler (downmix, down- and upsampling across frames)
+func TestResampler(t *testing.T) {
+	tests := []struct {
+		sampleRate int
+		input      []PCMFrame
+		expected   []int16
+	}{
+		{
+			sampleRate: 16000,
+			input: []PCMFrame{
+				{Samples: []int16{10, 30, 100, 200}, SampleRate: 16000, Channels: 2},
+			},
+			expected: []int16{20, 150},
+		},
+		{
+			sampleRate: 16000,
+			input: []PCMFrame{
+				{Samples: []int16{3, 6, 9, 30, 60}, SampleRate: 48000, Channels: 1},
+				{Samples: []int16{90, 300}, SampleRate: 48000, Channels: 1},
+			},
+			expected: []int16{6, 60},
+		},
+		{
+			sampleRate: 16000,
+			input: []PCMFrame{
+				{Samples: []int16{0, 100, 200}, SampleRate: 8000, Channels: 1},
+			},
+			expected: []int16{0, 50, 100, 150},
+		},
+	}
+
+	for num, test := range tests {
+		sink := &collectSink{}
+		resampler := NewResampler(sink, test.sampleRate)
+		for _, frame := range test.input {
+			if err := resampler.WritePCM(frame); err != nil {
+				t.Errorf("Resampler() %d FAILED: %v", num, err)
+			}
+		}
+
+		result := []int16{}
+		for _, frame := range sink.frames {
+			if frame.SampleRate != test.sampleRate || frame.Channels != 1 {
+				t.Errorf("Resampler() %d FAILED: wrong format %d Hz %d channels", num, frame.SampleRate, frame.Channels)
+			}
+			result = append(result, frame.Samples...)
+		}
+
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Resampler() %d FAILED: got %v, expected %v", num, result, test.expected)
+		} else {
+			t.Logf("Resampler() %d PASSED", num)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// VADConfig configures the voice activity detection
+type VADConfig struct {
+	// Level in dBFS (0 is the loudest) above which a frame is speech
+	Threshold float64
+	// How long the level has to be above the threshold to start an utterance
+	StartDuration time.Duration
+	// How long the level has to be below the threshold to end an utterance
+	EndDuration time.Duration
+	// How much audio before the start is added to the utterance (the first syllable is often quiet)
+	PreRoll time.Duration
+	// Longer utterances are split (e.g. for speech recognition). 0 does not split.
+	MaxDuration time.Duration
+	// Keep the samples of the utterances (Utterance.Samples)
+	KeepAudio bool
+}
+
+// DefaultVADConfig returns a config that works for the audio of a meeting
+func DefaultVADConfig() VADConfig {
+	return VADConfig{
+		Threshold:     -45,
+		StartDuration: 60 * time.Millisecond,
+		EndDuration:   600 * time.Millisecond,
+		PreRoll:       300 * time.Millisecond,
+		MaxDuration:   30 * time.Second,
+		KeepAudio:     false,
+	}
+}
+
+// Utterance is a part of the audio with speech
+type Utterance struct {
+	// Position in the audio stream (since the first frame)
+	Start time.Duration
+	End   time.Duration
+	// Wall clock time of Start and End
+	StartTime time.Time
+	EndTime   time.Time
+
+	// Audio of the utterance (only with VADConfig.KeepAudio and only at the end of the utterance)
+	Samples    []int16
+	SampleRate int
+	Channels   int
+}
+
+// Duration returns how long the utterance is
+func (u Utterance) Duration() time.Duration {
+	return u.End - u.Start
+}
+
+// VAD is a Sink that detects speech by the energy of the audio.
+// Only the frames of the utterances are written to its sinks, so the silence does not need to be
+// transcribed or recorded.
+type VAD struct {
+	mutex  *sync.Mutex
+	config VADConfig
+	sinks  []Sink
+
+	onStart []func(Utterance)
+	onEnd   []func(Utterance)
+
+	startedAt time.Time     // wall clock time of the first frame
+	position  time.Duration // position of the end of the last frame
+
+	speaking bool
+	speech   time.Duration // how long the level is above the threshold (before the start)
+	silence  time.Duration // how long the level is below the threshold (after the start)
+	preRoll  []PCMFrame
+	current  Utterance
+	closed   bool
+}
+
+// NewVAD creates a VAD
+func NewVAD(config VADConfig) *VAD {
+	return &VAD{
+		mutex:  new(sync.Mutex),
+		config: config,
+		sinks:  []Sink{},
+
+		onStart: []func(Utterance){},
+		onEnd:   []func(Utterance){},
+
+		preRoll: []PCMFrame{},
+	}
+}
+
+// AddSink adds a sink for the frames of the utterances
+func (v *VAD) AddSink(sink Sink) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.sinks = append(v.sinks, sink)
+}
+
+// OnUtteranceStart is called when an utterance starts (Samples and End are not known yet)
+func (v *VAD) OnUtteranceStart(fun func(Utterance)) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.onStart = append(v.onStart, fun)
+}
+
+// OnUtteranceEnd is called when an utterance ends
+func (v *VAD) OnUtteranceEnd(fun func(Utterance)) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.onEnd = append(v.onEnd, fun)
+}
+
+// WritePCM detects the speech in the frame
+func (v *VAD) WritePCM(frame PCMFrame) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.closed {
+		return errors.New("vad is closed")
+	}
+	if v.startedAt.IsZero() {
+		v.startedAt = time.Now()
+	}
+
+	duration := frame.Duration()
+	v.position += duration
+	voiced := Level(frame.Samples) >= v.config.Threshold
+
+	if !v.speaking {
+		v.addPreRoll(frame)
+		if voiced {
+			v.speech += duration
+		} else {
+			v.speech = 0
+		}
+		if v.speech < v.config.StartDuration {
+			return nil
+		}
+
+		// start with the audio before
+		start := v.position
+		for _, f := range v.preRoll {
+			start -= f.Duration()
+		}
+		v.startUtterance(start)
+		var firstErr error
+		for _, f := range v.preRoll {
+			if err := v.write(f); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		v.preRoll = []PCMFrame{}
+		return firstErr
+	}
+
+	err := v.write(frame)
+	if voiced {
+		v.silence = 0
+	} else {
+		v.silence += duration
+	}
+
+	if v.silence >= v.config.EndDuration {
+		v.endUtterance(v.position - v.silence)
+	} else if v.config.MaxDuration > 0 && v.position-v.current.Start >= v.config.MaxDuration {
+		v.endUtterance(v.position)
+		v.startUtterance(v.position)
+	}
+	return err
+}
+
+// Close ends the current utterance and closes the sinks
+func (v *VAD) Close() error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.closed {
+		return nil
+	}
+	v.closed = true
+
+	if v.speaking {
+		v.endUtterance(v.position - v.silence)
+	}
+
+	var firstErr error
+	for _, sink := range v.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Level returns the level (RMS) of the samples in dBFS. Silence is -Inf.
+func Level(samples []int16) float64 {
+	if len(samples) == 0 {
+		return math.Inf(-1)
+	}
+	sum := 0.0
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+	return 20 * math.Log10(rms/32768)
+}
+
+// Keeps the frames before the start of an utterance
+func (v *VAD) addPreRoll(frame PCMFrame) {
+	v.preRoll = append(v.preRoll, frame)
+
+	total := time.Duration(0)
+	for _, f := range v.preRoll {
+		total += f.Duration()
+	}
+	for len(v.preRoll) > 1 && total-v.preRoll[0].Duration() >= v.config.PreRoll+v.config.StartDuration {
+		total -= v.preRoll[0].Duration()
+		v.preRoll = v.preRoll[1:]
+	}
+}
+
+func (v *VAD) startUtterance(start time.Duration) {
+	v.speaking = true
+	v.speech = 0
+	v.silence = 0
+	v.current = Utterance{
+		Start:     start,
+		StartTime: v.startedAt.Add(start),
+		Samples:   []int16{},
+	}
+
+	for _, fun := range v.onStart {
+		go fun(v.current)
+	}
+}
+
+func (v *VAD) endUtterance(end time.Duration) {
+	v.speaking = false
+	utterance := v.current
+	utterance.End = end
+	utterance.EndTime = v.startedAt.Add(end)
+
+	if v.config.KeepAudio && utterance.SampleRate > 0 {
+		// remove the silence at the end
+		length := int(int64(utterance.Duration())*int64(utterance.SampleRate)/int64(time.Second)) * utterance.Channels
+		if length < len(utterance.Samples) {
+			utterance.Samples = utterance.Samples[:length]
+		}
+	} else {
+		utterance.Samples = nil
+	}
+	v.current = Utterance{}
+
+	for _, fun := range v.onEnd {
+		go fun(utterance)
+	}
+}
+
+// Writes the frame of an utterance to the sinks
+func (v *VAD) write(frame PCMFrame) error {
+	v.current.SampleRate = frame.SampleRate
+	v.current.Channels = frame.Channels
+	if v.config.KeepAudio {
+		v.current.Samples = append(v.current.Samples, frame.Samples...)
+	}
+
+	var firstErr error
+	for _, sink := range v.sinks {
+		if err := sink.WritePCM(frame); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,116 @@
+package audio
+
+import (
+	"testing"
+	"time"
+)
+
+// Test for VAD (start, end, pre-roll and splitting of utterances)
+func TestVAD(t *testing.T) {
+	const ms = time.Millisecond
+	type span struct {
+		start time.Duration
+		end   time.Duration
+	}
+
+	tests := []struct {
+		maxDuration time.Duration
+		input       string // one 20ms frame per character: s is speech, . is silence
+		expected    []span
+	}{
+		{
+			input:    "..........",
+			expected: []span{},
+		},
+		{
+			// too short to start
+			input:    "..s.......",
+			expected: []span{},
+		},
+		{
+			// pre-roll of 300ms: starts at 0
+			input:    "....ssssss..............................",
+			expected: []span{{start: 0, end: 200 * ms}},
+		},
+		{
+			// the silence before the second utterance belongs to the first one
+			input:    "....................ssssss..............................ssss..............................",
+			expected: []span{{start: 100 * ms, end: 520 * ms}, {start: 1120 * ms, end: 1200 * ms}},
+		},
+		{
+			// ended by Close
+			input:    "ssssss",
+			expected: []span{{start: 0, end: 120 * ms}},
+		},
+		{
+			maxDuration: 100 * ms,
+			input:       "ssssssss",
+			expected:    []span{{start: 0, end: 100 * ms}, {start: 100 * ms, end: 160 * ms}},
+		},
+	}
+
+	speech := make([]int16, 320)
+	for i := range speech {
+		speech[i] = 8000
+		if i%2 == 1 {
+			speech[i] = -8000
+		}
+	}
+	silence := make([]int16, 320)
+
+	for num, test := range tests {
+		config := DefaultVADConfig()
+		config.MaxDuration = test.maxDuration
+		config.KeepAudio = true
+		vad := NewVAD(config)
+
+		utterances := make(chan Utterance, 10)
+		vad.OnUtteranceEnd(func(u Utterance) {
+			utterances <- u
+		})
+
+		for _, c := range test.input {
+			samples := silence
+			if c == 's' {
+				samples = speech
+			}
+			if err := vad.WritePCM(PCMFrame{Samples: samples, SampleRate: 16000, Channels: 1}); err != nil {
+				t.Errorf("VAD() %d FAILED: %v", num, err)
+			}
+		}
+		vad.Close()
+
+		result := []span{}
+		for len(result) < len(test.expected) {
+			select {
+			case u := <-utterances:
+				result = append(result, span{start: u.Start, end: u.End})
+				if len(u.Samples) != int(u.Duration()/ms)*16 {
+					t.Errorf("VAD() %d FAILED: %d samples for %v", num, len(u.Samples), u.Duration())
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("VAD() %d FAILED: got %v, expected %v", num, result, test.expected)
+			}
+		}
+		time.Sleep(10 * ms)
+		if len(utterances) > 0 {
+			t.Errorf("VAD() %d FAILED: too many utterances", num)
+		}
+
+		// the order of the listeners is not fixed
+		if len(result) == 2 && result[0].start > result[1].start {
+			result[0], result[1] = result[1], result[0]
+		}
+		failed := false
+		for i := range result {
+			if result[i] != test.expected[i] {
+				failed = true
+			}
+		}
+		if failed {
+			t.Errorf("VAD() %d FAILED: got %v, expected %v", num, result, test.expected)
+		} else {
+			t.Logf("VAD() %d PASSED", num)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// WAVWriter is a Sink that writes a 16 bit PCM WAV file.
+// The sizes in the header are written on Close, if the writer can seek (e.g. a file).
+type WAVWriter struct {
+	writer     io.Writer
+	closer     io.Closer
+	sampleRate int
+	channels   int
+
+	headerWritten bool
+	dataSize      uint32
+}
+
+// NewWAVFile creates the file and returns a WAVWriter for it
+func NewWAVFile(path string, sampleRate int, channels int) (*WAVWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, errors.New("could not create wav file: " + err.Error())
+	}
+	w := NewWAVWriter(file, sampleRate, channels)
+	w.closer = file
+	return w, nil
+}
+
+// NewWAVWriter creates a WAVWriter that writes into w. The frames have to have sampleRate and channels.
+func NewWAVWriter(w io.Writer, sampleRate int, channels int) *WAVWriter {
+	return &WAVWriter{
+		writer:     w,
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+}
+
+// WritePCM writes the samples of the frame
+func (w *WAVWriter) WritePCM(frame PCMFrame) error {
+	if frame.SampleRate != w.sampleRate || frame.Channels != w.channels {
+		return errors.New("could not write wav: the frame has another format")
+	}
+	if !w.headerWritten {
+		// The sizes are unknown. They are the maximum until Close writes them.
+		if err := w.writeHeader(0xffffffff - 36); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	if err := binary.Write(w.writer, binary.LittleEndian, frame.Samples); err != nil {
+		return errors.New("could not write wav: " + err.Error())
+	}
+	w.dataSize += uint32(len(frame.Samples) * 2)
+	return nil
+}
+
+// Close writes the sizes into the header and closes the file
+func (w *WAVWriter) Close() error {
+	var err error
+	if !w.headerWritten {
+		err = w.writeHeader(0)
+		w.headerWritten = true
+	} else if seeker, ok := w.writer.(io.WriteSeeker); ok {
+		if _, err = seeker.Seek(0, io.SeekStart); err == nil {
+			err = w.writeHeader(w.dataSize)
+		}
+	}
+
+	if w.closer != nil {
+		if closeErr := w.closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		w.closer = nil
+	}
+	if err != nil {
+		return errors.New("could not write wav: " + err.Error())
+	}
+	return nil
+}
+
+// Write the 44 byte header of a PCM WAV file
+func (w *WAVWriter) writeHeader(dataSize uint32) error {
+	header := make([]byte, 44)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], 36+dataSize)
+	copy(header[8:], "WAVE")
+	copy(header[12:], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)                                // size of fmt chunk
+	binary.LittleEndian.PutUint16(header[20:], 1)                                 // PCM
+	binary.LittleEndian.PutUint16(header[22:], uint16(w.channels))                // channels
+	binary.LittleEndian.PutUint32(header[24:], uint32(w.sampleRate))              // sample rate
+	binary.LittleEndian.PutUint32(header[28:], uint32(w.sampleRate*w.channels*2)) // byte rate
+	binary.LittleEndian.PutUint16(header[32:], uint16(w.channels*2))              // block align
+	binary.LittleEndian.PutUint16(header[34:], 16)                                // bits per sample
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], dataSize)
+
+	_, err := w.writer.Write(header)
+	return err
+}
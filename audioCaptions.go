@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"errors"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+	pad "github.com/bigbluebutton-bot/bigbluebutton-bot/pad"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/transcribe"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// capture, err := client.CreateCapture("en", chsetExternal, chsetHost, chsetPort)
+// if err != nil {
+// 	panic(err)
+// }
+// audioClient := client.CreateAudioChannel()
+// err = audioClient.ListenToAudio()
+// if err != nil {
+// 	panic(err)
+// }
+// decoder, err := audio.NewOpusDecoder(48000, 2) // needs: go build -tags opus
+// if err != nil {
+// 	panic(err)
+// }
+// transcriber, err := transcribe.DialWebSocketTranscriber("ws://localhost:2700", "en")
+// if err != nil {
+// 	panic(err)
+// }
+// pipeline, err := audioClient.TranscribeToCaptions(decoder, transcriber, capture)
+// if err != nil {
+// 	panic(err)
+// }
+// pipeline.SetMaxLines(10)
+
+// TranscribeToCaptions transcribes the audio of the meeting and writes the text to the captions (see CreateCapture).
+// captions can be nil, if the results are only used with OnResult of the pipeline.
+// The pipeline (and the transcriber) is closed when the audio ends.
+func (c *AudioClient) TranscribeToCaptions(decoder audio.Decoder, transcriber transcribe.Transcriber, captions *pad.Pad) (*transcribe.Pipeline, error) {
+	if decoder == nil {
+		return nil, errors.New("decoder is nil")
+	}
+	if transcriber == nil {
+		return nil, errors.New("transcriber is nil")
+	}
+
+	// a nil *pad.Pad would not be a nil CaptionWriter
+	var pipeline *transcribe.Pipeline
+	if captions != nil {
+		pipeline = transcribe.NewPipeline(transcriber, captions)
+	} else {
+		pipeline = transcribe.NewPipeline(transcriber, nil)
+	}
+	pipeline.SetLogger(c.log())
+
+	receiver := audio.NewReceiver(decoder, 48000, 2)
+	receiver.AddSink(pipeline)
+	if err := c.ReceiveAudio(receiver); err != nil {
+		pipeline.Close()
+		return nil, err
+	}
+	return pipeline, nil
+}
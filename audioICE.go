@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pion/webrtc/v3"
+)
+
+//  EXAMPLE in main.go (e.g. in a kubernetes pod)
+// --------------------
+// audioClient := client.CreateAudioChannel()
+// audioClient.ICE.ExtraICEServers = []webrtc.ICEServer{{
+// 	URLs:       []string{"turn:turn.example.com:3478?transport=udp"},
+// 	Username:   "user",
+// 	Credential: "password",
+// }}
+// audioClient.ICE.PortMin = 40000
+// audioClient.ICE.PortMax = 40100
+// audioClient.ICE.NAT1To1IPs = []string{os.Getenv("NODE_IP")}
+// err = audioClient.ListenToAudio()
+
+// ICEConfig configures how the peer connections of the audio connect (see AudioClient.ICE)
+type ICEConfig struct {
+	// used instead of the STUN and TURN servers of the bbb server (/api/stuns is not requested).
+	// nil uses the servers of the bbb server.
+	ICEServers []webrtc.ICEServer
+	// used in addition to the STUN and TURN servers of the bbb server (or ICEServers)
+	ExtraICEServers []webrtc.ICEServer
+	// ListenToAudio fails if there is no TURN server
+	RequireTURN bool
+	// only connect over a TURN server (no direct connection). Needs a TURN server.
+	RelayOnly bool
+
+	// the range of the local UDP ports (0 uses any port)
+	PortMin uint16
+	PortMax uint16
+	// only use these network interfaces (e.g. eth0). Empty uses all.
+	Interfaces []string
+	// the network types (e.g. only webrtc.NetworkTypeUDP4). Empty uses the default of pion.
+	NetworkTypes []webrtc.NetworkType
+	// the public IPs of the host (e.g. of the node of a pod), used instead of the local IPs (host candidates)
+	// or as server reflexive candidates (see NAT1To1CandidateType)
+	NAT1To1IPs           []string
+	NAT1To1CandidateType webrtc.ICECandidateType
+	// also use the loopback interface (e.g. for a local sfu)
+	IncludeLoopback bool
+}
+
+// DefaultICEConfig returns the ICE config of a new AudioClient: the servers of the bbb server and a TURN server is needed
+func DefaultICEConfig() ICEConfig {
+	return ICEConfig{
+		ICEServers:           nil,
+		ExtraICEServers:      []webrtc.ICEServer{},
+		RequireTURN:          true,
+		RelayOnly:            false,
+		PortMin:              0,
+		PortMax:              0,
+		Interfaces:           []string{},
+		NetworkTypes:         []webrtc.NetworkType{},
+		NAT1To1IPs:           []string{},
+		NAT1To1CandidateType: webrtc.ICECandidateTypeHost,
+		IncludeLoopback:      false,
+	}
+}
+
+// The ICE servers of the config and the bbb server. requireTURN fails if there is no TURN server.
+func (c ICEConfig) servers(stunServers []stunServers, turnServers []turnServers, requireTURN bool) ([]webrtc.ICEServer, error) {
+	iceServers := []webrtc.ICEServer{}
+	if c.ICEServers != nil {
+		iceServers = append(iceServers, c.ICEServers...)
+	} else {
+		for _, stun := range stunServers {
+			iceServers = append(iceServers, webrtc.ICEServer{
+				URLs: []string{stun.URL},
+			})
+		}
+		for _, turn := range turnServers {
+			iceServers = append(iceServers, webrtc.ICEServer{
+				URLs:       []string{turn.URL},
+				Username:   turn.Username,
+				Credential: turn.Password,
+			})
+		}
+	}
+	iceServers = append(iceServers, c.ExtraICEServers...)
+
+	if (requireTURN || c.RelayOnly) && !hasTURNServer(iceServers) {
+		if c.ICEServers == nil && len(c.ExtraICEServers) == 0 {
+			return nil, errors.New("bbb api: No turn servers provided")
+		}
+		return nil, errors.New("no turn servers configured")
+	}
+	return iceServers, nil
+}
+
+// The settings of the network of the peer connection
+func (c ICEConfig) settingEngine() (webrtc.SettingEngine, error) {
+	settings := webrtc.SettingEngine{}
+
+	if c.PortMin != 0 || c.PortMax != 0 {
+		if err := settings.SetEphemeralUDPPortRange(c.PortMin, c.PortMax); err != nil {
+			return settings, errors.New("invalid udp port range: " + err.Error())
+		}
+	}
+	if len(c.Interfaces) > 0 {
+		interfaces := append([]string{}, c.Interfaces...)
+		settings.SetInterfaceFilter(func(name string) bool {
+			for _, i := range interfaces {
+				if i == name {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if len(c.NetworkTypes) > 0 {
+		settings.SetNetworkTypes(c.NetworkTypes)
+	}
+	if len(c.NAT1To1IPs) > 0 {
+		candidateType := c.NAT1To1CandidateType
+		if candidateType == webrtc.ICECandidateType(0) {
+			candidateType = webrtc.ICECandidateTypeHost
+		}
+		settings.SetNAT1To1IPs(c.NAT1To1IPs, candidateType)
+	}
+	settings.SetIncludeLoopbackCandidate(c.IncludeLoopback)
+
+	return settings, nil
+}
+
+// The configuration of the peer connection
+func (c ICEConfig) configuration(iceServers []webrtc.ICEServer) webrtc.Configuration {
+	policy := webrtc.ICETransportPolicyAll
+	if c.RelayOnly {
+		policy = webrtc.ICETransportPolicyRelay
+	}
+	return webrtc.Configuration{
+		ICEServers:         iceServers,
+		ICETransportPolicy: policy,
+	}
+}
+
+func hasTURNServer(iceServers []webrtc.ICEServer) bool {
+	for _, server := range iceServers {
+		for _, url := range server.URLs {
+			if strings.HasPrefix(url, "turn:") || strings.HasPrefix(url, "turns:") {
+				return true
+			}
+		}
+	}
+	return false
+}
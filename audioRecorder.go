@@ -0,0 +1,209 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// audioClient := client.CreateAudioChannel()
+// err = audioClient.ListenToAudio()
+// if err != nil {
+// 	panic(err)
+// }
+// config := audio.DefaultRecorderConfig("recordings", client.ExternalMeetingID)
+// config.MaxDuration = 10 * time.Minute
+// recording, err := audioClient.Record(nil, config) // Ogg/Opus needs no decoder (except for TrimSilence)
+// if err != nil {
+// 	panic(err)
+// }
+// // ... the recording is finished when the audio client is closed or the meeting ends
+// recording.Stop()
+
+// Recording records the audio of the meeting into files. Next to every file a JSON file
+// (e.g. meeting-0001.ogg.json) with the meeting, the time and the speakers is written.
+type Recording struct {
+	recorder *audio.Recorder
+	receiver *audio.Receiver
+	timeline *SpeakerTimeline // nil if the talking of the users is unknown
+
+	meetingID         string
+	internalMeetingID string
+	start             time.Time
+}
+
+type recordingMetadata struct {
+	MeetingID         string             `json:"meetingId"`
+	InternalMeetingID string             `json:"internalMeetingId"`
+	RecordingStart    time.Time          `json:"recordingStart"`
+	Segment           int                `json:"segment"`
+	File              string             `json:"file"`
+	Start             time.Time          `json:"start"`
+	End               time.Time          `json:"end"`
+	Duration          float64            `json:"duration"` // seconds of audio in the file
+	Size              int64              `json:"size"`
+	Spans             []recordingSpan    `json:"spans"`
+	Speakers          []recordingSpeaker `json:"speakers"`
+}
+
+type recordingSpan struct {
+	Time     time.Time `json:"time"`
+	Offset   float64   `json:"offset"` // seconds
+	Duration float64   `json:"duration"`
+}
+
+type recordingSpeaker struct {
+	UserID string    `json:"userId"`
+	Name   string    `json:"name"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	// Position in the file (seconds). Missing if the start was not recorded (e.g. trimmed).
+	Offset *float64 `json:"offset,omitempty"`
+}
+
+// Record records the audio of the meeting (see audio.RecorderConfig). The decoder can be nil for
+// Ogg/Opus without TrimSilence. The recording is finished by Stop, Close of the AudioClient or the end of the meeting.
+func (c *AudioClient) Record(decoder audio.Decoder, config audio.RecorderConfig) (*Recording, error) {
+	if decoder == nil && (config.Format == audio.RecordWAV || config.TrimSilence) {
+		return nil, errors.New("a decoder is needed to record wav or to trim the silence")
+	}
+
+	recorder, err := audio.NewRecorder(config)
+	if err != nil {
+		return nil, err
+	}
+
+	recording := &Recording{
+		recorder: recorder,
+
+		meetingID:         c.client.ExternalMeetingID,
+		internalMeetingID: c.client.InternalMeetingID,
+		start:             time.Now(),
+	}
+
+	timeline, err := c.client.NewSpeakerTimeline()
+	if err != nil {
+		c.log().Warn("recording without speakers", "error", err)
+	} else {
+		timeline.mutex.Lock()
+		timeline.history = 0 // a segment can be longer than the history
+		timeline.mutex.Unlock()
+		recording.timeline = timeline
+	}
+
+	recorder.OnSegment(func(segment audio.Segment) {
+		if err := recording.writeMetadata(segment); err != nil {
+			c.log().Warn("could not write recording metadata", "file", segment.Path, "error", err)
+		}
+	})
+
+	receiver := audio.NewReceiver(decoder, config.SampleRate, config.Channels)
+	receiver.AddPacketSink(recorder)
+	// closed after the recorder, so the speakers of the last file are known
+	receiver.AddPacketSink(recordingEnd{recording})
+	recording.receiver = receiver
+	if err := c.ReceiveAudio(receiver); err != nil {
+		recorder.Close()
+		recording.stopTimeline()
+		return nil, err
+	}
+	return recording, nil
+}
+
+// Stop finishes the recording (the last file and its metadata)
+func (r *Recording) Stop() error {
+	return r.receiver.Close()
+}
+
+// Stop the timeline, so the client does not inform it anymore
+func (r *Recording) stopTimeline() {
+	if r.timeline != nil {
+		r.timeline.Stop()
+	}
+}
+
+// recordingEnd is the last packet sink of the receiver of a Recording. It stops the timeline when the
+// receiver is closed (by Stop, Close of the AudioClient or the end of the meeting).
+type recordingEnd struct {
+	recording *Recording
+}
+
+func (e recordingEnd) WritePacket(packet *rtp.Packet, frame audio.PCMFrame) error {
+	return nil
+}
+
+func (e recordingEnd) Close() error {
+	e.recording.stopTimeline()
+	return nil
+}
+
+// Segments returns the finished files
+func (r *Recording) Segments() []audio.Segment {
+	return r.recorder.Segments()
+}
+
+// Write the JSON file of the segment
+func (r *Recording) writeMetadata(segment audio.Segment) error {
+	metadata := recordingMetadata{
+		MeetingID:         r.meetingID,
+		InternalMeetingID: r.internalMeetingID,
+		RecordingStart:    r.start,
+		Segment:           segment.Index,
+		File:              filepath.Base(segment.Path),
+		Start:             segment.Start,
+		End:               segment.End,
+		Duration:          segment.Duration.Seconds(),
+		Size:              segment.Size,
+		Spans:             []recordingSpan{},
+		Speakers:          []recordingSpeaker{},
+	}
+
+	for _, span := range segment.Spans {
+		metadata.Spans = append(metadata.Spans, recordingSpan{
+			Time:     span.Time,
+			Offset:   span.Offset.Seconds(),
+			Duration: span.Duration.Seconds(),
+		})
+	}
+
+	if r.timeline != nil {
+		for _, interval := range r.timeline.Intervals(segment.Start, segment.End) {
+			speaker := recordingSpeaker{
+				UserID: interval.UserID,
+				Name:   interval.User.Name,
+				Start:  interval.Start,
+				End:    interval.End,
+			}
+			if offset, found := segment.Offset(interval.Start); found {
+				seconds := offset.Seconds()
+				speaker.Offset = &seconds
+			}
+			metadata.Speakers = append(metadata.Speakers, speaker)
+		}
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so there is never a half written file
+	path := segment.Path + ".json"
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,408 @@
+package bot
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/interceptor/pkg/stats"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media"
+
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// audio := client.CreateAudioChannel()
+// err = audio.PublishAudio(bot.AudioRoleSendOnly)
+// if err != nil {
+// 	panic(err)
+// }
+// writer, err := audio.OpusWriter()
+// if err != nil {
+// 	panic(err)
+// }
+// for _, frame := range opusFrames { // 20ms opus frames, e.g. read with oggreader
+// 	writer.WriteFrame(frame, 20*time.Millisecond)
+// }
+
+// AudioRole is the direction of the audio of the bot
+type AudioRole string
+
+const (
+	AudioRoleRecv     AudioRole = "recv"     // only listen (ListenToAudio)
+	AudioRoleSendRecv AudioRole = "sendrecv" // listen and speak (PublishAudio)
+	AudioRoleSendOnly AudioRole = "sendonly" // only speak (PublishAudio)
+)
+
+// The opus codec of the audio the bot sends (like a browser does)
+const (
+	opusClockRate = 48000
+	opusChannels  = 2
+	opusFmtp      = "minptime=10;useinbandfec=1"
+)
+
+// PublishAudio joins the audio of the meeting with a microphone, so the bot can speak
+// (see OpusWriter and PCMWriter). With AudioRoleSendRecv the bot also receives the audio of the meeting (see OnTrack).
+func (c *AudioClient) PublishAudio(role AudioRole) error {
+	if role != AudioRoleSendRecv && role != AudioRoleSendOnly {
+		return errors.New("could not publish audio: role has to be sendrecv or sendonly")
+	}
+
+	// The track stays the same if the audio is rejoined
+	track, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{
+		MimeType:    webrtc.MimeTypeOpus,
+		ClockRate:   opusClockRate,
+		Channels:    opusChannels,
+		SDPFmtpLine: opusFmtp,
+	}, "audio", "bbb-bot")
+	if err != nil {
+		return errors.New("failed to create audio track: " + err.Error())
+	}
+	if err := c.join(role, track); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	c.frameWriter = &OpusFrameWriter{
+		track: track,
+		mutex: new(sync.Mutex),
+	}
+	c.mutex.Unlock()
+
+	return nil
+}
+
+// Connect to the audio with a microphone: the bot makes the offer
+func (c *AudioClient) connectPublisher(role AudioRole) (*audioConnection, error) {
+	timeout := time.After(c.ConnectTimeout)
+
+	c.mutex.Lock()
+	track := c.localTrack
+	c.mutex.Unlock()
+	if track == nil {
+		return nil, errors.New("could not publish audio: no audio track")
+	}
+
+	iceServers, voiceBridge, err := c.audioParameters(false)
+	if err != nil {
+		return nil, err
+	}
+	caleeName := strconv.FormatInt(int64(voiceBridge), 10)
+
+	connection := newAudioConnection()
+
+	// Create a PeerConnection with the audio track of the bot
+	connection.peerConnection, connection.stats, err = createPublisherPeerConnection(c.log(), c.ICE, iceServers, track, role)
+	if err != nil {
+		return nil, err
+	}
+	c.handleTracks(connection.peerConnection)
+
+	// Connect to the signalling server. The sfu only knows sendrecv for a microphone.
+	connection.signalling, err = newSignalling(c.log(), c.client.WebRTCWSURL, c.client.SessionToken, c.client.SessionCookie, string(AudioRoleSendRecv), voiceBridge, c.ConnectTimeout, c.PingInterval+c.PingTimeout)
+	if err != nil {
+		connection.close()
+		return nil, err
+	}
+	c.log().Debug("connected to signalling server", "url", c.client.WebRTCWSURL)
+	connection.watch()
+	if c.TrickleICE {
+		c.sendCandidates(connection)
+	}
+
+	// Generate the SDP offer
+	sdpOffer, err := generateSDPOffer(connection.peerConnection, c.TrickleICE, timeout)
+	if err != nil {
+		connection.close()
+		return nil, err
+	}
+
+	// Send join message with the offer
+	err = sendJoinMessage(connection.signalling, AudioRoleSendRecv, c.client.InternalMeetingID, voiceBridge, caleeName, c.client.InternalUserID, c.client.UserName, sdpOffer)
+	if err != nil {
+		connection.close()
+		return nil, err
+	}
+	if err := connection.signalling.startTrickle(); err != nil {
+		connection.close()
+		return nil, errors.New("failed to send ice candidates: " + err.Error())
+	}
+
+	// Read join response (this time it is the answer to the offer)
+	joinResponse, err := connection.signalling.waitForStartResponse(timeout)
+	if err != nil {
+		connection.close()
+		return nil, err
+	}
+	err = connection.peerConnection.SetRemoteDescription(webrtc.SessionDescription{
+		Type: webrtc.SDPTypeAnswer,
+		SDP:  joinResponse.SdpAnswer,
+	})
+	if err != nil {
+		connection.close()
+		return nil, errors.New("failed to set remote description: " + err.Error())
+	}
+	connection.signalling.addCandidatesTo(connection.peerConnection)
+
+	// Start ping loop
+	connection.signalling.pingLoop(c.PingInterval)
+
+	// Wait until the audio flows
+	if err := connection.signalling.waitForMediaFlowing(timeout); err != nil {
+		connection.close()
+		return nil, err
+	}
+
+	return connection, nil
+}
+
+// Mute mutes or unmutes the microphone of the bot
+func (c *AudioClient) Mute(mute bool) error {
+	return c.client.MuteUser(c.client.InternalUserID, mute)
+}
+
+// OpusWriter returns the writer for opus frames. The audio has to be published (see PublishAudio).
+func (c *AudioClient) OpusWriter() (*OpusFrameWriter, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.frameWriter == nil {
+		return nil, errors.New("could not get opus writer: the audio is not published")
+	}
+	return c.frameWriter, nil
+}
+
+// PCMWriter returns a writer for PCM audio (16 bit signed little endian, interleaved channels).
+// The PCM audio is encoded in frames of 20ms by the encoder (e.g. *opus.Encoder of gopkg.in/hraban/opus.v2
+// created with the same sampleRate and channels). The audio has to be published (see PublishAudio).
+func (c *AudioClient) PCMWriter(encoder OpusEncoder, sampleRate int, channels int) (*PCMWriter, error) {
+	frames, err := c.OpusWriter()
+	if err != nil {
+		return nil, errors.New("could not get pcm writer: the audio is not published")
+	}
+	return NewPCMWriter(frames, encoder, sampleRate, channels)
+}
+
+// Create a PeerConnection that sends the audio track of the bot
+func createPublisherPeerConnection(log logger.Logger, ice ICEConfig, iceServers []webrtc.ICEServer, track *webrtc.TrackLocalStaticSample, role AudioRole) (*webrtc.PeerConnection, stats.Getter, error) {
+	// Setup the codecs
+	m := &webrtc.MediaEngine{}
+	if err := m.RegisterCodec(webrtc.RTPCodecParameters{
+		RTPCodecCapability: track.Codec(),
+		PayloadType:        111,
+	}, webrtc.RTPCodecTypeAudio); err != nil {
+		return nil, nil, errors.New("failed to register opus codec: " + err.Error())
+	}
+
+	// Use the default set of Interceptors (NACKs, RTCP Reports, ...)
+	i := &interceptor.Registry{}
+	if err := webrtc.RegisterDefaultInterceptors(m, i); err != nil {
+		return nil, nil, errors.New("failed to register interceptors: " + err.Error())
+	}
+	statsGetter, err := registerStatsInterceptor(i)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settings, err := ice.settingEngine()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	webrtcapi := webrtc.NewAPI(webrtc.WithMediaEngine(m), webrtc.WithInterceptorRegistry(i), webrtc.WithSettingEngine(settings))
+
+	// Create a new RTCPeerConnection
+	peerConnection, err := webrtcapi.NewPeerConnection(ice.configuration(iceServers))
+	if err != nil {
+		return nil, nil, errors.New("failed to create new peer connection: " + err.Error())
+	}
+
+	peerConnection.OnICEConnectionStateChange(func(connectionState webrtc.ICEConnectionState) {
+		log.Info("ice connection state has changed", "state", connectionState.String())
+	})
+
+	direction := webrtc.RTPTransceiverDirectionSendrecv
+	if role == AudioRoleSendOnly {
+		direction = webrtc.RTPTransceiverDirectionSendonly
+	}
+	transceiver, err := peerConnection.AddTransceiverFromTrack(track, webrtc.RTPTransceiverInit{
+		Direction: direction,
+	})
+	if err != nil {
+		peerConnection.Close()
+		return nil, nil, errors.New("failed to add audio transceiver: " + err.Error())
+	}
+
+	// Read incoming RTCP packets. Before these packets are returned they are processed by interceptors.
+	go func() {
+		rtcpBuf := make([]byte, 1500)
+		for {
+			if _, _, err := transceiver.Sender().Read(rtcpBuf); err != nil {
+				return
+			}
+		}
+	}()
+
+	return peerConnection, statsGetter(), nil
+}
+
+// Generate a SDP offer. Without trickle ICE it waits until all ICE candidates are gathered.
+func generateSDPOffer(peerConnection *webrtc.PeerConnection, trickle bool, timeout <-chan time.Time) (string, error) {
+	// Create channel that is blocked until ICE Gathering is complete
+	gatherComplete := webrtc.GatheringCompletePromise(peerConnection)
+
+	offer, err := peerConnection.CreateOffer(nil)
+	if err != nil {
+		return "", errors.New("failed to create SDP offer: " + err.Error())
+	}
+
+	err = peerConnection.SetLocalDescription(offer)
+	if err != nil {
+		return "", errors.New("failed to set local description: " + err.Error())
+	}
+
+	// With trickle ICE the candidates are sent with iceCandidate messages
+	if !trickle {
+		select {
+		case <-gatherComplete:
+		case <-timeout:
+			return "", errors.New("timeout while gathering ice candidates")
+		}
+	}
+
+	return peerConnection.LocalDescription().SDP, nil
+}
+
+//--------------------------------------------------
+// Writers
+//--------------------------------------------------
+
+// If the writer is behind by more than this, the pacing starts again (e.g. after a pause)
+const maxPacingLag = 200 * time.Millisecond
+
+// OpusFrameWriter sends opus frames in real time. WriteFrame blocks until the frame is due,
+// so the frames can be written as fast as they are available (e.g. from a file).
+type OpusFrameWriter struct {
+	track *webrtc.TrackLocalStaticSample
+	mutex *sync.Mutex
+	next  time.Time // when the next frame has to be sent
+}
+
+// WriteFrame sends one opus frame with the given duration (usually 20ms)
+func (w *OpusFrameWriter) WriteFrame(frame []byte, duration time.Duration) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := time.Now()
+	if w.next.IsZero() || now.Sub(w.next) > maxPacingLag {
+		w.next = now
+	} else if w.next.After(now) {
+		time.Sleep(w.next.Sub(now))
+	}
+
+	if err := w.track.WriteSample(media.Sample{Data: frame, Duration: duration}); err != nil {
+		return errors.New("could not write opus frame: " + err.Error())
+	}
+	w.next = w.next.Add(duration)
+
+	return nil
+}
+
+// OpusEncoder encodes one frame of PCM samples into data and returns the length of the opus frame.
+// *opus.Encoder of gopkg.in/hraban/opus.v2 implements this interface.
+type OpusEncoder interface {
+	Encode(pcm []int16, data []byte) (int, error)
+}
+
+// PCMWriter is an io.Writer for PCM audio (16 bit signed little endian, interleaved channels).
+// The audio is encoded and sent in frames of 20ms.
+type PCMWriter struct {
+	frames    *OpusFrameWriter
+	encoder   OpusEncoder
+	frameSize int // samples of all channels in 20ms
+
+	samples []int16 // samples that are not encoded yet
+	odd     []byte  // the first byte of a sample, if the last write ended in the middle of a sample
+	out     []byte
+}
+
+// The duration of one frame of the PCMWriter
+const pcmFrameDuration = 20 * time.Millisecond
+
+// NewPCMWriter creates a PCMWriter that sends the encoded audio to frames
+func NewPCMWriter(frames *OpusFrameWriter, encoder OpusEncoder, sampleRate int, channels int) (*PCMWriter, error) {
+	if encoder == nil {
+		return nil, errors.New("could not create pcm writer: no encoder")
+	}
+	if sampleRate <= 0 || channels <= 0 {
+		return nil, errors.New("could not create pcm writer: invalid sample rate or channels")
+	}
+	frameSize := sampleRate * int(pcmFrameDuration/time.Millisecond) / 1000 * channels
+	return &PCMWriter{
+		frames:    frames,
+		encoder:   encoder,
+		frameSize: frameSize,
+		samples:   make([]int16, 0, frameSize),
+		odd:       make([]byte, 0, 1),
+		out:       make([]byte, 4000),
+	}, nil
+}
+
+// Write sends the PCM audio. It blocks like OpusFrameWriter.WriteFrame.
+func (w *PCMWriter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	if len(w.odd) == 1 && len(p) > 0 {
+		sample := int16(binary.LittleEndian.Uint16([]byte{w.odd[0], p[0]}))
+		w.odd = w.odd[:0]
+		p = p[1:]
+		if err := w.addSample(sample); err != nil {
+			return n - len(p), err
+		}
+	}
+	for len(p) >= 2 {
+		sample := int16(binary.LittleEndian.Uint16(p))
+		p = p[2:]
+		if err := w.addSample(sample); err != nil {
+			return n - len(p), err
+		}
+	}
+	if len(p) == 1 {
+		w.odd = append(w.odd, p[0])
+	}
+
+	return n, nil
+}
+
+// Add the sample and send the frame if it is full
+func (w *PCMWriter) addSample(sample int16) error {
+	w.samples = append(w.samples, sample)
+	if len(w.samples) < w.frameSize {
+		return nil
+	}
+	return w.sendFrame()
+}
+
+// Flush sends the remaining samples (filled up with silence)
+func (w *PCMWriter) Flush() error {
+	if len(w.samples) == 0 {
+		return nil
+	}
+	for len(w.samples) < w.frameSize {
+		w.samples = append(w.samples, 0)
+	}
+	return w.sendFrame()
+}
+
+func (w *PCMWriter) sendFrame() error {
+	length, err := w.encoder.Encode(w.samples, w.out)
+	w.samples = w.samples[:0]
+	if err != nil {
+		return errors.New("could not encode pcm: " + err.Error())
+	}
+	return w.frames.WriteFrame(w.out[:length], pcmFrameDuration)
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"encoding/binary"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// counts the encoded frames and keeps their samples
+type frameCollector struct {
+	frames [][]int16
+}
+
+func (e *frameCollector) Encode(pcm []int16, data []byte) (int, error) {
+	e.frames = append(e.frames, append([]int16{}, pcm...))
+	data[0] = byte(len(pcm))
+	return 1, nil
+}
+
+func newTestFrameWriter(t *testing.T) *OpusFrameWriter {
+	track, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus}, "audio", "bot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &OpusFrameWriter{track: track, mutex: new(sync.Mutex)}
+}
+
+// Test for PCMWriter with writes that end in the middle of a sample
+func TestPCMWriterOddWrites(t *testing.T) {
+	encoder := &frameCollector{}
+	// 100 Hz mono: 2 samples (4 bytes) per frame
+	writer, err := NewPCMWriter(newTestFrameWriter(t), encoder, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pcm := make([]byte, 104)
+	for i := 0; i < len(pcm)/2; i++ {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(i))
+	}
+	for _, write := range [][]byte{pcm[:3], pcm[3:4], pcm[4:]} {
+		if n, err := writer.Write(write); err != nil || n != len(write) {
+			t.Fatalf("PCMWriter.Write() FAILED: wrote %d of %d bytes (%v)", n, len(write), err)
+		}
+	}
+
+	expected := [][]int16{}
+	for i := 0; i < len(pcm)/2; i += 2 {
+		expected = append(expected, []int16{int16(i), int16(i + 1)})
+	}
+	if !reflect.DeepEqual(encoder.frames, expected) || len(writer.samples) != 0 {
+		t.Errorf("PCMWriter.Write() FAILED: got frames %v and %d pending samples, expected %v and 0", encoder.frames, len(writer.samples), expected)
+	} else {
+		t.Logf("PCMWriter.Write() PASSED")
+	}
+}
+
+// Test for OpusFrameWriter with frames of an odd length (the pacing only depends on the duration)
+func TestOpusFrameWriterOddFrames(t *testing.T) {
+	writer := newTestFrameWriter(t)
+
+	start := time.Now()
+	for _, frame := range [][]byte{{1}, {1, 2, 3}, {1, 2, 3, 4, 5}} {
+		if err := writer.WriteFrame(frame, 20*time.Millisecond); err != nil {
+			t.Fatalf("OpusFrameWriter.WriteFrame() FAILED: %v", err)
+		}
+	}
+
+	// The third frame is due after the first two (40ms)
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond || writer.next.Sub(start) < 60*time.Millisecond {
+		t.Errorf("OpusFrameWriter.WriteFrame() FAILED: 3 frames took %s and the next is due after %s, expected at least 40ms and 60ms", elapsed, writer.next.Sub(start))
+	} else {
+		t.Logf("OpusFrameWriter.WriteFrame() PASSED")
+	}
+}
@@ -0,0 +1,286 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pion/webrtc/v3"
+
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
+)
+
+// signalling is the websocket connection to bbb-webrtc-sfu for one audio connection.
+// All messages are read by one loop, so the answers, the ICE candidates and the pongs can come in any order.
+type signalling struct {
+	log        logger.Logger
+	conn       *websocket.Conn
+	writeMutex *sync.Mutex
+
+	role        string // role of the iceCandidate messages (recv or sendrecv)
+	voiceBridge int
+
+	readTimeout time.Duration // no message (not even a pong) for this long closes the connection
+
+	// the local ICE candidates are held back until the SDP is sent (see startTrickle)
+	candidateMutex    *sync.Mutex
+	trickleStarted    bool
+	pendingCandidates []webrtc.ICECandidateInit
+
+	startResponse chan signallingMessage
+	mediaFlowing  chan signallingMessage
+	candidates    chan webrtc.ICECandidateInit // closed when the connection ends
+
+	closeOnce *sync.Once
+	done      chan struct{} // closed when the connection ends
+	err       error         // why the connection ended (only read after done)
+}
+
+// All messages of bbb-webrtc-sfu for the audio
+type signallingMessage struct {
+	ID        string                   `json:"id"`
+	Type      string                   `json:"type"`
+	Response  string                   `json:"response"`  // startResponse: accepted or rejected
+	SdpAnswer string                   `json:"sdpAnswer"` // startResponse
+	Success   string                   `json:"success"`   // webRTCAudioSuccess: MEDIA_FLOWING
+	Candidate *webrtc.ICECandidateInit `json:"candidate"` // iceCandidate
+	Error     interface{}              `json:"error"`     // webRTCAudioError
+}
+
+type iceCandidateMessage struct {
+	ID          string                  `json:"id"`
+	Type        string                  `json:"type"`
+	Role        string                  `json:"role"`
+	VoiceBridge int                     `json:"voiceBridge"`
+	Candidate   webrtc.ICECandidateInit `json:"candidate"`
+}
+
+// Connect to the signalling server and start to read the messages
+func newSignalling(log logger.Logger, webrtcwsurl string, token string, cookies []*http.Cookie, role string, voiceBridge int, connectTimeout time.Duration, readTimeout time.Duration) (*signalling, error) {
+	conn, err := connectToWebSocketSignallingServer(webrtcwsurl, token, cookies, connectTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &signalling{
+		log:        log,
+		conn:       conn,
+		writeMutex: new(sync.Mutex),
+
+		role:        role,
+		voiceBridge: voiceBridge,
+
+		readTimeout: readTimeout,
+
+		candidateMutex:    new(sync.Mutex),
+		pendingCandidates: []webrtc.ICECandidateInit{},
+
+		startResponse: make(chan signallingMessage, 1),
+		mediaFlowing:  make(chan signallingMessage, 1),
+		candidates:    make(chan webrtc.ICECandidateInit, 100),
+
+		closeOnce: new(sync.Once),
+		done:      make(chan struct{}),
+	}
+	go s.readLoop()
+	return s, nil
+}
+
+// Connect to the websocket of bbb-webrtc-sfu
+func connectToWebSocketSignallingServer(webrtcwsurl, token string, cookies []*http.Cookie, timeout time.Duration) (*websocket.Conn, error) {
+	// Parse url
+	u, err := url.Parse(webrtcwsurl + "?sessionToken=" + token)
+	if err != nil {
+		return nil, errors.New("failed to parse WebRTC WebSocket URL: " + err.Error())
+	}
+	wsurl := u.String()
+
+	// Create CookieJar
+	coockieJar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, errors.New("failed to create cookie jar: " + err.Error())
+	}
+	tempurl := url.URL(*u)
+	tempurl.Scheme = "https"
+	coockieJar.SetCookies(&tempurl, cookies)
+
+	// Create dialer (a copy, so the cookies are not added to the default dialer)
+	wsdialer := *websocket.DefaultDialer
+	wsdialer.Jar = coockieJar
+	wsdialer.HandshakeTimeout = timeout
+
+	// Connect to the WebSocket signalling server
+	conn, _, err := wsdialer.Dial(wsurl, http.Header{})
+	if err != nil {
+		return nil, errors.New("failed to connect to WebSocket: " + err.Error())
+	}
+
+	return conn, nil
+}
+
+// Reads all messages until the connection ends
+func (s *signalling) readLoop() {
+	defer close(s.candidates)
+
+	for {
+		s.conn.SetReadDeadline(time.Now().Add(s.readTimeout))
+
+		var message signallingMessage
+		if err := s.conn.ReadJSON(&message); err != nil {
+			s.close(errors.New("signalling connection closed: " + err.Error()))
+			return
+		}
+
+		switch message.ID {
+		case "startResponse":
+			select {
+			case s.startResponse <- message:
+			default:
+			}
+		case "webRTCAudioSuccess":
+			select {
+			case s.mediaFlowing <- message:
+			default:
+			}
+		case "iceCandidate":
+			if message.Candidate == nil {
+				continue
+			}
+			select {
+			case s.candidates <- *message.Candidate:
+			default:
+				s.log.Warn("dropped remote ice candidate", "candidate", message.Candidate.Candidate)
+			}
+		case "pong":
+			s.log.Debug("received pong response")
+		case "webRTCAudioError", "error":
+			s.close(fmt.Errorf("signalling server returned an error: %v", message.Error))
+			return
+		default:
+			s.log.Debug("received unknown signalling message", "id", message.ID)
+		}
+	}
+}
+
+// Send a message
+func (s *signalling) send(message interface{}) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	return s.conn.WriteJSON(message)
+}
+
+// Wait for the startResponse (the SDP of the sfu)
+func (s *signalling) waitForStartResponse(timeout <-chan time.Time) (signallingMessage, error) {
+	select {
+	case message := <-s.startResponse:
+		if message.Response != "" && message.Response != "accepted" {
+			return message, errors.New("start was " + message.Response + " by the signalling server")
+		}
+		return message, nil
+	case <-s.done:
+		return signallingMessage{}, s.err
+	case <-timeout:
+		return signallingMessage{}, errors.New("timeout while waiting for the start response")
+	}
+}
+
+// Wait until the audio flows
+func (s *signalling) waitForMediaFlowing(timeout <-chan time.Time) error {
+	select {
+	case status := <-s.mediaFlowing:
+		if status.Success != "MEDIA_FLOWING" {
+			return errors.New("status response was not successful. Unable to establish webrtc audio connection. ID: " + status.ID + " ,Type: " + status.Type + " ,Success: " + status.Success)
+		}
+		return nil
+	case <-s.done:
+		return s.err
+	case <-timeout:
+		return errors.New("timeout while waiting for the audio to flow")
+	}
+}
+
+// Send a local ICE candidate (trickle ICE). Before startTrickle the candidate is held back.
+func (s *signalling) sendCandidate(candidate webrtc.ICECandidateInit) error {
+	s.candidateMutex.Lock()
+	defer s.candidateMutex.Unlock()
+
+	if !s.trickleStarted {
+		s.pendingCandidates = append(s.pendingCandidates, candidate)
+		return nil
+	}
+	return s.writeCandidate(candidate)
+}
+
+// Send the held back local ICE candidates. The sfu knows the session after the SDP was sent.
+func (s *signalling) startTrickle() error {
+	s.candidateMutex.Lock()
+	defer s.candidateMutex.Unlock()
+
+	s.trickleStarted = true
+	for _, candidate := range s.pendingCandidates {
+		if err := s.writeCandidate(candidate); err != nil {
+			return err
+		}
+	}
+	s.pendingCandidates = []webrtc.ICECandidateInit{}
+	return nil
+}
+
+func (s *signalling) writeCandidate(candidate webrtc.ICECandidateInit) error {
+	return s.send(iceCandidateMessage{
+		ID:          "iceCandidate",
+		Type:        "audio",
+		Role:        s.role,
+		VoiceBridge: s.voiceBridge,
+		Candidate:   candidate,
+	})
+}
+
+// Add the remote ICE candidates to the peer connection. The remote description has to be set before.
+func (s *signalling) addCandidatesTo(peerConnection *webrtc.PeerConnection) {
+	go func() {
+		for candidate := range s.candidates {
+			if err := peerConnection.AddICECandidate(candidate); err != nil {
+				s.log.Warn("could not add remote ice candidate", "candidate", candidate.Candidate, "error", err)
+			}
+		}
+	}()
+}
+
+type pingpong struct {
+	ID string `json:"id"`
+}
+
+// Send a ping every interval until the connection ends. The pongs are read by the read loop.
+func (s *signalling) pingLoop(interval time.Duration) {
+	go func() {
+		for {
+			select {
+			case <-s.done:
+				s.log.Debug("stopping ping loop")
+				return
+			case <-time.After(interval):
+				if err := s.send(pingpong{ID: "ping"}); err != nil {
+					s.close(errors.New("failed to send ping message: " + err.Error()))
+					return
+				}
+				s.log.Debug("sent ping message")
+			}
+		}
+	}()
+}
+
+// Close the connection. The first reason is kept.
+func (s *signalling) close(reason error) {
+	s.closeOnce.Do(func() {
+		s.err = reason
+		s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		s.conn.Close()
+		close(s.done)
+	})
+}
@@ -0,0 +1,255 @@
+package bot
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// timeline, err := client.NewSpeakerTimeline()
+// if err != nil {
+// 	panic(err)
+// }
+// vad := audio.NewVAD(audio.DefaultVADConfig())
+// receiver.AddSink(vad) // receiver of audioClient.ReceiveAudio
+// timeline.OnUtterance(vad, func(utterance bot.SpeakerUtterance) {
+// 	if len(utterance.Speakers) > 0 {
+// 		fmt.Println(utterance.Speakers[0].User.Name + " said something for " + utterance.Duration().String())
+// 	}
+// })
+// // ...
+// timeline.Stop()
+
+// The talking state of BBB is a bit later than the audio, so the utterances are compared with a tolerance
+const speakerTolerance = 500 * time.Millisecond
+
+// How long the talking of the users is kept
+const speakerHistory = 10 * time.Minute
+
+// Speaker is a user who talked during an utterance
+type Speaker struct {
+	UserID string
+	User   bbb.User      // empty if the user is unknown (e.g. dial-in)
+	Talked time.Duration // how long the user talked during the utterance
+}
+
+// SpeakerUtterance is an utterance of the audio with the users who talked
+type SpeakerUtterance struct {
+	audio.Utterance
+	Speakers []Speaker // the user who talked the longest is first
+}
+
+// SpeakerTimeline keeps track of who talked when (voice-users), to find the speakers of the utterances of a VAD.
+type SpeakerTimeline struct {
+	mutex     *sync.Mutex
+	talking   map[string]VoiceEvent // users who talk now (by user id)
+	last      map[string]time.Time  // time of the last event of the users (by user id, see isStale)
+	intervals []talkInterval
+	history   time.Duration // how long the intervals are kept (0 keeps all)
+
+	client    *Client
+	listeners map[string]uint64 // ids of the voice event listeners (removed by Stop)
+	stopped   bool
+}
+
+// SpeakerInterval is a time in which a user talked
+type SpeakerInterval struct {
+	UserID string
+	User   bbb.User // empty if the user is unknown (e.g. dial-in)
+	Start  time.Time
+	End    time.Time
+}
+
+type talkInterval struct {
+	userID string
+	user   bbb.User
+	start  time.Time
+	end    time.Time
+}
+
+// NewSpeakerTimeline creates a SpeakerTimeline. It starts to record who talks.
+func (c *Client) NewSpeakerTimeline() (*SpeakerTimeline, error) {
+	t := &SpeakerTimeline{
+		mutex:     new(sync.Mutex),
+		talking:   make(map[string]VoiceEvent),
+		last:      make(map[string]time.Time),
+		intervals: []talkInterval{},
+		history:   speakerHistory,
+
+		client: c,
+	}
+
+	if err := c.subscribeVoiceActivity(); err != nil {
+		return nil, err
+	}
+	t.listeners = map[string]uint64{
+		"OnTalkingStarted": c.addListener("OnTalkingStarted", voiceEventListener(t.talkingStarted)),
+		"OnTalkingStopped": c.addListener("OnTalkingStopped", voiceEventListener(t.talkingStopped)),
+	}
+	return t, nil
+}
+
+// Stop stops recording who talks and removes the listeners from the client.
+// The users who talk now stop talking, the intervals are kept.
+func (t *SpeakerTimeline) Stop() {
+	t.mutex.Lock()
+	if t.stopped {
+		t.mutex.Unlock()
+		return
+	}
+	t.stopped = true
+	t.intervals = t.allIntervals()
+	t.talking = make(map[string]VoiceEvent)
+	listeners := t.listeners
+	t.mutex.Unlock()
+
+	for event, id := range listeners {
+		t.client.removeListener(event, id)
+	}
+}
+
+// OnUtterance calls fun with the speakers of every utterance of the vad
+func (t *SpeakerTimeline) OnUtterance(vad *audio.VAD, fun func(SpeakerUtterance)) {
+	vad.OnUtteranceEnd(func(utterance audio.Utterance) {
+		fun(t.Attribute(utterance))
+	})
+}
+
+// Attribute returns the utterance with the users who talked during it
+func (t *SpeakerTimeline) Attribute(utterance audio.Utterance) SpeakerUtterance {
+	return SpeakerUtterance{
+		Utterance: utterance,
+		Speakers:  t.Speakers(utterance.StartTime, utterance.EndTime),
+	}
+}
+
+// Speakers returns the users who talked between start and end. The user who talked the longest is first.
+func (t *SpeakerTimeline) Speakers(start time.Time, end time.Time) []Speaker {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	start = start.Add(-speakerTolerance)
+	end = end.Add(speakerTolerance)
+
+	speakers := map[string]*Speaker{}
+	for _, interval := range t.allIntervals() {
+		from := maxTime(start, interval.start)
+		to := minTime(end, interval.end)
+		if !to.After(from) {
+			continue
+		}
+		speaker, found := speakers[interval.userID]
+		if !found {
+			speaker = &Speaker{UserID: interval.userID, User: interval.user}
+			speakers[interval.userID] = speaker
+		}
+		speaker.Talked += to.Sub(from)
+	}
+
+	result := []Speaker{}
+	for _, speaker := range speakers {
+		result = append(result, *speaker)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Talked != result[j].Talked {
+			return result[i].Talked > result[j].Talked
+		}
+		return result[i].UserID < result[j].UserID
+	})
+	return result
+}
+
+// Intervals returns who talked between start and end (cut to start and end), sorted by the start.
+// A user who still talks ends now.
+func (t *SpeakerTimeline) Intervals(start time.Time, end time.Time) []SpeakerInterval {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := []SpeakerInterval{}
+	for _, interval := range t.allIntervals() {
+		from := maxTime(start, interval.start)
+		to := minTime(end, interval.end)
+		if !to.After(from) {
+			continue
+		}
+		result = append(result, SpeakerInterval{UserID: interval.userID, User: interval.user, Start: from, End: to})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Start.Equal(result[j].Start) {
+			return result[i].Start.Before(result[j].Start)
+		}
+		return result[i].UserID < result[j].UserID
+	})
+	return result
+}
+
+// The finished intervals and the users who talk now (until now)
+func (t *SpeakerTimeline) allIntervals() []talkInterval {
+	now := time.Now()
+	intervals := append([]talkInterval{}, t.intervals...)
+	for userID, event := range t.talking {
+		intervals = append(intervals, talkInterval{userID: userID, user: event.User, start: event.Time, end: now})
+	}
+	return intervals
+}
+
+func (t *SpeakerTimeline) talkingStarted(event VoiceEvent) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.stopped || t.isStale(event) {
+		return
+	}
+	t.talking[event.VoiceUser.IntId] = event
+}
+
+func (t *SpeakerTimeline) talkingStopped(event VoiceEvent) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.stopped || t.isStale(event) {
+		return
+	}
+
+	delete(t.talking, event.VoiceUser.IntId)
+	t.intervals = append(t.intervals, talkInterval{
+		userID: event.VoiceUser.IntId,
+		user:   event.User,
+		start:  event.Time.Add(-event.Duration),
+		end:    event.Time,
+	})
+
+	// remove the old talking
+	for t.history > 0 && len(t.intervals) > 0 && time.Since(t.intervals[0].end) > t.history {
+		t.intervals = t.intervals[1:]
+	}
+}
+
+// Returns true if a newer event of the user was already received. The listeners are called in their own
+// goroutines, so an older event can be received late. Otherwise the event is the last one of the user.
+// t.mutex has to be locked.
+func (t *SpeakerTimeline) isStale(event VoiceEvent) bool {
+	userID := event.VoiceUser.IntId
+	if last, found := t.last[userID]; found && event.Time.Before(last) {
+		return true
+	}
+	t.last[userID] = event.Time
+	return false
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
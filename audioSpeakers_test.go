@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+// Test for SpeakerTimeline.Stop (the listeners are removed and the talking ends)
+func TestSpeakerTimelineStop(t *testing.T) {
+	client, err := NewClient("http://127.0.0.1/html5client/", "ws://127.0.0.1/html5client/websocket", "http://127.0.0.1/pad/", "ws://127.0.0.1/pad/", "http://127.0.0.1/bigbluebutton/api/", "secret", "ws://127.0.0.1/bbb-webrtc-sfu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// like NewSpeakerTimeline without the subscription of the voice users
+	timeline := &SpeakerTimeline{
+		mutex:     new(sync.Mutex),
+		talking:   make(map[string]VoiceEvent),
+		last:      make(map[string]time.Time),
+		intervals: []talkInterval{},
+		client:    client,
+	}
+	timeline.listeners = map[string]uint64{
+		"OnTalkingStarted": client.addListener("OnTalkingStarted", voiceEventListener(timeline.talkingStarted)),
+		"OnTalkingStopped": client.addListener("OnTalkingStopped", voiceEventListener(timeline.talkingStopped)),
+	}
+	client.addListener("OnTalkingStarted", voiceEventListener(func(VoiceEvent) {}))
+
+	start := time.Now().Add(-2 * time.Second)
+	timeline.talkingStarted(VoiceEvent{Time: start, VoiceUser: bbb.VoiceUser{IntId: "w_1"}})
+	timeline.Stop()
+	stop := time.Now()
+
+	if listeners := client.getListeners("OnTalkingStarted"); len(listeners) != 1 {
+		t.Errorf("SpeakerTimeline.Stop() FAILED: got %d OnTalkingStarted listeners, expected 1", len(listeners))
+	}
+	if listeners := client.getListeners("OnTalkingStopped"); len(listeners) != 0 {
+		t.Errorf("SpeakerTimeline.Stop() FAILED: got %d OnTalkingStopped listeners, expected 0", len(listeners))
+	}
+
+	// the events of listeners that were called before Stop are ignored
+	timeline.talkingStarted(VoiceEvent{Time: stop, VoiceUser: bbb.VoiceUser{IntId: "w_2"}})
+	time.Sleep(10 * time.Millisecond)
+
+	intervals := timeline.Intervals(start.Add(-time.Second), time.Now().Add(time.Second))
+	if len(intervals) != 1 || intervals[0].UserID != "w_1" || !intervals[0].Start.Equal(start) || intervals[0].End.After(stop) {
+		t.Errorf("SpeakerTimeline.Stop() FAILED: got %+v, expected w_1 from %v until the stop", intervals, start)
+	} else {
+		t.Logf("SpeakerTimeline.Stop() PASSED")
+	}
+}
+
+// Test for events that are received in the wrong order (the listeners are called in their own goroutines)
+func TestSpeakerTimelineOrder(t *testing.T) {
+	timeline := &SpeakerTimeline{
+		mutex:     new(sync.Mutex),
+		talking:   make(map[string]VoiceEvent),
+		last:      make(map[string]time.Time),
+		intervals: []talkInterval{},
+	}
+
+	start := time.Now().Add(-3 * time.Second)
+	stop := start.Add(time.Second)
+	user := bbb.VoiceUser{IntId: "w_1"}
+
+	// the stop of the first talk is received before its start
+	timeline.talkingStopped(VoiceEvent{Time: stop, VoiceUser: user, Duration: time.Second})
+	timeline.talkingStarted(VoiceEvent{Time: start, VoiceUser: user})
+	// the second talk is in order
+	timeline.talkingStarted(VoiceEvent{Time: stop.Add(time.Second), VoiceUser: user})
+
+	now := time.Now()
+	intervals := timeline.Intervals(start.Add(-time.Second), now.Add(time.Second))
+	if len(intervals) != 2 || !intervals[0].Start.Equal(start) || !intervals[0].End.Equal(stop) || !intervals[1].Start.Equal(stop.Add(time.Second)) {
+		t.Errorf("SpeakerTimeline order FAILED: got %+v, expected w_1 from %v to %v and from %v until now", intervals, start, stop, stop.Add(time.Second))
+	} else {
+		t.Logf("SpeakerTimeline order PASSED")
+	}
+}
@@ -0,0 +1,252 @@
+package bot
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/interceptor/pkg/stats"
+	"github.com/pion/webrtc/v3"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// audioClient := client.CreateAudioChannel()
+// audioClient.StatsInterval = 10 * time.Second
+// audioClient.OnStats(func(stats bot.AudioStats) {
+// 	fmt.Printf("loss %.1f%%, jitter %s, rtt %s, %s\n", stats.PacketLoss*100, stats.Jitter, stats.RoundTripTime, stats.LocalCandidateType)
+// 	for name, value := range stats.Metrics() {
+// 		gauges.WithLabelValues(name).Set(value) // e.g. a prometheus GaugeVec
+// 	}
+// })
+// err = audioClient.ListenToAudio()
+
+// AudioStats are the statistics of the audio connection (see OnStats).
+// The counters are since the (re)join, the rates are since the last stats.
+type AudioStats struct {
+	Time time.Time
+
+	// the audio of the meeting
+	PacketsReceived uint64
+	PacketsLost     int64
+	PacketLoss      float64       // lost packets / expected packets (0-1)
+	Jitter          time.Duration // interarrival jitter
+	BytesReceived   uint64
+	Bitrate         float64 // received bits per second
+	NACKsSent       uint32  // retransmissions the bot asked for
+
+	// the audio of the bot (only if it publishes audio)
+	PacketsSent   uint64
+	BytesSent     uint64
+	SendBitrate   float64 // sent bits per second
+	NACKsReceived uint32  // retransmissions the sfu asked for
+
+	// the decoding of the receivers of ReceiveAudio (counted since the receivers were added)
+	Frames          uint64
+	ConcealedFrames uint64
+	Concealment     float64 // concealed frames / frames (0-1)
+
+	// the network
+	RoundTripTime       time.Duration
+	LocalCandidateType  string // host, srflx, prflx or relay (relay means TURN is used)
+	RemoteCandidateType string
+	CandidateProtocol   string // udp or tcp
+}
+
+type audioStatsListener func(AudioStats)
+
+// Metrics returns the stats as numbers (e.g. for a metrics exporter). The names are in snake case.
+func (s AudioStats) Metrics() map[string]float64 {
+	relay := 0.0
+	if s.LocalCandidateType == webrtc.ICECandidateTypeRelay.String() || s.RemoteCandidateType == webrtc.ICECandidateTypeRelay.String() {
+		relay = 1
+	}
+	return map[string]float64{
+		"audio_packets_received":        float64(s.PacketsReceived),
+		"audio_packets_lost":            float64(s.PacketsLost),
+		"audio_packet_loss_ratio":       s.PacketLoss,
+		"audio_jitter_seconds":          s.Jitter.Seconds(),
+		"audio_bytes_received":          float64(s.BytesReceived),
+		"audio_bitrate_bps":             s.Bitrate,
+		"audio_nacks_sent":              float64(s.NACKsSent),
+		"audio_packets_sent":            float64(s.PacketsSent),
+		"audio_bytes_sent":              float64(s.BytesSent),
+		"audio_send_bitrate_bps":        s.SendBitrate,
+		"audio_nacks_received":          float64(s.NACKsReceived),
+		"audio_frames":                  float64(s.Frames),
+		"audio_concealed_frames":        float64(s.ConcealedFrames),
+		"audio_concealment_ratio":       s.Concealment,
+		"audio_round_trip_time_seconds": s.RoundTripTime.Seconds(),
+		"audio_relay":                   relay,
+	}
+}
+
+// OnStats is called every StatsInterval while the audio is connected
+func (c *AudioClient) OnStats(listener audioStatsListener) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.statsListeners = append(c.statsListeners, listener)
+}
+
+// GetStats returns the last stats. It is false if there are no stats yet.
+func (c *AudioClient) GetStats() (AudioStats, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.stats, !c.stats.Time.IsZero()
+}
+
+// Collect the stats of the connection until it fails
+func (c *AudioClient) collectStats(connection *audioConnection) {
+	if c.StatsInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.StatsInterval)
+	defer ticker.Stop()
+
+	var previous AudioStats // of this connection
+	for {
+		select {
+		case <-connection.failed:
+			return
+		case <-ticker.C:
+		}
+
+		c.mutex.Lock()
+		last := c.stats
+		receivers := []audio.ReceiverStats{}
+		for _, receiver := range c.receivers {
+			receivers = append(receivers, receiver.Stats())
+		}
+		c.mutex.Unlock()
+
+		stats := readAudioStats(connection, previous, last, receivers)
+		previous = stats
+
+		c.mutex.Lock()
+		c.stats = stats
+		listeners := append([]audioStatsListener{}, c.statsListeners...)
+		c.mutex.Unlock()
+
+		for _, listener := range listeners {
+			go listener(stats)
+		}
+	}
+}
+
+// Read the stats of the connection. previous are the stats of the same connection (to compute the rates)
+// and last the stats before (also of an older connection, for the receivers).
+func readAudioStats(connection *audioConnection, previous AudioStats, last AudioStats, receivers []audio.ReceiverStats) AudioStats {
+	result := AudioStats{Time: time.Now()}
+	peerConnection := connection.peerConnection
+
+	if connection.stats != nil {
+		for _, receiver := range peerConnection.GetReceivers() {
+			for _, track := range receiver.Tracks() {
+				s := connection.stats.Get(uint32(track.SSRC()))
+				if s == nil {
+					continue
+				}
+				result.PacketsReceived += s.InboundRTPStreamStats.PacketsReceived
+				result.PacketsLost += s.InboundRTPStreamStats.PacketsLost
+				result.BytesReceived += s.InboundRTPStreamStats.BytesReceived
+				result.NACKsSent += s.InboundRTPStreamStats.NACKCount
+				if clockRate := track.Codec().ClockRate; clockRate > 0 {
+					// the jitter is in RTP timestamp units
+					jitter := time.Duration(s.InboundRTPStreamStats.Jitter / float64(clockRate) * float64(time.Second))
+					if jitter > result.Jitter {
+						result.Jitter = jitter
+					}
+				}
+				if s.RemoteOutboundRTPStreamStats.RoundTripTimeMeasurements > 0 {
+					result.RoundTripTime = s.RemoteOutboundRTPStreamStats.RoundTripTime
+				}
+			}
+		}
+		for _, sender := range peerConnection.GetSenders() {
+			if sender.Track() == nil {
+				continue
+			}
+			for _, encoding := range sender.GetParameters().Encodings {
+				s := connection.stats.Get(uint32(encoding.SSRC))
+				if s == nil {
+					continue
+				}
+				result.PacketsSent += s.OutboundRTPStreamStats.PacketsSent
+				result.BytesSent += s.OutboundRTPStreamStats.BytesSent
+				result.NACKsReceived += s.OutboundRTPStreamStats.NACKCount
+				if s.RemoteInboundRTPStreamStats.RoundTripTimeMeasurements > 0 {
+					result.RoundTripTime = s.RemoteInboundRTPStreamStats.RoundTripTime
+				}
+			}
+		}
+	}
+
+	// the selected candidate pair
+	for _, report := range peerConnection.GetStats() {
+		pair, ok := report.(webrtc.ICECandidatePairStats)
+		if !ok || !pair.Nominated || pair.State != webrtc.StatsICECandidatePairStateSucceeded {
+			continue
+		}
+		if result.RoundTripTime == 0 {
+			result.RoundTripTime = time.Duration(pair.CurrentRoundTripTime * float64(time.Second))
+		}
+	}
+	if transport := peerConnection.SCTP().Transport().ICETransport(); transport != nil {
+		if pair, err := transport.GetSelectedCandidatePair(); err == nil && pair != nil {
+			result.LocalCandidateType = pair.Local.Typ.String()
+			result.RemoteCandidateType = pair.Remote.Typ.String()
+			result.CandidateProtocol = pair.Local.Protocol.String()
+		}
+	}
+
+	// the rates since the previous stats of this connection
+	if !previous.Time.IsZero() {
+		seconds := result.Time.Sub(previous.Time).Seconds()
+		if seconds > 0 {
+			result.Bitrate = float64(result.BytesReceived-previous.BytesReceived) * 8 / seconds
+			result.SendBitrate = float64(result.BytesSent-previous.BytesSent) * 8 / seconds
+		}
+		result.PacketLoss = lossRatio(result.PacketsReceived-previous.PacketsReceived, result.PacketsLost-previous.PacketsLost)
+	} else {
+		result.PacketLoss = lossRatio(result.PacketsReceived, result.PacketsLost)
+	}
+
+	for _, receiver := range receivers {
+		result.Frames += receiver.Frames
+		result.ConcealedFrames += receiver.Concealed
+	}
+	if frames := result.Frames - last.Frames; result.Frames >= last.Frames && frames > 0 {
+		result.Concealment = float64(result.ConcealedFrames-last.ConcealedFrames) / float64(frames)
+	}
+
+	return result
+}
+
+// lost / (received + lost)
+func lossRatio(received uint64, lost int64) float64 {
+	if lost <= 0 {
+		return 0
+	}
+	return float64(lost) / (float64(received) + float64(lost))
+}
+
+// Add the stats interceptor to the registry. The returned function returns the stats
+// of the peer connection that is created with the registry.
+func registerStatsInterceptor(i *interceptor.Registry) (func() stats.Getter, error) {
+	factory, err := stats.NewInterceptor()
+	if err != nil {
+		return nil, errors.New("failed to create stats interceptor: " + err.Error())
+	}
+
+	var getter stats.Getter
+	factory.OnNewPeerConnection(func(_ string, g stats.Getter) {
+		getter = g
+	})
+	i.Add(factory)
+
+	return func() stats.Getter {
+		return getter
+	}, nil
+}
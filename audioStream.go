@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pion/webrtc/v3"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// audioClient := client.CreateAudioChannel()
+// err = audioClient.ListenToAudio()
+// if err != nil {
+// 	panic(err)
+// }
+// decoder, err := audio.NewOpusDecoder(48000, 2) // needs: go build -tags opus
+// if err != nil {
+// 	panic(err)
+// }
+// receiver := audio.NewReceiver(decoder, 48000, 2)
+// wav, err := audio.NewWAVFile("meeting.wav", 48000, 2)
+// if err != nil {
+// 	panic(err)
+// }
+// receiver.AddSink(wav)
+// speech := audio.NewChannelSink(100)
+// receiver.AddSink(audio.NewSpeechResampler(speech)) // 16 kHz mono
+// err = audioClient.ReceiveAudio(receiver)
+// if err != nil {
+// 	panic(err)
+// }
+// for frame := range speech.C {
+// 	// ...
+// }
+
+// ReceiveAudio writes the opus audio of the meeting into the receiver. It can be called before ListenToAudio.
+// After a rejoin the audio of the new track is written into the same receiver.
+// The receiver (and all its sinks) is closed by Close.
+func (c *AudioClient) ReceiveAudio(receiver *audio.Receiver) error {
+	if receiver == nil {
+		return errors.New("receiver is nil")
+	}
+
+	c.mutex.Lock()
+	c.receivers = append(c.receivers, receiver)
+	c.mutex.Unlock()
+
+	c.addTrackListener(func(track *webrtc.TrackRemote, rtpReceiver *webrtc.RTPReceiver) {
+		if track.Kind() != webrtc.RTPCodecTypeAudio || !strings.EqualFold(track.Codec().MimeType, webrtc.MimeTypeOpus) {
+			c.log().Warn("ignoring track that is not opus audio", "codec", track.Codec().MimeType)
+			return
+		}
+		c.log().Debug("receiving audio track", "id", track.ID())
+
+		for {
+			packet, _, err := track.ReadRTP()
+			if err != nil {
+				c.log().Debug("audio track ended", "error", err)
+				return
+			}
+			if err := receiver.WriteRTP(packet); err != nil {
+				if errors.Is(err, audio.ErrReceiverClosed) {
+					return
+				}
+				c.log().Warn("could not write audio", "error", err)
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"bytes"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/sfutest"
+)
+
+// collects the opus packets of a Receiver
+type packetCollector struct {
+	mutex    *sync.Mutex
+	payloads [][]byte
+	received chan struct{} // gets a value for every packet
+}
+
+func (p *packetCollector) WriteRTP(packet *rtp.Packet) error {
+	p.mutex.Lock()
+	p.payloads = append(p.payloads, append([]byte{}, packet.Payload...))
+	p.mutex.Unlock()
+	select {
+	case p.received <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (p *packetCollector) Close() error {
+	return nil
+}
+
+func (p *packetCollector) count() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.payloads)
+}
+
+// Wait until there are n packets
+func (p *packetCollector) wait(n int, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for p.count() < n {
+		select {
+		case <-p.received:
+		case <-deadline:
+			return false
+		}
+	}
+	return true
+}
+
+// Start a local sfu with a known opus file and a client that is in its meeting
+func newTestAudioClient(t *testing.T, loop bool) (*sfutest.Server, *AudioClient, []sfutest.Packet) {
+	packets := []sfutest.Packet{}
+	for i := 0; i < 50; i++ {
+		// a valid opus TOC byte (20ms CELT) followed by the number of the packet
+		packets = append(packets, sfutest.Packet{Data: []byte{0xfc, byte(i), byte(i >> 8), 0x55}, Duration: 20 * time.Millisecond})
+	}
+	file := filepath.Join(t.TempDir(), "audio.ogg")
+	if err := sfutest.WriteOpusFile(file, 2, packets); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := sfutest.NewServer(sfutest.Config{OpusFile: file, Loop: loop})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("http://127.0.0.1/html5client/", "ws://127.0.0.1/html5client/websocket", "http://127.0.0.1/pad/", "ws://127.0.0.1/pad/", server.APIURL, "secret", server.WebSocketURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetLogger(logger.Nop())
+	// as if the client joined the meeting
+	client.ExternalMeetingID = server.MeetingID()
+	client.InternalMeetingID = server.InternalMeetingID()
+	client.InternalUserID = "w_sfutest"
+	client.UserName = "bot"
+	client.SessionToken = "token"
+	client.sessionActive = true
+
+	audioClient := client.CreateAudioChannel()
+	audioClient.ConnectTimeout = 20 * time.Second
+	audioClient.ICE.IncludeLoopback = true
+	audioClient.ICE.RequireTURN = false
+	audioClient.ICE.NetworkTypes = []webrtc.NetworkType{webrtc.NetworkTypeUDP4}
+	t.Cleanup(func() { audioClient.Close() })
+
+	return server, audioClient, server.Packets()
+}
+
+// Test for ListenToAudio with a local sfu (the packets of the file are received in order)
+func TestListenToAudio(t *testing.T) {
+	server, audioClient, packets := newTestAudioClient(t, false)
+
+	collector := &packetCollector{mutex: new(sync.Mutex), payloads: [][]byte{}, received: make(chan struct{}, 1)}
+	receiver := audio.NewReceiver(nil, 48000, 2)
+	receiver.AddOpusSink(collector)
+	if err := audioClient.ReceiveAudio(receiver); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := audioClient.ListenToAudio(); err != nil {
+		t.Fatalf("ListenToAudio() FAILED: %v", err)
+	}
+	if status := audioClient.GetStatus(); status != CONNECTED {
+		t.Errorf("ListenToAudio() FAILED: status %s, expected %s", status, CONNECTED)
+	}
+
+	if !collector.wait(len(packets), 10*time.Second) {
+		t.Fatalf("ListenToAudio() FAILED: received %d of %d packets", collector.count(), len(packets))
+	}
+	audioClient.Close()
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	if len(collector.payloads) != len(packets) {
+		t.Errorf("ListenToAudio() FAILED: received %d packets, expected %d", len(collector.payloads), len(packets))
+	}
+	for i, payload := range collector.payloads {
+		if i < len(packets) && !bytes.Equal(payload, packets[i].Data) {
+			t.Errorf("ListenToAudio() FAILED: packet %d is %x, expected %x", i, payload, packets[i].Data)
+			break
+		}
+	}
+
+	messages := server.Messages()
+	if len(messages) < 2 || messages[0] != "start" || messages[1] != "subscriberAnswer" {
+		t.Errorf("ListenToAudio() FAILED: wrong signalling messages %v", messages)
+	}
+}
+
+// Test for the rejoin after the sfu closed the connection
+func TestAudioRejoin(t *testing.T) {
+	server, audioClient, _ := newTestAudioClient(t, true)
+	audioClient.RejoinDelay = 100 * time.Millisecond
+
+	collector := &packetCollector{mutex: new(sync.Mutex), payloads: [][]byte{}, received: make(chan struct{}, 1)}
+	receiver := audio.NewReceiver(nil, 48000, 2)
+	receiver.AddOpusSink(collector)
+	if err := audioClient.ReceiveAudio(receiver); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := audioClient.ListenToAudio(); err != nil {
+		t.Fatalf("AudioRejoin() FAILED: %v", err)
+	}
+	if !collector.wait(10, 10*time.Second) {
+		t.Fatalf("AudioRejoin() FAILED: no audio before the rejoin")
+	}
+
+	server.Disconnect()
+
+	received := collector.count()
+	deadline := time.Now().Add(20 * time.Second)
+	for server.Sessions() < 2 || collector.count() < received+20 {
+		if time.Now().After(deadline) {
+			t.Fatalf("AudioRejoin() FAILED: %d sessions, %d packets after the rejoin", server.Sessions(), collector.count()-received)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if status := audioClient.GetStatus(); status != CONNECTED {
+		t.Errorf("AudioRejoin() FAILED: status %s, expected %s", status, CONNECTED)
+	}
+}
+
+// Test for OnTrack (the status can be read while the audio is closed)
+func TestOnTrackStatus(t *testing.T) {
+	_, audioClient, _ := newTestAudioClient(t, true)
+
+	done := make(chan struct{})
+	audioClient.OnTrack(func(status func() StatusType, track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
+		go func() {
+			defer close(done)
+			buffer := make([]byte, 1500)
+			for status() != DISCONNECTED {
+				if _, _, err := track.Read(buffer); err != nil {
+					return
+				}
+			}
+		}()
+	})
+
+	if err := audioClient.ListenToAudio(); err != nil {
+		t.Fatalf("ListenToAudio() FAILED: %v", err)
+	}
+	audioClient.Close()
+
+	select {
+	case <-done:
+		t.Logf("OnTrack() PASSED")
+	case <-time.After(10 * time.Second):
+		t.Errorf("OnTrack() FAILED: the track was still read after Close")
+	}
+}
@@ -0,0 +1,196 @@
+package bbb
+
+import (
+	"strconv"
+)
+
+// For stream-annotations (event "added")
+// {"eventName":"added","args":[{"annotations":[{"id":"...","status":"DRAW_END","annotationType":"pencil","annotationInfo":{...},"wbId":"...","userId":"...","position":0}]}]}
+type Annotation struct {
+	ID             string                 `json:"id"`
+	Status         string                 `json:"status"`
+	AnnotationType string                 `json:"annotationType"`
+	AnnotationInfo map[string]interface{} `json:"annotationInfo"`
+	WbID           string                 `json:"wbId"`
+	UserID         string                 `json:"userId"`
+	Position       int                    `json:"position"`
+}
+
+type annotationsAddedMessage struct {
+	Annotations []Annotation `json:"annotations"`
+}
+
+// Converts a StreamMessage of the annotations stream into a list of annotations
+func ConvertInToAnnotations(msg StreamMessage) []Annotation {
+	var content annotationsAddedMessage
+	if err := msg.ConvertArg(&content); err != nil {
+		return []Annotation{}
+	}
+	return content.Annotations
+}
+
+// For stream-annotations (event "removed")
+// If ShapeID is empty, all annotations of the user (or of all users if UserID is empty) on the whiteboard were removed.
+// {"eventName":"removed","args":[{"meetingId":"...","whiteboardId":"...","userId":"...","shapeId":"..."}]}
+type AnnotationsRemoved struct {
+	MeetingID    string `json:"meetingId"`
+	WhiteboardID string `json:"whiteboardId"`
+	UserID       string `json:"userId"`
+	ShapeID      string `json:"shapeId"`
+}
+
+// Converts a StreamMessage of the annotations stream into an AnnotationsRemoved object
+func ConvertInToAnnotationsRemoved(msg StreamMessage) AnnotationsRemoved {
+	var content AnnotationsRemoved
+	msg.ConvertArg(&content)
+	return content
+}
+
+// Types of annotations (AnnotationType of Annotation)
+const (
+	AnnotationPencil     = "pencil"
+	AnnotationLine       = "line"
+	AnnotationRectangle  = "rectangle"
+	AnnotationEllipse    = "ellipse"
+	AnnotationTriangle   = "triangle"
+	AnnotationText       = "text"
+	AnnotationStickyNote = "sticky"
+	AnnotationPollResult = "poll_result"
+)
+
+// Status of an annotation (Status of Annotation). Annotations that are sent at once are finished.
+const (
+	AnnotationStatusDrawStart     = "DRAW_START"
+	AnnotationStatusDrawUpdate    = "DRAW_UPDATE"
+	AnnotationStatusDrawEnd       = "DRAW_END"
+	AnnotationStatusTextCreated   = "textCreated"
+	AnnotationStatusTextEdited    = "textEdited"
+	AnnotationStatusTextPublished = "textPublished"
+)
+
+// AnnotationInfo of the shapes pencil, line, rectangle, ellipse and triangle.
+// The points are in percent of the slide (x1, y1, x2, y2, ...). Lines, rectangles, ellipses and
+// triangles have two points (start and end), a pencil has all points of the drawn line.
+type ShapeInfo struct {
+	ID           string    `json:"id"`
+	WhiteboardID string    `json:"whiteboardId"`
+	Status       string    `json:"status"`
+	Type         string    `json:"type"`
+	Color        int       `json:"color"`     // 0xRRGGBB
+	Thickness    float64   `json:"thickness"` // in percent of the slide width
+	Points       []float64 `json:"points"`
+	Fill         bool      `json:"fill"`
+}
+
+// AnnotationInfo of a text. The position and size are in percent of the slide.
+type TextInfo struct {
+	ID             string  `json:"id"`
+	WhiteboardID   string  `json:"whiteboardId"`
+	Status         string  `json:"status"`
+	Type           string  `json:"type"`
+	Text           string  `json:"text"`
+	X              float64 `json:"x"`
+	Y              float64 `json:"y"`
+	TextBoxWidth   float64 `json:"textBoxWidth"`
+	TextBoxHeight  float64 `json:"textBoxHeight"`
+	FontColor      int     `json:"fontColor"` // 0xRRGGBB
+	FontSize       float64 `json:"fontSize"`
+	CalcedFontSize float64 `json:"calcedFontSize"` // in percent of the slide height
+	DataPoints     string  `json:"dataPoints"`     // "x,y"
+}
+
+// AnnotationInfo of a sticky note. The position and size are in percent of the slide.
+// Sticky notes are only shown by clients that know them (the tldraw whiteboard).
+type StickyNoteInfo struct {
+	ID           string  `json:"id"`
+	WhiteboardID string  `json:"whiteboardId"`
+	Status       string  `json:"status"`
+	Type         string  `json:"type"`
+	Text         string  `json:"text"`
+	X            float64 `json:"x"`
+	Y            float64 `json:"y"`
+	Width        float64 `json:"width"`
+	Height       float64 `json:"height"`
+	Color        int     `json:"color"` // 0xRRGGBB of the note
+}
+
+// Shape returns the AnnotationInfo of a pencil, line, rectangle, ellipse or triangle
+func (a Annotation) Shape() (ShapeInfo, error) {
+	var info ShapeInfo
+	err := convertInTo(a.AnnotationInfo, &info)
+	return info, err
+}
+
+// Text returns the AnnotationInfo of a text
+func (a Annotation) Text() (TextInfo, error) {
+	var info TextInfo
+	err := convertInTo(a.AnnotationInfo, &info)
+	return info, err
+}
+
+// StickyNote returns the AnnotationInfo of a sticky note
+func (a Annotation) StickyNote() (StickyNoteInfo, error) {
+	var info StickyNoteInfo
+	err := convertInTo(a.AnnotationInfo, &info)
+	return info, err
+}
+
+// Converts the info into the map of an Annotation
+func newAnnotation(annotationType string, status string, info interface{}) Annotation {
+	return Annotation{
+		Status:         status,
+		AnnotationType: annotationType,
+		AnnotationInfo: convertToMap(info),
+	}
+}
+
+// NewShapeAnnotation creates a pencil, line, rectangle, ellipse or triangle (see ShapeInfo for the points).
+// The ids are set when the annotation is drawn.
+func NewShapeAnnotation(shapeType string, color int, thickness float64, points []float64, fill bool) Annotation {
+	return newAnnotation(shapeType, AnnotationStatusDrawEnd, ShapeInfo{
+		Status:    AnnotationStatusDrawEnd,
+		Type:      shapeType,
+		Color:     color,
+		Thickness: thickness,
+		Points:    points,
+		Fill:      fill,
+	})
+}
+
+// NewTextAnnotation creates a text at x, y (in percent of the slide)
+func NewTextAnnotation(text string, x float64, y float64, width float64, height float64, fontColor int, fontSize float64) Annotation {
+	return newAnnotation(AnnotationText, AnnotationStatusTextPublished, TextInfo{
+		Status:         AnnotationStatusTextPublished,
+		Type:           AnnotationText,
+		Text:           text,
+		X:              x,
+		Y:              y,
+		TextBoxWidth:   width,
+		TextBoxHeight:  height,
+		FontColor:      fontColor,
+		FontSize:       fontSize,
+		CalcedFontSize: fontSize,
+		DataPoints:     strconv.FormatFloat(x, 'f', -1, 64) + "," + strconv.FormatFloat(y, 'f', -1, 64),
+	})
+}
+
+// NewStickyNoteAnnotation creates a sticky note at x, y (in percent of the slide)
+func NewStickyNoteAnnotation(text string, x float64, y float64, width float64, height float64, color int) Annotation {
+	return newAnnotation(AnnotationStickyNote, AnnotationStatusDrawEnd, StickyNoteInfo{
+		Status: AnnotationStatusDrawEnd,
+		Type:   AnnotationStickyNote,
+		Text:   text,
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+		Color:  color,
+	})
+}
+
+// For whiteboard-multi-user (the users that can draw on the whiteboard besides the presenter)
+type WhiteboardMultiUser struct {
+	MeetingId    string   `json:"meetingId"`
+	WhiteboardId string   `json:"whiteboardId"`
+	MultiUser    []string `json:"multiUser"`
+}
@@ -0,0 +1,42 @@
+package bbb
+
+// For breakouts
+type Breakout struct {
+	BreakoutId      string               `json:"breakoutId"`
+	ParentMeetingId string               `json:"parentMeetingId"`
+	Name            string               `json:"name"`
+	ShortName       string               `json:"shortName"`
+	Sequence        int                  `json:"sequence"`
+	IsDefaultName   bool                 `json:"isDefaultName"`
+	FreeJoin        bool                 `json:"freeJoin"`
+	TimeRemaining   int                  `json:"timeRemaining"`
+	JoinedUsers     []BreakoutJoinedUser `json:"joinedUsers"`
+	Users           []BreakoutUser       `json:"users"`
+}
+type BreakoutJoinedUser struct {
+	UserId string `json:"userId"`
+	Name   string `json:"name"`
+}
+type BreakoutUser struct {
+	UserId                 string `json:"userId"`
+	RedirectToHtml5JoinURL string `json:"redirectToHtml5JoinURL"`
+	InsertedTime           int64  `json:"insertedTime"`
+}
+
+// A breakout room for the createBreakoutRoom call
+type BreakoutRoomSend struct {
+	Users                 []string `json:"users"` // user ids of the users that are moved into the room
+	Name                  string   `json:"name"`
+	ShortName             string   `json:"shortName"`
+	IsDefaultName         bool     `json:"isDefaultName"`
+	FreeJoin              bool     `json:"freeJoin"`
+	Sequence              int      `json:"sequence"`
+	CaptureNotesFilename  string   `json:"captureNotesFilename"`
+	CaptureSlidesFilename string   `json:"captureSlidesFilename"`
+}
+
+// For the requestJoinURL call
+type BreakoutJoinURLRequest struct {
+	BreakoutId string `json:"breakoutId"`
+	UserId     string `json:"userId"`
+}
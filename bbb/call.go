@@ -3,7 +3,7 @@ package bbb
 type CallType int
 
 const (
-	StreamCursorCall CallType = iota //BECAREFUL: The word INTERNALID must be replaced with the internal meeting id!!!
+	StreamCursorCall CallType = iota //BECAREFUL: The word INTERNALID must be replaced with the internal meeting id!!! (The bot.Client does this by itself.)
 	VoidConnectionCall
 	StopUserTypingCall
 	SendGroupChatMsgCall
@@ -36,6 +36,21 @@ const (
 	RemovePresentationCall
 	UserLeftMeetingCall
 	SetExitReasonCall
+	PublishVoteCall
+	PublishTypedVoteCall
+	StartPollCall
+	StopPollCall
+	PublishPollCall
+	RequestJoinURLCall
+	RemoveUserCall
+	ChangeRoleCall
+	AssignPresenterCall
+	AllowPendingUsersCall
+	SetPresentationCall
+	RemoveGlobalAccessCall
+	ClearPublicChatHistoryCall
+	RequestPresentationUploadTokenCall
+	CreateGroupChatCall
 )
 
 func GetCall(callName CallType) (string) {
@@ -106,6 +121,36 @@ func GetCall(callName CallType) (string) {
 		return "userLeftMeeting"
 	case SetExitReasonCall:
 		return "setExitReason"
+	case PublishVoteCall:
+		return "publishVote"
+	case PublishTypedVoteCall:
+		return "publishTypedVote"
+	case StartPollCall:
+		return "startPoll"
+	case StopPollCall:
+		return "stopPoll"
+	case PublishPollCall:
+		return "publishPoll"
+	case RequestJoinURLCall:
+		return "requestJoinURL"
+	case RemoveUserCall:
+		return "removeUser"
+	case ChangeRoleCall:
+		return "changeRole"
+	case AssignPresenterCall:
+		return "assignPresenter"
+	case AllowPendingUsersCall:
+		return "allowPendingUsers"
+	case SetPresentationCall:
+		return "setPresentation"
+	case RemoveGlobalAccessCall:
+		return "removeGlobalAccess"
+	case ClearPublicChatHistoryCall:
+		return "clearPublicChatHistory"
+	case RequestPresentationUploadTokenCall:
+		return "requestPresentationUploadToken"
+	case CreateGroupChatCall:
+		return "createGroupChat"
 	default:
 		return ""
 	}
@@ -1,5 +1,12 @@
 package bbb
 
+// Ids and access of the chats
+const (
+	PublicChatID  = "MAIN-PUBLIC-GROUP-CHAT"
+	PublicAccess  = "PUBLIC_ACCESS"
+	PrivateAccess = "PRIVATE_ACCESS"
+)
+
 // For GoupChat and Private Chats
 type Chat struct {
 	ChatId       string             `json:"chatId"`
@@ -14,3 +21,18 @@ type ChatParticipants struct {
 	Name string `json:"name"`
 	Role string `json:"role"`
 }
+
+// HasParticipant returns true if the user (user id) is a participant of the chat
+func (chat Chat) HasParticipant(userID string) bool {
+	for _, participant := range chat.Participants {
+		if participant.ID == userID {
+			return true
+		}
+	}
+	for _, user := range chat.Users {
+		if user == userID {
+			return true
+		}
+	}
+	return false
+}
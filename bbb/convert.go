@@ -0,0 +1,206 @@
+package bbb
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	convert "github.com/benpate/convert"
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+)
+
+// Converts content (from ddp.Update) into out (pointer to a struct) like ConvertInToMessage does it:
+// every field gets the value with the name of its json tag, converted by benpate/convert.
+// Values that can not be converted into the type of the field result in the zero value.
+// Returns an error if out is not a pointer.
+func convertInTo(content interface{}, out interface{}) error {
+	value := reflect.ValueOf(out)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return errors.New("can not convert into " + value.Kind().String() + ": not a pointer")
+	}
+	convertValue(content, value.Elem())
+	return nil
+}
+
+// Sets value to content converted into the type of value. Structs, slices and maps are converted element by element.
+func convertValue(content interface{}, value reflect.Value) {
+	if content == nil {
+		value.Set(reflect.Zero(value.Type()))
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(convert.String(content))
+	case reflect.Bool:
+		value.SetBool(convert.Bool(content))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(convert.Int64(content))
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(convert.Float(content))
+	case reflect.Interface:
+		if reflect.TypeOf(content).AssignableTo(value.Type()) {
+			value.Set(reflect.ValueOf(content))
+		}
+	case reflect.Slice:
+		items := convert.SliceOfInterface(content)
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for i, item := range items {
+			convertValue(item, slice.Index(i))
+		}
+		value.Set(slice)
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return
+		}
+		items := mapOf(content)
+		m := reflect.MakeMapWithSize(value.Type(), len(items))
+		for key, item := range items {
+			element := reflect.New(value.Type().Elem()).Elem()
+			convertValue(item, element)
+			m.SetMapIndex(reflect.ValueOf(key).Convert(value.Type().Key()), element)
+		}
+		value.Set(m)
+	case reflect.Struct:
+		items := mapOf(content)
+		for i := 0; i < value.NumField(); i++ {
+			name := jsonName(value.Type().Field(i))
+			if item, found := items[name]; found && name != "" {
+				convertValue(item, value.Field(i))
+			}
+		}
+	}
+}
+
+// Converts a struct into a map with the json tags of the fields as keys (e.g. to send it)
+func convertToMap(content interface{}) map[string]interface{} {
+	value := reflect.ValueOf(content)
+	items := map[string]interface{}{}
+	if value.Kind() != reflect.Struct {
+		return items
+	}
+	for i := 0; i < value.NumField(); i++ {
+		if name := jsonName(value.Type().Field(i)); name != "" {
+			items[name] = value.Field(i).Interface()
+		}
+	}
+	return items
+}
+
+// Returns the name of the field in the json tag. Empty for unexported fields and fields without a name.
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// Returns content as map. ddp.Update is a map as well, but benpate/convert does not know its type.
+func mapOf(content interface{}) map[string]interface{} {
+	if update, ok := content.(ddp.Update); ok {
+		return update
+	}
+	return convert.MapOfInterface(content)
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a User object
+func ConvertInToUser(content ddp.Update) User {
+	var user User
+	convertInTo(content, &user)
+	return user
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a VoiceUser object
+func ConvertInToVoiceUser(content ddp.Update) VoiceUser {
+	var voiceUser VoiceUser
+	convertInTo(content, &voiceUser)
+	return voiceUser
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a Meeting object
+func ConvertInToMeeting(content ddp.Update) Meeting {
+	var meeting Meeting
+	convertInTo(content, &meeting)
+	return meeting
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a Chat object
+func ConvertInToChat(content ddp.Update) Chat {
+	var chat Chat
+	convertInTo(content, &chat)
+	return chat
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a Breakout object
+func ConvertInToBreakout(content ddp.Update) Breakout {
+	var breakout Breakout
+	convertInTo(content, &breakout)
+	return breakout
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a Presentation object
+func ConvertInToPresentation(content ddp.Update) Presentation {
+	var presentation Presentation
+	convertInTo(content, &presentation)
+	return presentation
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a PresentationUploadToken object
+func ConvertInToPresentationUploadToken(content ddp.Update) PresentationUploadToken {
+	var token PresentationUploadToken
+	convertInTo(content, &token)
+	return token
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a Slide object
+func ConvertInToSlide(content ddp.Update) Slide {
+	var slide Slide
+	convertInTo(content, &slide)
+	return slide
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a Poll object
+func ConvertInToPoll(content ddp.Update) Poll {
+	var poll Poll
+	convertInTo(content, &poll)
+	return poll
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a MeetingTimeRemaining object
+func ConvertInToMeetingTimeRemaining(content ddp.Update) MeetingTimeRemaining {
+	var timeRemaining MeetingTimeRemaining
+	convertInTo(content, &timeRemaining)
+	return timeRemaining
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a RecordMeeting object
+func ConvertInToRecordMeeting(content ddp.Update) RecordMeeting {
+	var recordMeeting RecordMeeting
+	convertInTo(content, &recordMeeting)
+	return recordMeeting
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a GuestUser object
+func ConvertInToGuestUser(content ddp.Update) GuestUser {
+	var guestUser GuestUser
+	convertInTo(content, &guestUser)
+	return guestUser
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a SlidePosition object
+func ConvertInToSlidePosition(content ddp.Update) SlidePosition {
+	var slidePosition SlidePosition
+	convertInTo(content, &slidePosition)
+	return slidePosition
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a WhiteboardMultiUser object
+func ConvertInToWhiteboardMultiUser(content ddp.Update) WhiteboardMultiUser {
+	var multiUser WhiteboardMultiUser
+	convertInTo(content, &multiUser)
+	return multiUser
+}
@@ -0,0 +1,100 @@
+package bbb
+
+import (
+	"reflect"
+	"testing"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+)
+
+// Test for ConvertInToUser and ConvertInToChat (values of the wrong type are converted or become the zero value)
+func TestConvertInTo(t *testing.T) {
+	tests := []struct {
+		content  ddp.Update
+		convert  func(ddp.Update) interface{}
+		expected interface{}
+	}{
+		{
+			content: ddp.Update{
+				"userId":        "w_1",
+				"name":          "Alice",
+				"presenter":     true,
+				"loginTime":     float64(1700000000000),
+				"responseDelay": 2.0,
+				"breakoutProps": map[string]interface{}{"isBreakoutUser": true, "parentId": "meeting"},
+			},
+			convert: func(doc ddp.Update) interface{} { return ConvertInToUser(doc) },
+			expected: User{
+				UserID:        "w_1",
+				Name:          "Alice",
+				Presenter:     true,
+				LoginTime:     1700000000000,
+				ResponseDelay: 2,
+				BreakoutProps: UserBreakoutProps{IsBreakoutUser: true, ParentID: "meeting"},
+			},
+		},
+		{
+			// wrong types: a number for a string, a string for a bool, an object for a string and null
+			content: ddp.Update{
+				"userId":    12,
+				"presenter": "true",
+				"name":      map[string]interface{}{"first": "Alice"},
+				"role":      nil,
+			},
+			convert:  func(doc ddp.Update) interface{} { return ConvertInToUser(doc) },
+			expected: User{UserID: "12", Presenter: true},
+		},
+		{
+			content: ddp.Update{
+				"chatId":       "MAIN-PUBLIC-GROUP-CHAT",
+				"participants": []interface{}{map[string]interface{}{"id": "w_1", "name": "Alice", "role": "MODERATOR"}},
+				"users":        []interface{}{"w_1", "w_2"},
+			},
+			convert: func(doc ddp.Update) interface{} { return ConvertInToChat(doc) },
+			expected: Chat{
+				ChatId:       "MAIN-PUBLIC-GROUP-CHAT",
+				Participants: []ChatParticipants{{ID: "w_1", Name: "Alice", Role: "MODERATOR"}},
+				Users:        []string{"w_1", "w_2"},
+			},
+		},
+	}
+
+	for num, test := range tests {
+		got := test.convert(test.content)
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("ConvertInTo() %d FAILED: got %+v, expected %+v", num, got, test.expected)
+		} else {
+			t.Logf("ConvertInTo() %d PASSED", num)
+		}
+	}
+}
+
+// Test for ConvertInToCursors and the AnnotationInfo of a new annotation
+func TestConvertArg(t *testing.T) {
+	cursors := ConvertInToCursors(StreamMessage{
+		EventName: "message",
+		Args: []interface{}{map[string]interface{}{
+			"meetingId": "meeting",
+			"cursors":   map[string]interface{}{"w_1": map[string]interface{}{"xPercent": 10.5, "yPercent": 20, "whiteboardId": "slide-1"}},
+		}},
+	})
+	expected := []Cursor{{UserID: "w_1", XPercent: 10.5, YPercent: 20, WhiteboardID: "slide-1"}}
+	if !reflect.DeepEqual(cursors, expected) {
+		t.Errorf("ConvertInToCursors() FAILED: got %+v, expected %+v", cursors, expected)
+	} else {
+		t.Logf("ConvertInToCursors() PASSED")
+	}
+
+	annotation := NewShapeAnnotation(AnnotationLine, 0xff0000, 1.5, []float64{1, 2, 3, 4}, false)
+	shape, err := annotation.Shape()
+	if err != nil || shape.Color != 0xff0000 || shape.Thickness != 1.5 || !reflect.DeepEqual(shape.Points, []float64{1, 2, 3, 4}) {
+		t.Errorf("Annotation.Shape() FAILED: got %+v (%v), expected color 0xff0000, thickness 1.5 and 4 points", shape, err)
+	} else {
+		t.Logf("Annotation.Shape() PASSED")
+	}
+
+	var notAPointer Cursor
+	if err := convertInTo(map[string]interface{}{}, notAPointer); err == nil {
+		t.Errorf("convertInTo() FAILED: no error for a struct that is not a pointer")
+	}
+}
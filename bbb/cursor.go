@@ -0,0 +1,37 @@
+package bbb
+
+// For stream-cursor
+// {"eventName":"message","args":[{"meetingId":"...","cursors":{"w_xyz":{"xPercent":10.5,"yPercent":20,"whiteboardId":"..."}}}]}
+type Cursor struct {
+	UserID       string  `json:"userId"`
+	XPercent     float64 `json:"xPercent"`
+	YPercent     float64 `json:"yPercent"`
+	WhiteboardID string  `json:"whiteboardId"`
+}
+
+type cursorMessage struct {
+	MeetingID string            `json:"meetingId"`
+	Cursors   map[string]Cursor `json:"cursors"`
+}
+
+// Converts a StreamMessage of the cursor stream into a list of cursors
+func ConvertInToCursors(msg StreamMessage) []Cursor {
+	var content cursorMessage
+	if err := msg.ConvertArg(&content); err != nil {
+		return []Cursor{}
+	}
+
+	cursors := make([]Cursor, 0, len(content.Cursors))
+	for userID, cursor := range content.Cursors {
+		cursor.UserID = userID
+		cursors = append(cursors, cursor)
+	}
+	return cursors
+}
+
+// Send this to the cursor stream to move the cursor of the bot
+type CursorSend struct {
+	XPercent     float64 `json:"xPercent"`
+	YPercent     float64 `json:"yPercent"`
+	WhiteboardID string  `json:"whiteboardId"`
+}
@@ -0,0 +1,26 @@
+package bbb
+
+// Status of a guest (guestStatus of the join response and GuestStatus of User)
+type GuestStatus string
+
+const (
+	GuestStatusAllow GuestStatus = "ALLOW"
+	GuestStatusWait  GuestStatus = "WAIT"
+	GuestStatusDeny  GuestStatus = "DENY"
+)
+
+// For guest-user (the guests in the lobby)
+type GuestUser struct {
+	MeetingId                string `json:"meetingId"`
+	IntId                    string `json:"intId"`
+	Name                     string `json:"name"`
+	Role                     string `json:"role"`
+	Guest                    bool   `json:"guest"`
+	Authenticated            bool   `json:"authenticated"`
+	Approved                 bool   `json:"approved"`
+	Denied                   bool   `json:"denied"`
+	Avatar                   string `json:"avatar"`
+	Color                    string `json:"color"`
+	LoginTime                int64  `json:"loginTime"`
+	PrivateGuestLobbyMessage string `json:"privateGuestLobbyMessage"`
+}
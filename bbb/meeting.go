@@ -86,3 +86,28 @@ type MeetingWelcomeProp struct {
 	WelcomeMsg         string `json:"welcomeMsg"`
 	WelcomeMsgTemplate string `json:"welcomeMsgTemplate"`
 }
+
+// For meeting-time-remaining
+type MeetingTimeRemaining struct {
+	MeetingId     string `json:"meetingId"`
+	TimeRemaining int    `json:"timeRemaining"` // in seconds (0 if the meeting has no duration)
+}
+
+// For record-meetings
+type RecordMeeting struct {
+	MeetingId               string `json:"meetingId"`
+	Record                  bool   `json:"record"`
+	Recording               bool   `json:"recording"`
+	Time                    int64  `json:"time"`
+	AllowStartStopRecording bool   `json:"allowStartStopRecording"`
+	AutoStartRecording      bool   `json:"autoStartRecording"`
+}
+
+// Guest policies of a meeting (GuestPolicy of MeetingUsersProp)
+type GuestPolicy string
+
+const (
+	GuestPolicyAlwaysAccept GuestPolicy = "ALWAYS_ACCEPT"
+	GuestPolicyAlwaysDeny   GuestPolicy = "ALWAYS_DENY"
+	GuestPolicyAskModerator GuestPolicy = "ASK_MODERATOR"
+)
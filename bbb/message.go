@@ -1,7 +1,9 @@
 package bbb
 
 import (
-	ddp "github.com/gopackage/ddp"
+	"strings"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
 	convert "github.com/benpate/convert"
 )
 
@@ -35,6 +37,19 @@ func ConvertInToMessage(content ddp.Update) Message {
 	}
 }
 
+// Sender of the messages the server sends (e.g. poll results or "chat was cleared")
+const SystemMessageSender = "SYSTEM_MESSAGE"
+
+// IsSystem returns true if the message was sent by the server and not by a user
+func (m Message) IsSystem() bool {
+	return m.Sender == SystemMessageSender || strings.HasPrefix(m.ID, SystemMessageSender)
+}
+
+// IsPublic returns true if the message was sent to the public chat
+func (m Message) IsPublic() bool {
+	return m.ChatId == PublicChatID
+}
+
 type MessageSend struct {
 	ID                 string            `json:"correlationId"`
 	Sender             MessageSendSender `json:"sender"`
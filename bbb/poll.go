@@ -0,0 +1,53 @@
+package bbb
+
+// For polls and current-poll
+type Poll struct {
+	ID                 string       `json:"id"`
+	MeetingId          string       `json:"meetingId"`
+	Requester          string       `json:"requester"`
+	Question           string       `json:"question"`
+	PollType           string       `json:"pollType"`
+	SecretPoll         bool         `json:"secretPoll"`
+	IsMultipleResponse bool         `json:"isMultipleResponse"`
+	Answers            []PollAnswer `json:"answers"`
+	Users              []string     `json:"users"`          // users who have not answered yet
+	NumRespondents     int          `json:"numRespondents"` // only in current-poll
+	NumResponders      int          `json:"numResponders"`  // only in current-poll
+}
+type PollAnswer struct {
+	ID       int    `json:"id"`
+	Key      string `json:"key"`
+	NumVotes int    `json:"numVotes"` // only in current-poll
+}
+
+// Types of polls (pollType of Poll)
+type PollType string
+
+const (
+	PollYesNo           PollType = "YN"
+	PollYesNoAbstention PollType = "YNA"
+	PollTrueFalse       PollType = "TF"
+	PollLetter          PollType = "A-"
+	PollA2              PollType = "A-2"
+	PollA3              PollType = "A-3"
+	PollA4              PollType = "A-4"
+	PollA5              PollType = "A-5"
+	PollCustom          PollType = "CUSTOM"
+	PollResponse        PollType = "R-" // the users answer with a text
+)
+
+// Returns all poll types as the HTML5 client sends them to the startPoll call
+func GetPollTypes() map[string]PollType {
+	return map[string]PollType{
+		"YesNo":           PollYesNo,
+		"YesNoAbstention": PollYesNoAbstention,
+		"TrueFalse":       PollTrueFalse,
+		"Letter":          PollLetter,
+		"A2":              PollA2,
+		"A3":              PollA3,
+		"A4":              PollA4,
+		"A5":              PollA5,
+		"Custom":          PollCustom,
+		"Response":        PollResponse,
+	}
+}
@@ -0,0 +1,65 @@
+package bbb
+
+// For presentations
+type Presentation struct {
+	ID           string                 `json:"id"`
+	MeetingId    string                 `json:"meetingId"`
+	PodId        string                 `json:"podId"`
+	Name         string                 `json:"name"`
+	Current      bool                   `json:"current"`
+	Downloadable bool                   `json:"downloadable"`
+	Removable    bool                   `json:"removable"`
+	Conversion   PresentationConversion `json:"conversion"`
+}
+type PresentationConversion struct {
+	Done           bool   `json:"done"`
+	Error          bool   `json:"error"`
+	Status         string `json:"status"`
+	NumPages       int    `json:"numPages"`
+	PagesCompleted int    `json:"pagesCompleted"`
+}
+
+// For slides
+type Slide struct {
+	ID             string `json:"id"`
+	MeetingId      string `json:"meetingId"`
+	PodId          string `json:"podId"`
+	PresentationId string `json:"presentationId"`
+	Num            int    `json:"num"`
+	Current        bool   `json:"current"`
+	Content        string `json:"content"` // text of the slide
+	ImageUri       string `json:"imageUri"`
+	SvgUri         string `json:"svgUri"`
+	ThumbUri       string `json:"thumbUri"`
+	TxtUri         string `json:"txtUri"`
+}
+
+// For slide-positions (the visible part of a slide)
+type SlidePosition struct {
+	ID             string  `json:"id"`
+	MeetingId      string  `json:"meetingId"`
+	PodId          string  `json:"podId"`
+	PresentationId string  `json:"presentationId"`
+	Width          float64 `json:"width"`
+	Height         float64 `json:"height"`
+	X              float64 `json:"x"`
+	Y              float64 `json:"y"`
+	ViewBoxWidth   float64 `json:"viewBoxWidth"`
+	ViewBoxHeight  float64 `json:"viewBoxHeight"`
+}
+
+// For presentation-upload-token (the token to upload a presentation)
+type PresentationUploadToken struct {
+	ID                      string `json:"id"`
+	MeetingId               string `json:"meetingId"`
+	PodId                   string `json:"podId"`
+	UserId                  string `json:"userId"`
+	Filename                string `json:"filename"`
+	TemporaryPresentationId string `json:"temporaryPresentationId"`
+	AuthzToken              string `json:"authzToken"`
+	Failed                  bool   `json:"failed"`
+	Used                    bool   `json:"used"`
+}
+
+// The pod of the presentation area. The HTML5 client only uses this pod.
+const DefaultPresentationPod = "DEFAULT_PRESENTATION_POD"
@@ -0,0 +1,33 @@
+package bbb
+
+import (
+	convert "github.com/benpate/convert"
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+)
+
+// Message of a Meteor streamer (stream-cursor-..., stream-annotations-...)
+// {"msg":"changed","collection":"stream-cursor-INTERNALID","id":"id","fields":{"eventName":"message","args":[{...}]}}
+type StreamMessage struct {
+	EventName string        `json:"eventName"`
+	Args      []interface{} `json:"args"`
+}
+
+// Converts a map[string]interface{} (from ddp.Update) into a StreamMessage object
+func ConvertInToStreamMessage(content ddp.Update) StreamMessage {
+	msg := StreamMessage{
+		EventName: convert.String(content["eventName"]),
+		Args:      []interface{}{},
+	}
+	if args, ok := content["args"].([]interface{}); ok {
+		msg.Args = args
+	}
+	return msg
+}
+
+// Converts the first argument of the stream message into out (pointer to a struct)
+func (msg StreamMessage) ConvertArg(out interface{}) error {
+	if len(msg.Args) == 0 {
+		return nil
+	}
+	return convertInTo(msg.Args[0], out)
+}
@@ -37,6 +37,8 @@ const (
 	GroupChatMsgSub
 	CurrentPollSub
 	CurrentUser
+	MeetingsSub
+	PresentationUploadTokenSub
 )
 
 type streamsettings struct {
@@ -46,6 +48,8 @@ type streamsettings struct {
 
 // Returns the sub and all parameters
 // IMPORTENT: If the name conatins the word `INTERNALID`, it MUST be replaced with the internal meeting id!!!
+// (The bot.Client does this by itself.)
+// The stream subs are Meteor streamer subscriptions. Their first parameter is the event name of the stream.
 func GetSub(SubName SubType) (string, []interface{}) {
 	st := streamsettings{
 		UseCollection: false,
@@ -110,17 +114,21 @@ func GetSub(SubName SubType) (string, []interface{}) {
 	case UsersPersistentDataSub:
 		return "users-persistent-data", []interface{}{}
 	case StreamCursorSub:
-		return "stream-cursor-INTERNALID", []interface{}{st} //BECAREFUL: The word INTERNALID must be replaced with the internal meeting id!!!
+		return "stream-cursor-INTERNALID", []interface{}{"message", st} //BECAREFUL: The word INTERNALID must be replaced with the internal meeting id!!!
 	case StreamAnnotationsAddedSub:
-		return "stream-annotations-INTERNALID", []interface{}{st} //BECAREFUL: The word INTERNALID must be replaced with the internal meeting id!!!
+		return "stream-annotations-INTERNALID", []interface{}{"added", st} //BECAREFUL: The word INTERNALID must be replaced with the internal meeting id!!!
 	case StreamAnnotationsRemovedSub:
-		return "stream-annotations-INTERNALID", []interface{}{st} //BECAREFUL: The word INTERNALID must be replaced with the internal meeting id!!!
+		return "stream-annotations-INTERNALID", []interface{}{"removed", st} //BECAREFUL: The word INTERNALID must be replaced with the internal meeting id!!!
 	case GroupChatMsgSub:
 		return "group-chat-msg", []interface{}{0}
 	case CurrentPollSub:
 		return "current-poll", []interface{}{false, true}
 	case CurrentUser:
 		return "current-user", []interface{}{}
+	case MeetingsSub:
+		return "meetings", []interface{}{}
+	case PresentationUploadTokenSub:
+		return "presentation-upload-token", []interface{}{} // args are podId, filename and temporaryPresentationId of the upload
 	default:
 		return "", []interface{}{}
 	}
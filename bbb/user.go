@@ -1,5 +1,11 @@
 package bbb
 
+// Roles of a user (Role of User)
+const (
+	RoleModerator = "MODERATOR"
+	RoleViewer    = "VIEWER"
+)
+
 // For users and current-user
 type User struct {
 	MeetingID               string            `json:"meetingId"`
@@ -16,6 +22,9 @@ type User struct {
 	BreakoutProps           UserBreakoutProps `json:"breakoutProps"`
 	Color                   string            `json:"color"`
 	EffectiveConnectionType interface{}       `json:"effectiveConnectionType"`
+	Ejected                 bool              `json:"ejected"`
+	EjectedReason           string            `json:"ejectedReason"`
+	EjectedReasonCode       string            `json:"ejectedReasonCode"`
 	Emoji                   string            `json:"emoji"`
 	ExtID                   string            `json:"extId"`
 	Guest                   bool              `json:"guest"`
@@ -27,6 +36,7 @@ type User struct {
 	Name                    string            `json:"name"`
 	Pin                     bool              `json:"pin"`
 	Presenter               bool              `json:"presenter"`
+	RaiseHand               bool              `json:"raiseHand"`
 	ResponseDelay           int               `json:"responseDelay"`
 	Role                    string            `json:"role"`
 	SortName                string            `json:"sortName"`
@@ -8,11 +8,13 @@ import (
 
 	api "github.com/bigbluebutton-bot/bigbluebutton-bot/api"
 
-	ddp "github.com/gopackage/ddp"
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
 
 	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
 
 	pad "github.com/bigbluebutton-bot/bigbluebutton-bot/pad"
+
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
 )
 
 type StatusType string
@@ -25,11 +27,20 @@ const (
 	RECONNECTING  StatusType = "reconnecting"
 )
 
+// Events that do not depend on a subscription. They can be added before Join and are kept after Leave.
+var persistentEvents = map[string]bool{
+	"OnStatus":       true,
+	"OnMeetingEnded": true,
+	"OnEjected":      true,
+	"OnGuestStatus":  true,
+}
+
 // Client represents a BigBlueButton client connection. The BigBlueButton client establish a BigBlueButton
 // session and acts as a message pump for other tools.
 type Client struct {
-	// Status is the current connection status of the client
-	Status StatusType
+	// current connection status of the client (see GetStatus). Guarded by statusMutex.
+	statusMutex *sync.Mutex
+	status      StatusType
 
 	// BBB-urls the client is connected to
 	ClientURL   string
@@ -40,12 +51,33 @@ type Client struct {
 	// to make api requests to the BBB-server
 	API *api.ApiRequest
 
+	// how long Join waits in the guest lobby for the approval of a moderator
+	GuestWaitTimeout time.Duration
+
+	// all log messages are written to the logger (see SetLogger)
+	logger logger.Logger
+
 	ddpClient *ddp.Client
 
-	// events will store all the functions executed on certain events. (events["OnStatus"][]func(StatusType))
-	events          map[string][]interface{}
+	// events will store all the functions executed on certain events. (events["OnStatus"] with func(StatusType))
+	// It is guarded by eventsMutex (see addListener, removeListener and getListeners).
+	eventsMutex    *sync.Mutex
+	events         map[string][]eventListener
+	lastListenerID uint64 // id of the last listener added by addListener
+	// guards the subscription of the first listener of an event (see addSubscribedListener)
+	listenMutex     *sync.Mutex
 	ddpEventHandler *ddpEventHandler
 
+	// all active ddp subscriptions
+	subMutex      *sync.Mutex
+	subscriptions map[bbb.SubType]*ddpSubscription
+
+	// true between Join and Leave. The session is resumed if the ddpClient reconnects.
+	// Both are guarded by meetingEventsMutex.
+	sessionActive bool
+	// ddp session the auth token was validated for
+	ddpSession string
+
 	// after join there are the following informations
 	JoinURL           string
 	SessionCookie     []*http.Cookie
@@ -59,6 +91,42 @@ type Client struct {
 	// Pads (Captures and shared notes)
 	padMutex *sync.Mutex
 	captures []*pad.Pad
+
+	// typed state of the meeting (see GetMeetingState)
+	stateMutex   *sync.Mutex
+	meetingState *MeetingState
+
+	// last known state of the users for OnUserJoined, OnUserLeft and OnUserChanged (userEvents[ddp id])
+	userEventsMutex *sync.Mutex
+	userEvents      map[string]bbb.User
+
+	// voice users and talk times for OnTalkingStarted, OnTalkingStopped and OnMuteChanged
+	voiceMutex    *sync.Mutex
+	voiceActivity *voiceActivity
+
+	// the ddp id of the meeting document
+	meetingEventsMutex *sync.Mutex
+	meetingDocID       string
+
+	// last known recording status for OnRecordingStatusChanged (recordingStatus[ddp id])
+	recordingMutex  *sync.Mutex
+	recordingStatus map[string]bbb.RecordMeeting
+
+	// last poll and published state for the poll events
+	pollMutex *sync.Mutex
+	polls     *polls
+
+	// last known state of the breakout rooms for the breakout events (breakouts[ddp id])
+	breakoutMutex *sync.Mutex
+	breakouts     map[string]bbb.Breakout
+
+	// current state of the slides for OnSlideChanged (currentSlides[ddp id])
+	presentationMutex *sync.Mutex
+	currentSlides     map[string]bool
+
+	// connected audio clients (closed when the session ends)
+	audioMutex   *sync.Mutex
+	audioClients []*AudioClient
 }
 
 func NewClient(clientURL string, clientWSURL string, padURL string, padWSURL string, apiURL string, apiSecret string, webRTCWSURL string) (*Client, error) {
@@ -70,7 +138,8 @@ func NewClient(clientURL string, clientWSURL string, padURL string, padWSURL str
 	ddpClient := ddp.NewClient(clientWSURL, clientURL)
 
 	c := &Client{
-		Status: DISCONNECTED,
+		statusMutex: new(sync.Mutex),
+		status:      DISCONNECTED,
 
 		ClientURL:   clientURL,
 		ClientWSURL: clientWSURL,
@@ -82,33 +151,102 @@ func NewClient(clientURL string, clientWSURL string, padURL string, padWSURL str
 
 		API: api,
 
-		events:          make(map[string][]interface{}),
+		GuestWaitTimeout: time.Minute * 10,
+
+		logger: logger.Default(),
+
+		eventsMutex:     new(sync.Mutex),
+		events:          make(map[string][]eventListener),
+		listenMutex:     new(sync.Mutex),
 		ddpEventHandler: nil,
 
+		subMutex:      new(sync.Mutex),
+		subscriptions: make(map[bbb.SubType]*ddpSubscription),
+
 		padMutex: new(sync.Mutex),
 		captures: make([]*pad.Pad, 0),
+
+		stateMutex:   new(sync.Mutex),
+		meetingState: nil,
+
+		userEventsMutex: new(sync.Mutex),
+		userEvents:      nil,
+
+		voiceMutex:    new(sync.Mutex),
+		voiceActivity: nil,
+
+		meetingEventsMutex: new(sync.Mutex),
+		meetingDocID:       "",
+
+		recordingMutex:  new(sync.Mutex),
+		recordingStatus: nil,
+
+		pollMutex: new(sync.Mutex),
+		polls:     nil,
+
+		breakoutMutex: new(sync.Mutex),
+		breakouts:     nil,
+
+		presentationMutex: new(sync.Mutex),
+		currentSlides:     nil,
+
+		audioMutex:   new(sync.Mutex),
+		audioClients: []*AudioClient{},
 	}
 
 	c.ddpEventHandler = &ddpEventHandler{
-		client:  c,
-		updater: make(map[string][]updaterfunc),
+		client:     c,
+		updater:    make(map[string][]ddpUpdater),
+		listening:  make(map[string]bool),
+		queueMutex: new(sync.Mutex),
+		queues:     make(map[string]*ddpQueue),
+		known:      make(map[string]map[string]bool),
+		docMutex:   new(sync.Mutex),
+		resyncing:  make(map[string]map[string]bool),
 	}
 
+	ddpClient.AddStatusListener(c.ddpEventHandler)
+	ddpClient.AddConnectionListener(c.ddpEventHandler)
+
 	return c, nil
 }
 
+// SetLogger sets the logger for the client and everything created by it (captures, audio).
+// *slog.Logger can be used. Tokens and other secrets are redacted before they are logged.
+func (c *Client) SetLogger(l logger.Logger) {
+	if l == nil {
+		l = logger.Nop()
+	}
+	c.logger = l
+	c.API.SetLogger(l)
+}
+
+// Returns the logger with the fields of the client
+func (c *Client) log() logger.Logger {
+	return logger.With(c.logger, "meeting", c.ExternalMeetingID, "user", c.InternalUserID)
+}
+
 // Join a meeting
 func (c *Client) Join(meetingID string, userName string, moderator bool) error {
-	if c.Status != DISCONNECTED {
+	if c.GetStatus() != DISCONNECTED {
 		c.Leave()
 	}
 
-	c.Status = CONNECTING
+	c.updateStatus(CONNECTING)
 
-	joinURL, coockie, internalUserID, authToken, sessionToken, internalMeetingID, err := c.API.Join(meetingID, userName, moderator)
+	joinURL, coockie, internalUserID, authToken, sessionToken, internalMeetingID, guestStatus, err := c.API.JoinWithGuestStatus(meetingID, userName, moderator)
 	if err != nil {
+		c.updateStatus(DISCONNECTED)
 		return err
 	}
+
+	// If the meeting asks a moderator, the bot has to wait in the guest lobby
+	if guestStatus != "" && bbb.GuestStatus(guestStatus) != bbb.GuestStatusAllow {
+		if err = c.waitInGuestLobby(bbb.GuestStatus(guestStatus), sessionToken); err != nil {
+			c.updateStatus(DISCONNECTED)
+			return err
+		}
+	}
 	c.JoinURL = joinURL
 	c.SessionCookie = coockie
 	c.InternalUserID = internalUserID
@@ -118,39 +256,80 @@ func (c *Client) Join(meetingID string, userName string, moderator bool) error {
 	c.ExternalMeetingID = meetingID
 	c.InternalMeetingID = internalMeetingID
 
+	return c.connect()
+}
+
+// Join a meeting with a join url the server created for the bot (e.g. of a breakout room, see JoinBreakout)
+func (c *Client) joinWithURL(joinURL string) error {
+	if c.GetStatus() != DISCONNECTED {
+		c.Leave()
+	}
+
+	c.updateStatus(CONNECTING)
+
+	coockie, internalUserID, authToken, sessionToken, internalMeetingID, externalMeetingID, userName, err := c.API.Enter(joinURL)
+	if err != nil {
+		c.updateStatus(DISCONNECTED)
+		return err
+	}
+	c.JoinURL = joinURL
+	c.SessionCookie = coockie
+	c.InternalUserID = internalUserID
+	c.UserName = userName
+	c.AuthToken = authToken
+	c.SessionToken = sessionToken
+	c.ExternalMeetingID = externalMeetingID
+	c.InternalMeetingID = internalMeetingID
+
+	return c.connect()
+}
+
+// Connect to the DDP server and validate the auth token of the joined user
+func (c *Client) connect() error {
 	// Connect to the DDP server
-	if err = c.ddpConnect(); err != nil {
-		c.Status = DISCONNECTED
+	if err := c.ddpConnect(); err != nil {
+		c.updateStatus(DISCONNECTED)
 		return err
 	}
 
 	// Subscribe to the current user
-	if err = c.ddpSubscribe(bbb.CurrentUser, nil); err != nil {
-		c.Status = DISCONNECTED
+	if _, err := c.ddpSubscribe(bbb.CurrentUser, nil); err != nil {
+		c.updateStatus(DISCONNECTED)
 		return err
 	}
 
 	// Call the validateAuthToken method with the userID, authToken, and userName
-	_, err = c.ddpCall(bbb.ValidateAuthTokenCall, internalMeetingID, internalUserID, authToken, internalUserID)
+	_, err := c.ddpCall(bbb.ValidateAuthTokenCall, c.InternalMeetingID, c.InternalUserID, c.AuthToken, c.InternalUserID)
 	if err != nil {
-		c.Status = DISCONNECTED
+		c.updateStatus(DISCONNECTED)
 		return errors.New("could not validateAuthToken")
 	}
 
-	c.Status = CONNECTED
+	// Know when the meeting ends or the bot is removed
+	if err = c.watchSession(); err != nil {
+		c.log().Warn("meeting end and ejection will not be detected", "error", err)
+	}
+
+	c.meetingEventsMutex.Lock()
+	c.ddpSession = c.ddpClient.Session()
+	c.sessionActive = true
+	c.meetingEventsMutex.Unlock()
+	c.updateStatus(CONNECTED)
 
 	return nil
 }
 
-// Leave the joined meeting
+// Leave the joined meeting.
+// The listeners of all events except OnStatus, OnMeetingEnded, OnEjected and OnGuestStatus are removed,
+// because their subscriptions end with the meeting. They have to be added again after the next Join.
 func (c *Client) Leave() error {
 	// If not connected, return an error
-	if c.Status != CONNECTED {
+	if c.GetStatus() != CONNECTED {
 		// If is connecting retry 5 times
-		if c.Status == CONNECTING {
+		if c.GetStatus() == CONNECTING {
 			i := 0
 			for i < 5 {
-				if c.Status == CONNECTED {
+				if c.GetStatus() == CONNECTED {
 					c.Leave()
 				}
 				time.Sleep(time.Second * 1)
@@ -160,13 +339,169 @@ func (c *Client) Leave() error {
 		return errors.New("Client is in no meeting. First Join a meeting with: client.Join(meetingID string, userName string, moderator bool)")
 	}
 
+	// The session may have been ended meanwhile by the end of the meeting
+	if !c.endSession() {
+		return errors.New("Client is in no meeting. First Join a meeting with: client.Join(meetingID string, userName string, moderator bool)")
+	}
+
 	c.ddpCall(bbb.UserLeftMeetingCall)
 	c.ddpCall(bbb.SetExitReasonCall, "logout")
-	// c.ddpClient.UnSubscribe("from all subs")
+
+	c.resetSession()
 
 	c.ddpDisconnect()
 
-	c.Status = DISCONNECTED
+	c.updateStatus(DISCONNECTED)
+
+	return nil
+}
+
+// GetStatus returns the current connection status of the client
+func (c *Client) GetStatus() StatusType {
+	c.statusMutex.Lock()
+	defer c.statusMutex.Unlock()
+	return c.status
+}
+
+// Mark the session as ended. Returns false if it was not active, e.g. because it was already
+// ended by Leave or by another event. Only the caller that gets true may close the session.
+func (c *Client) endSession() bool {
+	c.meetingEventsMutex.Lock()
+	defer c.meetingEventsMutex.Unlock()
+	if !c.sessionActive {
+		return false
+	}
+	c.sessionActive = false
+	return true
+}
+
+// Close the session without leaving the meeting, because the meeting has ended or the bot was removed.
+// It has to be ended with endSession before.
+func (c *Client) closeSession() {
+	c.resetSession()
+
+	c.ddpDisconnect()
+
+	c.updateStatus(DISCONNECTED)
+}
+
+// A listener added by addListener. The id is used to remove it again.
+type eventListener struct {
+	id uint64
+	f  interface{}
+}
+
+// Add the listener to the event. The returned id can be used to remove it with removeListener.
+func (c *Client) addListener(event string, listener interface{}) uint64 {
+	c.eventsMutex.Lock()
+	defer c.eventsMutex.Unlock()
+	c.lastListenerID++
+	c.events[event] = append(c.events[event], eventListener{id: c.lastListenerID, f: listener})
+	return c.lastListenerID
+}
+
+// Remove the listener with the id from the event
+func (c *Client) removeListener(event string, id uint64) {
+	c.eventsMutex.Lock()
+	defer c.eventsMutex.Unlock()
+	listeners := c.events[event]
+	for i, listener := range listeners {
+		if listener.id == id {
+			c.events[event] = append(listeners[:i:i], listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// Returns a copy of the listeners of the event, so they can be called while listeners are added
+func (c *Client) getListeners(event string) []interface{} {
+	c.eventsMutex.Lock()
+	defer c.eventsMutex.Unlock()
+	listeners := make([]interface{}, 0, len(c.events[event]))
+	for _, listener := range c.events[event] {
+		listeners = append(listeners, listener.f)
+	}
+	return listeners
+}
+
+// Returns true if the event has at least one listener
+func (c *Client) hasListeners(event string) bool {
+	c.eventsMutex.Lock()
+	defer c.eventsMutex.Unlock()
+	return len(c.events[event]) > 0
+}
+
+// Add the listener to the event. If it is the first listener of the event, subscribe is called before
+// to subscribe to the collections of the event. Both is done with listenMutex locked,
+// so the collections are only subscribed once if listeners are added at the same time.
+func (c *Client) addSubscribedListener(event string, listener interface{}, subscribe func() error) error {
+	c.listenMutex.Lock()
+	defer c.listenMutex.Unlock()
+
+	if !c.hasListeners(event) {
+		if err := subscribe(); err != nil {
+			return err
+		}
+	}
+
+	c.addListener(event, listener)
 
 	return nil
 }
+
+// Returns true while the client is in a meeting (between Join and Leave)
+func (c *Client) isSessionActive() bool {
+	c.meetingEventsMutex.Lock()
+	defer c.meetingEventsMutex.Unlock()
+	return c.sessionActive
+}
+
+// Remove everything that belongs to the meeting
+func (c *Client) resetSession() {
+	// Unsubscribe from all collections. The listeners (except the persistentEvents) depend on them,
+	// so they are removed as well and have to be added again after the next Join.
+	c.listenMutex.Lock()
+	c.ddpUnsubscribeAll()
+	c.eventsMutex.Lock()
+	for event := range c.events {
+		if !persistentEvents[event] {
+			delete(c.events, event)
+		}
+	}
+	c.eventsMutex.Unlock()
+	c.listenMutex.Unlock()
+	c.stateMutex.Lock()
+	c.meetingState = nil
+	c.stateMutex.Unlock()
+	c.userEventsMutex.Lock()
+	c.userEvents = nil
+	c.userEventsMutex.Unlock()
+	c.voiceMutex.Lock()
+	c.voiceActivity = nil
+	c.voiceMutex.Unlock()
+	c.meetingEventsMutex.Lock()
+	c.meetingDocID = ""
+	c.meetingEventsMutex.Unlock()
+	c.recordingMutex.Lock()
+	c.recordingStatus = nil
+	c.recordingMutex.Unlock()
+	c.pollMutex.Lock()
+	c.polls = nil
+	c.pollMutex.Unlock()
+	c.breakoutMutex.Lock()
+	c.breakouts = nil
+	c.breakoutMutex.Unlock()
+	c.presentationMutex.Lock()
+	c.currentSlides = nil
+	c.presentationMutex.Unlock()
+
+	c.audioMutex.Lock()
+	audioClients := c.audioClients
+	c.audioClients = []*AudioClient{}
+	c.audioMutex.Unlock()
+	for _, audioClient := range audioClients {
+		if err := audioClient.Close(); err != nil {
+			c.log().Warn("could not close audio", "error", err)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package ddp
+
+import "sync"
+
+// ----------------------------------------------------------------------
+// Collection
+// ----------------------------------------------------------------------
+
+type Update map[string]interface{}
+
+// UpdateListener is informed about the changes of a collection.
+// CollectionUpdate is called by the goroutine that reads the messages, in the order of the messages.
+// It must not block (e.g. by a call or a sub), because no other message is read meanwhile.
+// The doc is a copy, which belongs to the listener.
+type UpdateListener interface {
+	CollectionUpdate(collection, operation, id string, doc Update)
+}
+
+// Collection managed cached collection data sent from the server in a
+// livedata subscription.
+//
+// It would be great to build an entire mongo compatible local store (minimongo)
+type Collection interface {
+
+	// FindOne queries objects and returns the first match.
+	FindOne(id string) Update
+	// FindAll returns a map of all items in the cache - this is a hack
+	// until we have time to build out a real minimongo interface.
+	FindAll() map[string]Update
+	// AddUpdateListener adds a channel that receives update messages.
+	AddUpdateListener(listener UpdateListener)
+
+	// livedata updates
+	added(msg Update)
+	changed(msg Update)
+	removed(msg Update)
+	addedBefore(msg Update)
+	movedBefore(msg Update)
+	init()  // init informs the collection that the connection to the server has begun/resumed
+	reset() // reset informs the collection that the connection to the server has been lost
+}
+
+// NewMockCollection creates an empty collection that does nothing.
+func NewMockCollection() Collection {
+	return &MockCache{}
+}
+
+// NewCollection creates a new collection - always KeyCache.
+func NewCollection(name string) Collection {
+	return &KeyCache{Name: name, items: make(map[string]Update)}
+}
+
+// KeyCache caches items keyed on unique ID.
+type KeyCache struct {
+	// The name of the collection
+	Name string
+	// items contains collection items by ID
+	items map[string]Update
+	// listeners contains all the listeners that should be notified of collection updates.
+	listeners []UpdateListener
+	// mux protects the items and the listeners. It is not held while the listeners are called.
+	mux sync.RWMutex
+}
+
+func (c *KeyCache) added(msg Update) {
+	id, fields := parseUpdate(msg)
+	if fields != nil {
+		c.mux.Lock()
+		c.items[id] = fields
+		listeners := c.listeners
+		c.mux.Unlock()
+		c.notify(listeners, "create", id, fields)
+	}
+}
+
+func (c *KeyCache) changed(msg Update) {
+	id, fields := parseUpdate(msg)
+	if fields != nil {
+		c.mux.Lock()
+		item, ok := c.items[id]
+		if !ok {
+			// The Meteor streamer sends changed for a document it never added
+			item = Update{}
+		}
+		for key, value := range fields {
+			item[key] = value
+		}
+		c.items[id] = item
+		doc := copyUpdate(item)
+		listeners := c.listeners
+		c.mux.Unlock()
+		c.notify(listeners, "update", id, doc)
+	}
+}
+
+func (c *KeyCache) removed(msg Update) {
+	id, _ := parseUpdate(msg)
+	if len(id) > 0 {
+		c.mux.Lock()
+		delete(c.items, id)
+		listeners := c.listeners
+		c.mux.Unlock()
+		c.notify(listeners, "remove", id, nil)
+	}
+}
+
+func (c *KeyCache) addedBefore(msg Update) {
+	// for keyed cache, ordered commands are a noop
+}
+
+func (c *KeyCache) movedBefore(msg Update) {
+	// for keyed cache, ordered commands are a noop
+}
+
+// init prepares the collection for data updates (called when a new connection is
+// made or a connection/session is resumed).
+func (c *KeyCache) init() {
+	// Reset the local collection with fresh server state
+	c.mux.Lock()
+	c.items = make(map[string]Update)
+	c.mux.Unlock()
+}
+
+// reset causes the collection to return to a fresh state. Resets are currently implemented by clearing all items
+// from the collection cache.
+func (c *KeyCache) reset() {
+	c.init()
+	c.mux.RLock()
+	listeners := c.listeners
+	c.mux.RUnlock()
+	c.notify(listeners, "reset", "", nil)
+}
+
+// notify sends a copy of the Update to all UpdateListener's, which should never block.
+func (c *KeyCache) notify(listeners []UpdateListener, operation, id string, doc Update) {
+	for _, listener := range listeners {
+		listener.CollectionUpdate(c.Name, operation, id, copyUpdate(doc))
+	}
+}
+
+// FindOne returns a copy of the item with matching id.
+func (c *KeyCache) FindOne(id string) Update {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return copyUpdate(c.items[id])
+}
+
+// FindAll returns a copy of all items in the collection
+func (c *KeyCache) FindAll() map[string]Update {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	items := make(map[string]Update, len(c.items))
+	for id, item := range c.items {
+		items[id] = copyUpdate(item)
+	}
+	return items
+}
+
+// AddUpdateListener adds a listener for changes on a collection.
+func (c *KeyCache) AddUpdateListener(listener UpdateListener) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+// OrderedCache caches items based on list order.
+// This is a placeholder, currently not implemented as the Meteor server
+// does not transmit ordered collections over DDP yet.
+type OrderedCache struct {
+	// ranks contains ordered collection items for ordered collections
+	items []interface{}
+}
+
+func (c *OrderedCache) added(msg Update) {
+	// for ordered cache, key commands are a noop
+}
+
+func (c *OrderedCache) changed(msg Update) {
+
+}
+
+func (c *OrderedCache) removed(msg Update) {
+
+}
+
+func (c *OrderedCache) addedBefore(msg Update) {
+
+}
+
+func (c *OrderedCache) movedBefore(msg Update) {
+
+}
+
+func (c *OrderedCache) init() {
+
+}
+
+func (c *OrderedCache) reset() {
+
+}
+
+// FindOne returns the item with matching id.
+func (c *OrderedCache) FindOne(id string) Update {
+	return nil
+}
+
+// FindAll returns a dump of all items in the collection
+func (c *OrderedCache) FindAll() map[string]Update {
+	return map[string]Update{}
+}
+
+// AddUpdateListener does nothing.
+func (c *OrderedCache) AddUpdateListener(ch UpdateListener) {
+}
+
+// MockCache implements the Collection interface but does nothing with the data.
+type MockCache struct {
+}
+
+func (c *MockCache) added(msg Update) {
+
+}
+
+func (c *MockCache) changed(msg Update) {
+
+}
+
+func (c *MockCache) removed(msg Update) {
+
+}
+
+func (c *MockCache) addedBefore(msg Update) {
+
+}
+
+func (c *MockCache) movedBefore(msg Update) {
+
+}
+
+func (c *MockCache) init() {
+
+}
+
+func (c *MockCache) reset() {
+
+}
+
+// FindOne returns the item with matching id.
+func (c *MockCache) FindOne(id string) Update {
+	return nil
+}
+
+// FindAll returns a dump of all items in the collection
+func (c *MockCache) FindAll() map[string]Update {
+	return map[string]Update{}
+}
+
+// AddUpdateListener does nothing.
+func (c *MockCache) AddUpdateListener(ch UpdateListener) {
+}
+
+// parseUpdate returns the ID and fields from a DDP Update document.
+func parseUpdate(up Update) (ID string, Fields Update) {
+	key, ok := up["id"]
+	if ok {
+		switch id := key.(type) {
+		case string:
+			updates, ok := up["fields"]
+			if ok {
+				switch fields := updates.(type) {
+				case map[string]interface{}:
+					return id, Update(fields)
+				default:
+					// Don't know what to do...
+				}
+			}
+			return id, nil
+		}
+	}
+	return "", nil
+}
+
+// copyUpdate returns a copy of the fields. The values are never changed by the collection, only replaced.
+func copyUpdate(doc Update) Update {
+	if doc == nil {
+		return nil
+	}
+	update := make(Update, len(doc))
+	for key, value := range doc {
+		update[key] = value
+	}
+	return update
+}
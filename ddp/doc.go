@@ -0,0 +1,15 @@
+// Package ddp implements the MeteorJS DDP protocol over websockets. Fallback
+// to long polling is NOT supported (and is not planned on ever being supported
+// by this library). We will try to model the library after `net/http` - right
+// now the library is bare bones and doesn't provide the plug-ability of http.
+// However, that's the goal for the package eventually.
+//
+// This is a copy of github.com/gopackage/ddp v0.0.6 (see LICENSE) with the following changes for the bot:
+//   - The collections call their UpdateListeners by the goroutine that reads the messages, in the order
+//     of the messages. Every listener gets its own copy of the document.
+//   - FindOne and FindAll return copies and can be called while messages are read.
+//   - A changed message for a document that was never added adds the document. The Meteor streamer
+//     sends all its messages this way.
+//   - The state of the Client (calls, subs, collections and the connection) is guarded by a mutex,
+//     so it can be used by more than one goroutine.
+package ddp
@@ -2,9 +2,11 @@ package bot
 
 import (
 	"errors"
-	"fmt"
+	"strings"
+	"sync"
+	"time"
 
-	ddp "github.com/gopackage/ddp"
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
 
 	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
 )
@@ -32,7 +34,7 @@ func (c *Client) ddpDisconnect() {
 
 func (c *Client) ddpCall(method bbb.CallType, params ...interface{}) (interface{}, error) {
 	if c.ddpClient != nil {
-		callname := bbb.GetCall(method)
+		callname := c.resolveInternalID(bbb.GetCall(method))
 		result, err := c.ddpClient.Call(callname, params...)
 		if err != nil {
 			return result, errors.New("could not call " + callname + ": " + err.Error())
@@ -46,40 +48,413 @@ func (c *Client) ddpCall(method bbb.CallType, params ...interface{}) (interface{
 // EVENTS (Collections)
 //--------------------------------------------------
 
+// Operations given to the updaterfuncs (the ddp collections call them create, update, remove and reset)
+const (
+	addedOperation   = "create"
+	changedOperation = "update"
+	removedOperation = "remove"
+	resetOperation   = "reset"
+	// The document was sent again by the server after a reconnect, but it was already known before.
+	resyncOperation = "resync"
+)
+
 // This is for all events that are in "event_....go" files
 type updaterfunc func(collection string, operation string, id string, doc ddp.Update)
 type ddpEventHandler struct {
 	client  *Client
-	updater map[string][]updaterfunc
+	updater map[string][]ddpUpdater
+	// id of the last updater added by ddpSubscribe
+	lastUpdaterID uint64
+
+	// collections the handler is already registered on as update listener.
+	// ddp collections do not support removing listeners, so this is never cleared.
+	listening map[string]bool
+
+	// the updates and documents of each collection (queues[collection])
+	queueMutex *sync.Mutex
+	queues     map[string]*ddpQueue
+
+	// guards known, resyncing and resyncStarted. The ddpClient reports the lost connection
+	// while a sub may wait with subMutex locked, so subMutex can not be used for them.
+	docMutex *sync.Mutex
+	// ids of the documents in each collection (known[collection][id])
+	known map[string]map[string]bool
+	// ids of the known documents which were not sent again after a reconnect (resyncing[collection][id])
+	resyncing map[string]map[string]bool
+	// true from the lost connection until the session was resumed
+	resyncStarted bool
+}
+
+// An update of a document for the updaterfuncs
+type ddpUpdate struct {
+	number     uint64 // the updates of a collection are numbered in their order
+	collection string
+	operation  string
+	id         string
+	doc        ddp.Update
 }
 
-// Will be emited by ddpClient
+// The updates of one collection. Only one goroutine at a time gives them to the updaterfuncs,
+// so they get the updates of a document in the order the ddpClient received them.
+type ddpQueue struct {
+	updates []ddpUpdate // not given to the updaterfuncs yet
+	running bool        // a goroutine gives the updates to the updaterfuncs
+	last    uint64      // number of the last update
+	// the documents after the last update (docs[id]). A new updaterfunc starts with them (see ddpSubscribeDocs).
+	docs map[string]ddp.Update
+}
+
+// Will be emited by ddpClient. It is called by the goroutine that reads the ddp messages (in their order),
+// so the updaterfuncs are called by the queue of the collection. They can (un)subscribe and call methods.
 func (e *ddpEventHandler) CollectionUpdate(collection string, operation string, id string, doc ddp.Update) {
-	fmt.Print("CollectionUpdate: " + collection + " " + operation + " " + id + " ")
-	fmt.Println(doc)
-	// "redirect" to the event handler
-	if flist, found := e.updater[collection]; found {
-		for _, f := range flist {
-			if f != nil {
-				f(collection, operation, id, doc)
+	operation = e.trackDocument(collection, operation, id)
+	e.enqueue(ddpUpdate{collection: collection, operation: operation, id: id, doc: doc})
+}
+
+// Add the update to the queue of its collection and start a goroutine for the queue if there is none
+func (e *ddpEventHandler) enqueue(update ddpUpdate) {
+	e.queueMutex.Lock()
+	defer e.queueMutex.Unlock()
+
+	queue := e.getQueue(update.collection)
+	queue.last++
+	update.number = queue.last
+	switch update.operation {
+	case removedOperation:
+		delete(queue.docs, update.id)
+	case resetOperation:
+		// the documents are kept until the session is resumed (see resumeSession)
+	default:
+		if update.doc != nil {
+			queue.docs[update.id] = copyDoc(update.doc)
+		}
+	}
+
+	queue.updates = append(queue.updates, update)
+	if !queue.running {
+		queue.running = true
+		go e.processQueue(queue)
+	}
+}
+
+// Give the updates of the queue to the updaterfuncs until it is empty
+func (e *ddpEventHandler) processQueue(queue *ddpQueue) {
+	for {
+		e.queueMutex.Lock()
+		if len(queue.updates) == 0 {
+			queue.running = false
+			e.queueMutex.Unlock()
+			return
+		}
+		update := queue.updates[0]
+		queue.updates = queue.updates[1:]
+		e.queueMutex.Unlock()
+
+		e.client.log().Debug("collection update", "collection", update.collection, "operation", update.operation, "id", update.id, "doc", update.doc)
+
+		// Copy the list, so the updaters can (un)subscribe themselfs
+		e.client.subMutex.Lock()
+		flist := append([]ddpUpdater{}, e.updater[update.collection]...)
+		e.client.subMutex.Unlock()
+
+		// "redirect" to the event handler. Updaters added after the update already have its document.
+		for _, u := range flist {
+			if u.since < update.number {
+				u.f(update.collection, update.operation, update.id, update.doc)
 			}
 		}
 	}
 }
 
-// Subscribe to a ddp collection
-func (c *Client) ddpSubscribe(collectionName bbb.SubType, callbackUpdater updaterfunc) error {
+// Returns the queue of the collection. Must be called with queueMutex locked.
+func (e *ddpEventHandler) getQueue(collection string) *ddpQueue {
+	queue, found := e.queues[collection]
+	if !found {
+		queue = &ddpQueue{docs: make(map[string]ddp.Update)}
+		e.queues[collection] = queue
+	}
+	return queue
+}
+
+// Returns a copy of the documents of the collection after the last update and the number of that update
+func (e *ddpEventHandler) getDocs(collection string) (map[string]ddp.Update, uint64) {
+	e.queueMutex.Lock()
+	defer e.queueMutex.Unlock()
+
+	queue := e.getQueue(collection)
+	docs := make(map[string]ddp.Update, len(queue.docs))
+	for id, doc := range queue.docs {
+		docs[id] = copyDoc(doc)
+	}
+	return docs, queue.last
+}
+
+// Returns a copy of the first document of the collection for which match returns true.
+// The documents are the ones after the last received update, even if it was not given to the updaterfuncs yet.
+func (e *ddpEventHandler) findDoc(collection string, match func(doc ddp.Update) bool) (ddp.Update, bool) {
+	e.queueMutex.Lock()
+	defer e.queueMutex.Unlock()
+
+	for _, doc := range e.getQueue(collection).docs {
+		if match(doc) {
+			return copyDoc(doc), true
+		}
+	}
+	return nil, false
+}
+
+// Forget the documents of the collection (after the unsub)
+func (e *ddpEventHandler) forgetDocs(collection string) {
+	e.queueMutex.Lock()
+	defer e.queueMutex.Unlock()
+
+	e.getQueue(collection).docs = make(map[string]ddp.Update)
+}
+
+// Returns a copy of the document (the fields are not copied)
+func copyDoc(doc ddp.Update) ddp.Update {
+	docCopy := make(ddp.Update, len(doc))
+	for key, value := range doc {
+		docCopy[key] = value
+	}
+	return docCopy
+}
+
+// An updaterfunc added by ddpSubscribe. The id is used to remove it again.
+type ddpUpdater struct {
+	id    uint64
+	f     updaterfunc
+	since uint64 // number of the last update of the collection before the updaterfunc was added
+}
+
+// Returned by ddpSubscribe. It is needed to unsubscribe again with ddpUnsubscribe.
+type ddpHandle struct {
+	collectionName bbb.SubType
+	updaterID      uint64 // 0 if no updaterfunc was given
+}
+
+// A ddp subscription shared by everything that subscribed to the same bbb.SubType
+type ddpSubscription struct {
+	name string        // name of the sub (INTERNALID is already replaced). This is also the name of the collection.
+	args []interface{} // args of the sub
+	id   string        // id of the ddp sub message. Needed to unsub.
+	refs int           // how often ddpSubscribe was called without ddpUnsubscribe
+	// Done channel of the ddp sub. The ddpClient sends the sub again after a reconnect and
+	// reports its ready and nosub here as well.
+	done chan *ddp.Call
+}
+
+// How long to wait for the server to confirm a sub or unsub
+const ddpSubTimeout = 10 * time.Second
+
+// Subscribe to a ddp collection.
+// If the collection is already subscribed, no new sub will be sent. Only the callbackUpdater
+// is added and the subscription has to be unsubscribed one more time before it is removed.
+// The returned handle is needed to unsubscribe (and to remove the callbackUpdater) again.
+func (c *Client) ddpSubscribe(collectionName bbb.SubType, callbackUpdater updaterfunc) (ddpHandle, error) {
+	handle, _, err := c.ddpSubscribeDocs(collectionName, callbackUpdater)
+	return handle, err
+}
+
+// Subscribe to a ddp collection like ddpSubscribe and return the documents the collection already has.
+// The callbackUpdater gets all updates after these documents, so they can be used as its initial state.
+// The updates may be given to the callbackUpdater before ddpSubscribeDocs returns. If the initial state
+// is set after the return, both have to be guarded by the same mutex, which is locked before the call.
+func (c *Client) ddpSubscribeDocs(collectionName bbb.SubType, callbackUpdater updaterfunc) (ddpHandle, map[string]ddp.Update, error) {
+	handle := ddpHandle{collectionName: collectionName}
+	if c.ddpClient == nil {
+		return handle, nil, errors.New("ddpClient is nil")
+	}
+
 	// subscribe to bbb.collectionName
-	subname, args := bbb.GetSub(collectionName) // get sub name and args
-	err := c.ddpClient.Sub(subname, args...)
+	subname, args := c.getSub(collectionName) // get sub name and args
+
+	c.subMutex.Lock()
+	defer c.subMutex.Unlock()
+
+	// add the update listener of the ddp collection (only once per collection).
+	// This is done before the sub, so the documents the server sends first are not missed.
+	if !c.ddpEventHandler.listening[subname] {
+		collection := c.ddpClient.CollectionByName(subname) // get the ddp collection
+		collection.AddUpdateListener(c.ddpEventHandler)
+		c.ddpEventHandler.listening[subname] = true
+	}
+
+	sub, found := c.subscriptions[collectionName]
+	if !found {
+		id, done, err := c.ddpSendSub(subname, args)
+		if err != nil {
+			return handle, nil, err
+		}
+		sub = &ddpSubscription{
+			name: subname,
+			args: args,
+			id:   id,
+			refs: 0,
+			done: done,
+		}
+		c.subscriptions[collectionName] = sub
+	}
+	sub.refs++
+
+	// The sub is ready, so the documents the server sent first are part of the updates.
+	// The updaters of the collection are copied with subMutex locked, so the new
+	// update handler gets all updates after the documents.
+	docs, since := c.ddpEventHandler.getDocs(subname)
+
+	// add the update handler
+	if callbackUpdater != nil {
+		c.ddpEventHandler.lastUpdaterID++
+		handle.updaterID = c.ddpEventHandler.lastUpdaterID
+		c.ddpEventHandler.updater[subname] = append(c.ddpEventHandler.updater[subname], ddpUpdater{
+			id:    handle.updaterID,
+			f:     callbackUpdater,
+			since: since,
+		})
+	}
+	return handle, docs, nil
+}
+
+// Subscribe to a ddp collection without an update handler if it is not subscribed yet.
+// This is used to read the collection directly. The subscription is kept until Leave.
+func (c *Client) ddpSubscribeOnce(collectionName bbb.SubType) error {
+	c.subMutex.Lock()
+	_, found := c.subscriptions[collectionName]
+	c.subMutex.Unlock()
+	if found {
+		return nil
+	}
+	_, err := c.ddpSubscribe(collectionName, nil)
+	return err
+}
+
+// Unsubscribe from a ddp collection.
+// The callbackUpdater that was given to ddpSubscribe is removed. The ddp unsub
+// is only sent if this was the last subscription of the collection.
+func (c *Client) ddpUnsubscribe(handle ddpHandle) error {
+	collectionName := handle.collectionName
+	subname, _ := c.getSub(collectionName)
+
+	c.subMutex.Lock()
+	defer c.subMutex.Unlock()
+
+	sub, found := c.subscriptions[collectionName]
+	if !found {
+		return errors.New("not subscribed to " + subname)
+	}
+
+	// remove the update handler
+	if handle.updaterID != 0 {
+		c.ddpEventHandler.removeUpdater(subname, handle.updaterID)
+	}
+
+	sub.refs--
+	if sub.refs > 0 {
+		return nil
+	}
+
+	delete(c.subscriptions, collectionName)
+
+	// Streams have more than one sub for the same collection
+	if c.isSubscribed(subname) {
+		return c.ddpSendUnsub(sub)
+	}
+	delete(c.ddpEventHandler.updater, subname)
+	c.ddpEventHandler.forgetDocuments(subname)
+	c.ddpEventHandler.forgetDocs(subname)
+	return c.ddpSendUnsub(sub)
+}
+
+// Unsubscribe from all ddp collections and remove all update handlers
+func (c *Client) ddpUnsubscribeAll() {
+	c.subMutex.Lock()
+	defer c.subMutex.Unlock()
+
+	for collectionName, sub := range c.subscriptions {
+		c.ddpSendUnsub(sub)
+		delete(c.subscriptions, collectionName)
+		c.ddpEventHandler.forgetDocuments(sub.name)
+		c.ddpEventHandler.forgetDocs(sub.name)
+	}
+	c.ddpEventHandler.updater = make(map[string][]ddpUpdater)
+}
+
+// Send a new sub for an already subscribed collection (and unsub the old one).
+func (c *Client) ddpResubscribe(collectionName bbb.SubType) error {
+	subname, _ := c.getSub(collectionName)
+
+	c.subMutex.Lock()
+	defer c.subMutex.Unlock()
+
+	sub, found := c.subscriptions[collectionName]
+	if !found {
+		return errors.New("not subscribed to " + subname)
+	}
+
+	return c.ddpSendResub(sub)
+}
+
+// Send a new sub message for the subscription and unsub the old one afterwards.
+// The documents are part of both subs for a moment, so the server does not remove them.
+func (c *Client) ddpSendResub(sub *ddpSubscription) error {
+	id, done, err := c.ddpSendSub(sub.name, sub.args)
 	if err != nil {
-		return errors.New("could not subscribe to " + subname + ": " + err.Error())
+		return err
+	}
+	c.ddpSendUnsub(sub)
+	sub.id = id
+	sub.done = done
+	return nil
+}
+
+// Send the ddp sub message and wait until the subscription is ready.
+// Returns the id of the sub and the channel on which the ddpClient reports it.
+func (c *Client) ddpSendSub(subname string, args []interface{}) (string, chan *ddp.Call, error) {
+	// The ready after a reconnect and the final nosub have to fit in as well
+	done := make(chan *ddp.Call, 4)
+	call := <-c.ddpClient.Subscribe(subname, done, args...).Done
+	if call.Error != nil {
+		return "", nil, errors.New("could not subscribe to " + subname + ": " + call.Error.Error())
+	}
+	return call.ID, done, nil
+}
+
+// Send the ddp unsub message. The server answers with nosub, which also removes the sub from the ddpClient.
+func (c *Client) ddpSendUnsub(sub *ddpSubscription) error {
+	if err := c.ddpClient.Send(ddp.Message{Type: "unsub", ID: sub.id}); err != nil {
+		return errors.New("could not unsubscribe from " + sub.name + ": " + err.Error())
 	}
-	collection := c.ddpClient.CollectionByName(subname)                                              // get the ddp collection
-	collection.AddUpdateListener(c.ddpEventHandler)                                                  // add the update listener of the ddp collection
-	c.ddpEventHandler.updater[subname] = append(c.ddpEventHandler.updater[subname], callbackUpdater) // add the update handler
 	return nil
 }
 
-// Unsubscribe from a ddp collection
-// ToDo: send ddp unsub and remove the update listener and the update handler
+// Returns true if there is a subscription of the collection subname. Must be called with subMutex locked.
+func (c *Client) isSubscribed(subname string) bool {
+	for _, sub := range c.subscriptions {
+		if sub.name == subname {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the name and args of the sub. INTERNALID in the name is replaced with the internal meeting id.
+func (c *Client) getSub(collectionName bbb.SubType) (string, []interface{}) {
+	subname, args := bbb.GetSub(collectionName)
+	return c.resolveInternalID(subname), args
+}
+
+// Replace the placeholder INTERNALID (see bbb.GetSub and bbb.GetCall) with the internal meeting id
+func (c *Client) resolveInternalID(name string) string {
+	return strings.ReplaceAll(name, "INTERNALID", c.InternalMeetingID)
+}
+
+// Remove the updater with the id from the collection. Must be called with subMutex locked.
+func (e *ddpEventHandler) removeUpdater(collection string, id uint64) {
+	flist := e.updater[collection]
+	for i, u := range flist {
+		if u.id == id {
+			e.updater[collection] = append(flist[:i:i], flist[i+1:]...)
+			return
+		}
+	}
+}
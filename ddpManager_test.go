@@ -0,0 +1,242 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
+)
+
+const testInternalMeetingID = "ddptest-internal-meeting"
+
+// Client that is connected to the ddptest server like after Join (without the api and the auth token)
+func newTestClient(t *testing.T, server *ddptest.Server) *Client {
+	client, err := NewClient(server.URL, server.WebSocketURL, "http://127.0.0.1/pad/", "ws://127.0.0.1/pad/", "http://127.0.0.1/bigbluebutton/api/", "secret", "ws://127.0.0.1/bbb-webrtc-sfu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetLogger(logger.Nop())
+	client.InternalMeetingID = testInternalMeetingID
+	client.InternalUserID = "w_bot"
+	client.ddpClient.ReconnectInterval = 10 * time.Millisecond
+
+	if err := client.ddpConnect(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "ddp session", func() bool { return client.ddpClient.Session() != "" })
+
+	client.meetingEventsMutex.Lock()
+	client.ddpSession = client.ddpClient.Session()
+	client.sessionActive = true
+	client.meetingEventsMutex.Unlock()
+	client.updateStatus(CONNECTED)
+
+	t.Cleanup(func() {
+		client.meetingEventsMutex.Lock()
+		client.sessionActive = false
+		client.meetingEventsMutex.Unlock()
+		client.ddpDisconnect()
+	})
+	return client
+}
+
+// Wait until condition returns true. The test fails after 5 seconds.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	timeout := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(timeout) {
+			t.Fatalf("timeout while waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Test for the updates of a stream (every message once and in order, while the collection is read)
+func TestDDPStreamUpdates(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	const count = 500
+	received := make(chan int, count)
+	_, err := client.ddpSubscribe(bbb.StreamCursorSub, func(collection string, operation string, id string, doc ddp.Update) {
+		var content struct {
+			Number int `json:"number"`
+		}
+		if err := bbb.ConvertInToStreamMessage(doc).ConvertArg(&content); err != nil {
+			t.Errorf("ConvertArg() FAILED: %v", err)
+		}
+		received <- content.Number
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the collection is read by other goroutines meanwhile (e.g. by the getters)
+	collection := client.ddpClient.CollectionByName("stream-cursor-" + testInternalMeetingID)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				for _, doc := range collection.FindAll() {
+					doc["number"] = 0
+				}
+			}
+		}
+	}()
+
+	for i := 1; i <= count; i++ {
+		server.Stream("stream-cursor-"+testInternalMeetingID, "message", map[string]interface{}{"number": i})
+	}
+
+	for i := 1; i <= count; i++ {
+		select {
+		case number := <-received:
+			if number != i {
+				t.Fatalf("stream update %d FAILED: got message %d, expected %d", i, number, i)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("stream update %d FAILED: message was not received", i)
+		}
+	}
+
+	select {
+	case number := <-received:
+		t.Errorf("stream updates FAILED: got message %d again", number)
+	case <-time.After(50 * time.Millisecond):
+		t.Logf("stream updates PASSED")
+	}
+}
+
+// Returns how many sub (or unsub) messages of the collection the server received
+func countMessages(server *ddptest.Server, msg string, name string) int {
+	count := 0
+	for _, message := range server.Messages() {
+		if message.Msg == msg && message.Name == name {
+			count++
+		}
+	}
+	return count
+}
+
+// Test for the reference counting of ddpSubscribe and ddpUnsubscribe
+func TestDDPSubscribeRefs(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	received := make(chan string, 10)
+	updater := func(name string) updaterfunc {
+		return func(collection string, operation string, id string, doc ddp.Update) {
+			received <- name + " " + operation + " " + id
+		}
+	}
+
+	first, err := client.ddpSubscribe(bbb.UsersSub, updater("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.ddpSubscribe(bbb.UsersSub, updater("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subs := countMessages(server, "sub", "users"); subs != 1 {
+		t.Errorf("ddpSubscribe() FAILED: server got %d subs, expected 1", subs)
+	}
+
+	// the first updater is removed, but the collection stays subscribed
+	if err := client.ddpUnsubscribe(first); err != nil {
+		t.Errorf("ddpUnsubscribe() FAILED: %v", err)
+	}
+	server.Add("users", "doc1", map[string]interface{}{"userId": "w_1"})
+	select {
+	case update := <-received:
+		if update != "second create doc1" {
+			t.Errorf("ddpUnsubscribe() FAILED: got update %q, expected second create doc1", update)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("ddpUnsubscribe() FAILED: the second updater got no update")
+	}
+	if unsubs := countMessages(server, "unsub", "users"); unsubs != 0 {
+		t.Errorf("ddpUnsubscribe() FAILED: server got %d unsubs, expected 0", unsubs)
+	}
+
+	// the last handle sends the unsub
+	if err := client.ddpUnsubscribe(second); err != nil {
+		t.Errorf("ddpUnsubscribe() FAILED: %v", err)
+	}
+	waitFor(t, "the unsub", func() bool { return server.Subscribers("users") == 0 })
+	if err := client.ddpUnsubscribe(second); err == nil {
+		t.Errorf("ddpUnsubscribe() FAILED: no error for a collection that is not subscribed")
+	}
+	select {
+	case update := <-received:
+		t.Errorf("ddpUnsubscribe() FAILED: got update %q after the unsubscribe", update)
+	default:
+		t.Logf("ddpSubscribe() and ddpUnsubscribe() PASSED")
+	}
+}
+
+// Test for listeners that are added at the same time (one subscription) and for the listeners after Leave
+func TestAddSubscribedListener(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	const listeners = 10
+	received := make(chan []bbb.Cursor, listeners)
+	errs := make(chan error, listeners)
+	for i := 0; i < listeners; i++ {
+		go func() {
+			errs <- client.OnCursor(func(cursors []bbb.Cursor) {
+				received <- cursors
+			})
+		}()
+	}
+	for i := 0; i < listeners; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("OnCursor() FAILED: %v", err)
+		}
+	}
+
+	collection := "stream-cursor-" + testInternalMeetingID
+	if subs := countMessages(server, "sub", collection); subs != 1 {
+		t.Errorf("OnCursor() FAILED: server got %d subs, expected 1", subs)
+	}
+
+	server.Stream(collection, "message", map[string]interface{}{
+		"cursors": map[string]interface{}{"w_1": map[string]interface{}{"xPercent": 10, "yPercent": 20}},
+	})
+	for i := 0; i < listeners; i++ {
+		select {
+		case cursors := <-received:
+			if len(cursors) != 1 || cursors[0].UserID != "w_1" {
+				t.Errorf("OnCursor() FAILED: got %+v, expected the cursor of w_1", cursors)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("OnCursor() FAILED: %d of %d listeners were called", i, listeners)
+		}
+	}
+	select {
+	case <-received:
+		t.Errorf("OnCursor() FAILED: a listener was called twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Leave keeps only the persistent listeners
+	client.OnStatus(func(status StatusType) {})
+	client.resetSession()
+	if client.hasListeners("OnCursor") || !client.hasListeners("OnStatus") {
+		t.Errorf("resetSession() FAILED: OnCursor listeners kept %t, OnStatus listeners kept %t, expected false and true", client.hasListeners("OnCursor"), client.hasListeners("OnStatus"))
+	} else {
+		t.Logf("addSubscribedListener() PASSED")
+	}
+}
@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"errors"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//--------------------------------------------------
+// Resume the session after the ddpClient reconnected
+//--------------------------------------------------
+
+// The ddpClient reconnects by itself if the websocket drops and sends all subs again,
+// but the new ddp session is not authenticated. The server sends all documents of the
+// subscribed collections again.
+// After the reconnect the auth token is validated again. While this happens the client status is RECONNECTING.
+// Documents which are added again by the server and were already known before the
+// reconnect are given to the updaterfuncs with the operation "resync" instead of "create".
+// Documents which were removed while the client was disconnected are given with "remove".
+
+// Will be emited by ddpClient every time the ddp session is established
+func (e *ddpEventHandler) Connected() {
+	c := e.client
+
+	// The first connection is handled by Join
+	c.meetingEventsMutex.Lock()
+	resume := c.sessionActive && c.ddpClient.Session() != c.ddpSession
+	c.meetingEventsMutex.Unlock()
+	if !resume {
+		return
+	}
+
+	if err := c.resumeSession(); err != nil {
+		c.log().Error("failed to resume session after reconnect", "error", err)
+		if !c.endSession() {
+			return
+		}
+		c.ddpDisconnect()
+		c.updateStatus(DISCONNECTED)
+		return
+	}
+
+	c.log().Info("session resumed after reconnect")
+	c.updateStatus(CONNECTED)
+}
+
+// Validate the auth token for the new ddp session and wait for the subs the ddpClient sent again
+func (c *Client) resumeSession() error {
+	c.updateStatus(RECONNECTING)
+
+	// Call the validateAuthToken method with the userID, authToken, and userName.
+	// The server runs the subs again for the validated user and sends their documents before the result.
+	_, err := c.ddpCall(bbb.ValidateAuthTokenCall, c.InternalMeetingID, c.InternalUserID, c.AuthToken, c.InternalUserID)
+	if err != nil {
+		return err
+	}
+
+	c.subMutex.Lock()
+	defer c.subMutex.Unlock()
+
+	for _, sub := range c.subscriptions {
+		if err := c.ddpWaitReady(sub); err != nil {
+			return err
+		}
+
+		// The collection is complete after the sub is ready. All known documents
+		// that are missing now were removed while we were disconnected.
+		current := c.ddpClient.CollectionByName(sub.name).FindAll()
+		removed := []string{}
+		c.ddpEventHandler.docMutex.Lock()
+		for id := range c.ddpEventHandler.resyncing[sub.name] {
+			if _, found := current[id]; !found {
+				delete(c.ddpEventHandler.resyncing[sub.name], id)
+				delete(c.ddpEventHandler.known[sub.name], id)
+				removed = append(removed, id)
+			}
+		}
+		c.ddpEventHandler.docMutex.Unlock()
+
+		// after the updates the server sent again
+		for _, id := range removed {
+			c.ddpEventHandler.enqueue(ddpUpdate{collection: sub.name, operation: removedOperation, id: id})
+		}
+	}
+
+	c.ddpEventHandler.docMutex.Lock()
+	c.ddpEventHandler.resyncStarted = false
+	c.ddpEventHandler.docMutex.Unlock()
+
+	c.meetingEventsMutex.Lock()
+	c.ddpSession = c.ddpClient.Session()
+	c.meetingEventsMutex.Unlock()
+
+	return nil
+}
+
+// Wait until the sub the ddpClient sent again after the reconnect is ready.
+// If the server stopped it (nosub), the sub is sent again.
+func (c *Client) ddpWaitReady(sub *ddpSubscription) error {
+	select {
+	case call := <-sub.done:
+		if call.Error == nil {
+			return nil
+		}
+		id, done, err := c.ddpSendSub(sub.name, sub.args)
+		if err != nil {
+			return err
+		}
+		sub.id = id
+		sub.done = done
+		return nil
+	case <-time.After(ddpSubTimeout):
+		return errors.New("sub " + sub.name + " was not ready after the reconnect")
+	}
+}
+
+// Remember all known documents to detect the resync of them.
+// The ddpClient reports the lost connection before it sends the subs again, so this is done
+// before the server sends any document again.
+func (e *ddpEventHandler) startResync() {
+	e.docMutex.Lock()
+	defer e.docMutex.Unlock()
+
+	// The ddpClient may try to reconnect more than once
+	if e.resyncStarted {
+		return
+	}
+	e.resyncStarted = true
+
+	for collection, ids := range e.known {
+		e.resyncing[collection] = make(map[string]bool)
+		for id := range ids {
+			e.resyncing[collection][id] = true
+		}
+	}
+}
+
+// Keep track of the known documents of a collection. Returns the operation to give to the updaterfuncs.
+func (e *ddpEventHandler) trackDocument(collection string, operation string, id string) string {
+	e.docMutex.Lock()
+	defer e.docMutex.Unlock()
+
+	switch operation {
+	case addedOperation:
+		if e.resyncing[collection][id] {
+			delete(e.resyncing[collection], id)
+			return resyncOperation
+		}
+		if e.known[collection] == nil {
+			e.known[collection] = make(map[string]bool)
+		}
+		e.known[collection][id] = true
+	case removedOperation:
+		delete(e.known[collection], id)
+	}
+	return operation
+}
+
+// Forget all known documents of a collection
+func (e *ddpEventHandler) forgetDocuments(collection string) {
+	e.docMutex.Lock()
+	defer e.docMutex.Unlock()
+
+	delete(e.known, collection)
+	delete(e.resyncing, collection)
+}
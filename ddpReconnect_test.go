@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for the resume of the session after the connection was lost (resync, remove and status)
+func TestResumeSession(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("users", "doc1", map[string]interface{}{"userId": "w_1", "name": "Alice"})
+	server.Add("users", "doc2", map[string]interface{}{"userId": "w_2", "name": "Bob"})
+
+	client := newTestClient(t, server)
+	// enough time to change the documents while the client is disconnected
+	client.ddpClient.ReconnectInterval = 200 * time.Millisecond
+
+	updates := make(chan string, 10)
+	if _, err := client.ddpSubscribe(bbb.UsersSub, func(collection string, operation string, id string, doc ddp.Update) {
+		// the lost connection is reported with reset
+		if operation != resetOperation {
+			updates <- operation + " " + id
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(chan StatusType, 10)
+	client.OnStatus(func(status StatusType) {
+		statuses <- status
+	})
+
+	server.Disconnect()
+	server.Remove("users", "doc2")
+	server.Add("users", "doc3", map[string]interface{}{"userId": "w_3", "name": "Carol"})
+	server.Change("users", "doc1", map[string]interface{}{"name": "Alice B."})
+
+	// The documents sent again come first (in any order), then the removed ones
+	received := []string{}
+	for len(received) < 3 {
+		select {
+		case update := <-updates:
+			received = append(received, update)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("resumeSession() FAILED: got updates %v, expected 3", received)
+		}
+	}
+	if received[2] != "remove doc2" {
+		t.Errorf("resumeSession() FAILED: got %v, expected remove doc2 last", received)
+	}
+	sort.Strings(received[:2])
+	if received[0] != "create doc3" || received[1] != "resync doc1" {
+		t.Errorf("resumeSession() FAILED: got %v, expected create doc3 and resync doc1", received)
+	}
+
+	// RECONNECTING until the auth token was validated again
+	reconnecting := false
+	for status := range statuses {
+		if status == RECONNECTING {
+			reconnecting = true
+		}
+		if status == CONNECTED {
+			break
+		}
+		if status == DISCONNECTED {
+			t.Fatalf("resumeSession() FAILED: the client disconnected")
+		}
+	}
+	if !reconnecting {
+		t.Errorf("resumeSession() FAILED: RECONNECTING was not reported")
+	}
+	if calls := countMessages(server, "method", "validateAuthToken"); calls != 1 {
+		t.Errorf("resumeSession() FAILED: server got %d validateAuthToken calls, expected 1", calls)
+	}
+	if subs := countMessages(server, "sub", "users"); subs != 2 {
+		t.Errorf("resumeSession() FAILED: server got %d subs of the users, expected 2", subs)
+	}
+	if session := client.ddpClient.Session(); session != "ddptest-session-2" {
+		t.Errorf("resumeSession() FAILED: got session %s, expected ddptest-session-2", session)
+	}
+
+	select {
+	case update := <-updates:
+		t.Errorf("resumeSession() FAILED: got update %s, expected no more updates", update)
+	case <-time.After(50 * time.Millisecond):
+		t.Logf("resumeSession() PASSED")
+	}
+}
+
+// Test for a reconnect that can not resume the session (validateAuthToken fails)
+func TestResumeSessionFailed(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	statuses := make(chan StatusType, 10)
+	client.OnStatus(func(status StatusType) {
+		statuses <- status
+	})
+	server.HandleMethod("validateAuthToken", func(params []interface{}) (interface{}, error) {
+		return nil, errors.New("invalid auth token")
+	})
+
+	server.Disconnect()
+
+	for {
+		select {
+		case status := <-statuses:
+			if status != DISCONNECTED {
+				continue
+			}
+			if client.isSessionActive() {
+				t.Errorf("resumeSession() FAILED: the session is still active")
+			} else {
+				t.Logf("resumeSession() with invalid auth token PASSED")
+			}
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatalf("resumeSession() FAILED: the client was not disconnected")
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Package ddptest is a local stand-in for the Meteor DDP server of bbb-html5,
+// so the collections, streams and methods the bot uses can be tested without a bbb server.
+//
+// The server keeps the documents of every collection. A sub gets all documents of the collection
+// with the name of the sub (added) and then ready. Documents that are added, changed or removed later
+// are sent to every connection that subscribed to the collection. Stream sends a message of a Meteor
+// streamer (a changed of the document "id"). Methods are answered by the handler of the method.
+package ddptest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+//  EXAMPLE in a test
+// --------------------
+// server := ddptest.NewServer()
+// defer server.Close()
+// server.Add("users", "user1", map[string]interface{}{"userId": "w_1", "name": "Alice"})
+// client, err := bot.NewClient(server.URL, server.WebSocketURL, ...)
+// ...
+// server.Stream("stream-cursor-"+internalMeetingID, "message", cursors)
+
+// MethodHandler answers a method call. The returned error is sent as the error of the result.
+type MethodHandler func(params []interface{}) (interface{}, error)
+
+// Message is a sub, unsub or method message the server received
+type Message struct {
+	Msg    string        // sub, unsub or method
+	Name   string        // name of the sub or the method
+	Params []interface{} // params of the sub or the method
+}
+
+// Server is a local DDP server. It is started by NewServer.
+type Server struct {
+	// the urls for bot.NewClient
+	URL          string // http://127.0.0.1:port/html5client/
+	WebSocketURL string // ws://127.0.0.1:port/html5client/websocket
+
+	httpServer *httptest.Server
+	upgrader   websocket.Upgrader
+
+	// mutex guards everything below. It is held while documents are sent, so every connection
+	// gets the documents in the same order.
+	mutex       *sync.Mutex
+	collections map[string]map[string]map[string]interface{} // collections[name][id] are the fields of the document
+	methods     map[string]MethodHandler
+	sessions    []*session
+	lastSession int // number of the last connection (for the session ids)
+	messages    []Message
+}
+
+// NewServer starts a Server on a local port
+func NewServer() *Server {
+	s := &Server{
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+
+		mutex:       new(sync.Mutex),
+		collections: make(map[string]map[string]map[string]interface{}),
+		methods:     make(map[string]MethodHandler),
+		sessions:    []*session{},
+		messages:    []Message{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/html5client/websocket", s.handleWebSocket)
+	s.httpServer = httptest.NewServer(mux)
+
+	s.URL = s.httpServer.URL + "/html5client/"
+	s.WebSocketURL = "ws" + strings.TrimPrefix(s.httpServer.URL, "http") + "/html5client/websocket"
+	return s
+}
+
+// HandleMethod sets the handler of a method. Methods without a handler return null.
+func (s *Server) HandleMethod(method string, handler MethodHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.methods[method] = handler
+}
+
+// Add adds (or replaces) a document and sends it to the subscribers of the collection
+func (s *Server) Add(collection string, id string, fields map[string]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.collections[collection] == nil {
+		s.collections[collection] = make(map[string]map[string]interface{})
+	}
+	doc := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		doc[key] = value
+	}
+	s.collections[collection][id] = doc
+
+	s.broadcast(collection, map[string]interface{}{"msg": "added", "collection": collection, "id": id, "fields": fields})
+}
+
+// Change sets the fields of a document and sends them to the subscribers of the collection
+func (s *Server) Change(collection string, id string, fields map[string]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	doc, found := s.collections[collection][id]
+	if !found {
+		return
+	}
+	for key, value := range fields {
+		doc[key] = value
+	}
+
+	s.broadcast(collection, map[string]interface{}{"msg": "changed", "collection": collection, "id": id, "fields": fields})
+}
+
+// Remove removes a document and tells the subscribers of the collection
+func (s *Server) Remove(collection string, id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, found := s.collections[collection][id]; !found {
+		return
+	}
+	delete(s.collections[collection], id)
+
+	s.broadcast(collection, map[string]interface{}{"msg": "removed", "collection": collection, "id": id})
+}
+
+// Stream sends a message of a Meteor streamer to the subscribers of the collection (e.g. stream-cursor-INTERNALID).
+// The streamer does not keep its messages, so a later sub does not get them.
+func (s *Server) Stream(collection string, eventName string, args ...interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if args == nil {
+		args = []interface{}{}
+	}
+	s.broadcast(collection, map[string]interface{}{
+		"msg":        "changed",
+		"collection": collection,
+		"id":         "id",
+		"fields":     map[string]interface{}{"eventName": eventName, "args": args},
+	})
+}
+
+// Messages returns all sub, unsub and method messages the server received (in order)
+func (s *Server) Messages() []Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Message{}, s.messages...)
+}
+
+// Subscribers returns how many subs of the collection are running
+func (s *Server) Subscribers(collection string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	count := 0
+	for _, session := range s.sessions {
+		for _, name := range session.subs {
+			if name == collection {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Disconnect closes the websockets of all connections (e.g. to test a reconnect)
+func (s *Server) Disconnect() {
+	s.mutex.Lock()
+	sessions := s.sessions
+	s.sessions = []*session{}
+	s.mutex.Unlock()
+
+	for _, session := range sessions {
+		session.conn.Close()
+	}
+}
+
+// Close disconnects all connections and stops the server
+func (s *Server) Close() {
+	s.Disconnect()
+	s.httpServer.Close()
+}
+
+// Send the message to every connection that subscribed to the collection. Must be called with mutex locked.
+func (s *Server) broadcast(collection string, msg map[string]interface{}) {
+	for _, session := range s.sessions {
+		for _, name := range session.subs {
+			if name == collection {
+				session.send(msg)
+				break
+			}
+		}
+	}
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	s.lastSession++
+	session := &session{
+		id:         "ddptest-session-" + strconv.Itoa(s.lastSession),
+		server:     s,
+		conn:       conn,
+		writeMutex: new(sync.Mutex),
+		subs:       make(map[string]string),
+	}
+	s.sessions = append(s.sessions, session)
+	s.mutex.Unlock()
+
+	session.run()
+}
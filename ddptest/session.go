@@ -0,0 +1,133 @@
+package ddptest
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// One DDP connection of a client
+type session struct {
+	id     string // the ddp session of the connection
+	server *Server
+	conn   *websocket.Conn
+
+	// guards the writes on the websocket
+	writeMutex *sync.Mutex
+	// running subs (subs[sub id] is the collection). Guarded by the mutex of the server.
+	subs map[string]string
+}
+
+// A message of the client. Only the fields the server needs are read.
+type clientMessage struct {
+	Msg    string        `json:"msg"`
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// Read the messages of the client until the websocket is closed
+func (s *session) run() {
+	defer s.close()
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg clientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+
+		switch msg.Msg {
+		case "connect":
+			s.send(map[string]interface{}{"msg": "connected", "session": s.id})
+		case "ping":
+			pong := map[string]interface{}{"msg": "pong"}
+			if msg.ID != "" {
+				pong["id"] = msg.ID
+			}
+			s.send(pong)
+		case "sub":
+			s.handleSub(msg)
+		case "unsub":
+			s.handleUnsub(msg)
+		case "method":
+			// The handler may take some time, the next messages are read meanwhile (like Meteor does with this.unblock())
+			go s.handleMethod(msg)
+		}
+	}
+}
+
+// Send all documents of the collection and ready. Later changes are sent by the server.
+func (s *session) handleSub(msg clientMessage) {
+	s.server.mutex.Lock()
+	defer s.server.mutex.Unlock()
+
+	s.server.messages = append(s.server.messages, Message{Msg: "sub", Name: msg.Name, Params: msg.Params})
+
+	for id, fields := range s.server.collections[msg.Name] {
+		s.send(map[string]interface{}{"msg": "added", "collection": msg.Name, "id": id, "fields": fields})
+	}
+	s.subs[msg.ID] = msg.Name
+	s.send(map[string]interface{}{"msg": "ready", "subs": []string{msg.ID}})
+}
+
+// Stop the sub. The documents stay on the client, like with a second sub of the same collection.
+func (s *session) handleUnsub(msg clientMessage) {
+	s.server.mutex.Lock()
+	name := s.subs[msg.ID]
+	delete(s.subs, msg.ID)
+	s.server.messages = append(s.server.messages, Message{Msg: "unsub", Name: name})
+	s.server.mutex.Unlock()
+
+	s.send(map[string]interface{}{"msg": "nosub", "id": msg.ID})
+}
+
+// Answer the method with the result of its handler
+func (s *session) handleMethod(msg clientMessage) {
+	s.server.mutex.Lock()
+	s.server.messages = append(s.server.messages, Message{Msg: "method", Name: msg.Method, Params: msg.Params})
+	handler := s.server.methods[msg.Method]
+	s.server.mutex.Unlock()
+
+	var result interface{}
+	var err error
+	if handler != nil {
+		result, err = handler(msg.Params)
+	}
+
+	if err != nil {
+		s.send(map[string]interface{}{
+			"msg":   "result",
+			"id":    msg.ID,
+			"error": map[string]interface{}{"error": 500, "reason": err.Error(), "errorType": "Meteor.Error"},
+		})
+		return
+	}
+	s.send(map[string]interface{}{"msg": "result", "id": msg.ID, "result": result})
+}
+
+func (s *session) send(msg map[string]interface{}) {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	s.conn.WriteJSON(msg)
+}
+
+// Close the websocket and remove the session from the server
+func (s *session) close() {
+	s.conn.Close()
+
+	s.server.mutex.Lock()
+	defer s.server.mutex.Unlock()
+	for i, session := range s.server.sessions {
+		if session == s {
+			s.server.sessions = append(s.server.sessions[:i:i], s.server.sessions[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"reflect"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+type annotationsAddedListener func(annotations []bbb.Annotation)
+
+// OnAnnotationsAdded in order to receive the annotations drawn on the whiteboard.
+func (c *Client) OnAnnotationsAdded(listener annotationsAddedListener) error {
+	return c.addSubscribedListener("OnAnnotationsAdded", listener, func() error {
+		_, err := c.ddpSubscribe(bbb.StreamAnnotationsAddedSub, c.updateAnnotationsAdded)
+		return err
+	})
+}
+
+// informs all listeners with the added annotations.
+func (c *Client) updateAnnotationsAdded(collection string, operation string, id string, doc ddp.Update) {
+	if doc == nil {
+		return
+	}
+	// The added and removed events are in the same collection
+	msg := bbb.ConvertInToStreamMessage(doc)
+	if msg.EventName != "added" {
+		return
+	}
+	annotations := bbb.ConvertInToAnnotations(msg)
+
+	// Inform all listeners
+	for _, event := range c.getListeners("OnAnnotationsAdded") {
+
+		// call event(annotations)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Slice { //parameter 0 is of type slice ([]bbb.Annotation)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(annotations)})
+				}
+			}
+		}
+	}
+}
+
+type annotationsRemovedListener func(removed bbb.AnnotationsRemoved)
+
+// OnAnnotationsRemoved in order to receive which annotations were removed from the whiteboard.
+func (c *Client) OnAnnotationsRemoved(listener annotationsRemovedListener) error {
+	return c.addSubscribedListener("OnAnnotationsRemoved", listener, func() error {
+		_, err := c.ddpSubscribe(bbb.StreamAnnotationsRemovedSub, c.updateAnnotationsRemoved)
+		return err
+	})
+}
+
+// informs all listeners with the removed annotations.
+func (c *Client) updateAnnotationsRemoved(collection string, operation string, id string, doc ddp.Update) {
+	if doc == nil {
+		return
+	}
+	// The added and removed events are in the same collection
+	msg := bbb.ConvertInToStreamMessage(doc)
+	if msg.EventName != "removed" {
+		return
+	}
+	removed := bbb.ConvertInToAnnotationsRemoved(msg)
+
+	// Inform all listeners
+	for _, event := range c.getListeners("OnAnnotationsRemoved") {
+
+		// call event(removed)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Struct { //parameter 0 is of type struct (bbb.AnnotationsRemoved)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(removed)})
+				}
+			}
+		}
+	}
+}
+
+// OnSlideAnnotationsAdded in order to receive the annotations drawn on one whiteboard (the id of the slide).
+func (c *Client) OnSlideAnnotationsAdded(whiteboardID string, listener annotationsAddedListener) error {
+	return c.OnAnnotationsAdded(func(annotations []bbb.Annotation) {
+		onSlide := []bbb.Annotation{}
+		for _, annotation := range annotations {
+			if annotation.WbID == whiteboardID {
+				onSlide = append(onSlide, annotation)
+			}
+		}
+		if len(onSlide) > 0 {
+			listener(onSlide)
+		}
+	})
+}
+
+// OnSlideAnnotationsRemoved in order to receive which annotations were removed from one whiteboard (the id of the slide).
+func (c *Client) OnSlideAnnotationsRemoved(whiteboardID string, listener annotationsRemovedListener) error {
+	return c.OnAnnotationsRemoved(func(removed bbb.AnnotationsRemoved) {
+		if removed.WhiteboardID == whiteboardID {
+			listener(removed)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package bot
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// err = client.OnBreakoutStarted(func(breakout bbb.Breakout) {
+// 	// Join every breakout room with its own client
+// 	child, err := client.JoinBreakout(breakout.BreakoutId)
+// 	if err != nil {
+// 		fmt.Println(err)
+// 		return
+// 	}
+// 	child.OnGroupChatMsg(func(msg bbb.Message) {
+// 		fmt.Println(breakout.ShortName + ": " + msg.Message)
+// 	})
+// })
+// if err != nil {
+// 	panic(err)
+// }
+
+type breakoutListener func(breakout bbb.Breakout)
+
+// OnBreakoutStarted in order to receive a new breakout room.
+func (c *Client) OnBreakoutStarted(listener breakoutListener) error {
+	return c.addBreakoutListener("OnBreakoutStarted", listener)
+}
+
+// OnBreakoutUpdated in order to receive changes of a breakout room (e.g. joined users, time remaining).
+func (c *Client) OnBreakoutUpdated(listener breakoutListener) error {
+	return c.addBreakoutListener("OnBreakoutUpdated", listener)
+}
+
+// OnBreakoutEnded in order to receive when a breakout room has ended.
+func (c *Client) OnBreakoutEnded(listener breakoutListener) error {
+	return c.addBreakoutListener("OnBreakoutEnded", listener)
+}
+
+func (c *Client) addBreakoutListener(event string, listener breakoutListener) error {
+	if err := c.subscribeBreakouts(); err != nil {
+		return err
+	}
+
+	c.addListener(event, listener)
+
+	return nil
+}
+
+// All breakout events share one subscription of the breakouts collection.
+func (c *Client) subscribeBreakouts() error {
+	c.breakoutMutex.Lock()
+	defer c.breakoutMutex.Unlock()
+
+	if c.breakouts != nil {
+		return nil
+	}
+
+	// updateBreakouts waits for breakoutMutex, so it gets the updates after these documents
+	_, docs, err := c.ddpSubscribeDocs(bbb.BreakoutsSub, c.updateBreakouts)
+	if err != nil {
+		return err
+	}
+
+	// The breakout rooms that already exist are not started again
+	breakouts := make(map[string]bbb.Breakout)
+	for id, doc := range docs {
+		breakouts[id] = bbb.ConvertInToBreakout(doc)
+	}
+	c.breakouts = breakouts
+
+	return nil
+}
+
+// informs all listeners with the changes of the breakout rooms
+func (c *Client) updateBreakouts(collection string, operation string, id string, doc ddp.Update) {
+	c.breakoutMutex.Lock()
+	if c.breakouts == nil {
+		c.breakoutMutex.Unlock()
+		return
+	}
+
+	before, known := c.breakouts[id]
+
+	if operation == removedOperation {
+		delete(c.breakouts, id)
+		c.breakoutMutex.Unlock()
+		if known {
+			c.emitBreakoutEvent("OnBreakoutEnded", before)
+		}
+		return
+	}
+	if doc == nil {
+		c.breakoutMutex.Unlock()
+		return
+	}
+
+	breakout := bbb.ConvertInToBreakout(doc)
+	c.breakouts[id] = breakout
+	c.breakoutMutex.Unlock()
+
+	if !known {
+		c.emitBreakoutEvent("OnBreakoutStarted", breakout)
+	} else if operation != resyncOperation {
+		c.emitBreakoutEvent("OnBreakoutUpdated", breakout)
+	}
+}
+
+// informs all listeners of the event
+func (c *Client) emitBreakoutEvent(eventName string, breakout bbb.Breakout) {
+	for _, event := range c.getListeners(eventName) {
+
+		// call event(breakout)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Struct { //parameter 0 is of type struct (bbb.Breakout)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(breakout)})
+				}
+			}
+		}
+	}
+}
+
+// GetBreakouts returns all breakout rooms of the meeting
+func (c *Client) GetBreakouts() ([]bbb.Breakout, error) {
+	if err := c.subscribeBreakouts(); err != nil {
+		return nil, err
+	}
+
+	c.breakoutMutex.Lock()
+	defer c.breakoutMutex.Unlock()
+
+	breakouts := make([]bbb.Breakout, 0, len(c.breakouts))
+	for _, breakout := range c.breakouts {
+		breakouts = append(breakouts, breakout)
+	}
+	return breakouts, nil
+}
+
+// CreateBreakoutRooms creates the breakout rooms. The bot has to be a moderator.
+// Rooms without a name are called "Room <sequence>". The duration is rounded to minutes.
+func (c *Client) CreateBreakoutRooms(rooms []bbb.BreakoutRoomSend, duration time.Duration, record bool) error {
+	if len(rooms) < 2 {
+		return errors.New("could not create breakout rooms: at least 2 rooms are needed")
+	}
+	minutes := int(duration.Minutes())
+	if minutes < 1 {
+		return errors.New("could not create breakout rooms: the duration has to be at least 1 minute")
+	}
+
+	// The rooms of the caller are not changed
+	rooms = append([]bbb.BreakoutRoomSend{}, rooms...)
+	for i := range rooms {
+		if rooms[i].Sequence == 0 {
+			rooms[i].Sequence = i + 1
+		}
+		if rooms[i].Users == nil {
+			rooms[i].Users = []string{}
+		}
+		if rooms[i].Name == "" {
+			rooms[i].Name = "Room " + strconv.Itoa(rooms[i].Sequence)
+			rooms[i].IsDefaultName = true
+		}
+		if rooms[i].ShortName == "" {
+			rooms[i].ShortName = rooms[i].Name
+		}
+	}
+
+	// rooms, durationInMinutes, record, captureNotes, captureSlides, sendInviteToModerators
+	return c.moderatorCall("create breakout rooms", bbb.CreateBreakoutRoomCall, rooms, minutes, record, false, false, false)
+}
+
+// EndAllBreakouts ends all breakout rooms. The bot has to be a moderator.
+func (c *Client) EndAllBreakouts() error {
+	return c.moderatorCall("end breakout rooms", bbb.EndAllBreakoutsCall)
+}
+
+// GetBreakoutJoinURL requests the url the bot can use to join the breakout room (like the HTML5 client does).
+func (c *Client) GetBreakoutJoinURL(breakoutID string) (string, error) {
+	if err := c.subscribeBreakouts(); err != nil {
+		return "", err
+	}
+
+	_, err := c.ddpCall(bbb.RequestJoinURLCall, bbb.BreakoutJoinURLRequest{
+		BreakoutId: breakoutID,
+		UserId:     c.InternalUserID,
+	})
+	if err != nil {
+		return "", errors.New("could not request join url: " + err.Error())
+	}
+
+	// The url is added to the users of the breakout room
+	for i := 0; i < 100; i++ {
+		c.breakoutMutex.Lock()
+		for _, breakout := range c.breakouts {
+			if breakout.BreakoutId != breakoutID {
+				continue
+			}
+			for _, user := range breakout.Users {
+				if user.UserId == c.InternalUserID && user.RedirectToHtml5JoinURL != "" {
+					c.breakoutMutex.Unlock()
+					return user.RedirectToHtml5JoinURL, nil
+				}
+			}
+		}
+		c.breakoutMutex.Unlock()
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	return "", errors.New("could not get join url of breakout room " + breakoutID + ": timeout")
+}
+
+// JoinBreakout creates a new Client that joins the breakout room with the join url of the bot
+// (see GetBreakoutJoinURL). userName and moderator are given by the server (like for the users of the
+// HTML5 client). The new client has its own events and has to leave the breakout room by itself.
+// It is DISCONNECTED when the breakout room ends.
+func (c *Client) JoinBreakout(breakoutID string) (*Client, error) {
+	joinURL, err := c.GetBreakoutJoinURL(breakoutID)
+	if err != nil {
+		return nil, errors.New("could not join breakout room: " + err.Error())
+	}
+
+	child, err := NewClient(c.ClientURL, c.ClientWSURL, c.PadURL, c.PadWSURL, c.API.Url, c.API.Secret, c.WebRTCWSURL)
+	if err != nil {
+		return nil, errors.New("could not join breakout room: " + err.Error())
+	}
+	child.SetLogger(c.logger)
+
+	if err := child.joinWithURL(joinURL); err != nil {
+		return nil, errors.New("could not join breakout room: " + err.Error())
+	}
+
+	// The user of the new client has to be a breakout user of this meeting
+	state, err := child.GetMeetingState()
+	if err != nil {
+		child.Leave()
+		return nil, errors.New("could not join breakout room: " + err.Error())
+	}
+	user, _ := state.User(child.InternalUserID)
+	if !user.BreakoutProps.IsBreakoutUser || user.BreakoutProps.ParentID != c.InternalMeetingID {
+		child.Leave()
+		return nil, errors.New("could not join breakout room: the user " + child.InternalUserID + " is no breakout user of the meeting")
+	}
+
+	return child, nil
+}
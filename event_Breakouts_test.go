@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for the breakout events with a breakout room that exists before the listeners are added
+func TestBreakoutEvents(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("breakouts", "doc1", map[string]interface{}{"breakoutId": "breakout-1", "sequence": 1})
+	client := newTestClient(t, server)
+
+	received := make(chan string, 10)
+	listener := func(name string) breakoutListener {
+		return func(breakout bbb.Breakout) {
+			received <- name + " " + breakout.BreakoutId
+		}
+	}
+	if err := client.OnBreakoutStarted(listener("started")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.OnBreakoutEnded(listener("ended")); err != nil {
+		t.Fatal(err)
+	}
+
+	server.Add("breakouts", "doc2", map[string]interface{}{"breakoutId": "breakout-2", "sequence": 2})
+	server.Remove("breakouts", "doc1")
+
+	expected := map[string]bool{"started breakout-2": true, "ended breakout-1": true}
+	for range expected {
+		select {
+		case event := <-received:
+			if !expected[event] {
+				t.Errorf("breakout events FAILED: got %q", event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("breakout events FAILED: expected %v", expected)
+		}
+	}
+	select {
+	case event := <-received:
+		t.Errorf("breakout events FAILED: got %q, expected no more events", event)
+	case <-time.After(50 * time.Millisecond):
+		t.Logf("breakout events PASSED")
+	}
+}
+
+// Test for CreateBreakoutRooms (the rooms of the caller are not changed)
+func TestCreateBreakoutRooms(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("users", "doc1", map[string]interface{}{"userId": "w_bot", "role": bbb.RoleModerator})
+	client := newTestClient(t, server)
+
+	rooms := []bbb.BreakoutRoomSend{{Users: []string{"w_1"}}, {Name: "Second"}}
+	if err := client.CreateBreakoutRooms(rooms, 15*time.Minute, false); err != nil {
+		t.Fatalf("CreateBreakoutRooms() FAILED: %v", err)
+	}
+	if rooms[0].Name != "" || rooms[0].Sequence != 0 || rooms[1].Users != nil {
+		t.Errorf("CreateBreakoutRooms() FAILED: the rooms of the caller were changed to %+v", rooms)
+	}
+
+	for _, msg := range server.Messages() {
+		if msg.Msg != "method" || msg.Name != "createBreakoutRoom" {
+			continue
+		}
+		sent, _ := msg.Params[0].([]interface{})
+		if len(sent) != 2 || sent[0].(map[string]interface{})["name"] != "Room 1" || sent[1].(map[string]interface{})["sequence"] != float64(2) {
+			t.Errorf("CreateBreakoutRooms() FAILED: got rooms %v, expected Room 1 and Second (sequence 2)", sent)
+		} else {
+			t.Logf("CreateBreakoutRooms() PASSED")
+		}
+		return
+	}
+	t.Errorf("CreateBreakoutRooms() FAILED: the server got no createBreakoutRoom call")
+}
+
+// Test for JoinBreakout with the join url the server creates for the bot
+func TestJoinBreakout(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+
+	// The api redirects the join url to the HTML5 client with the session token, like bbb-web does
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bigbluebutton/api/join", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/html5client/join?sessionToken=token-1", http.StatusFound)
+	})
+	mux.HandleFunc("/html5client/join", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/bigbluebutton/api/enter", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sessionToken") != "token-1" {
+			w.Write([]byte(`{"response":{"returncode":"FAILED","messageKey":"missingSession","message":"Invalid session token"}}`))
+			return
+		}
+		w.Write([]byte(`{"response":{"returncode":"SUCCESS","meetingID":"breakout-1","externMeetingID":"meeting-1","internalUserID":"w_child","authToken":"auth-1","fullname":"bot"}}`))
+	})
+	apiServer := httptest.NewServer(mux)
+	defer apiServer.Close()
+
+	server.Add("breakouts", "doc1", map[string]interface{}{"breakoutId": "breakout-1", "sequence": 1})
+	server.Add("users", "doc2", map[string]interface{}{
+		"userId":        "w_child",
+		"breakoutProps": map[string]interface{}{"isBreakoutUser": true, "parentId": testInternalMeetingID},
+	})
+	server.HandleMethod("requestJoinURL", func(params []interface{}) (interface{}, error) {
+		server.Change("breakouts", "doc1", map[string]interface{}{"users": []interface{}{map[string]interface{}{
+			"userId":                 "w_bot",
+			"redirectToHtml5JoinURL": apiServer.URL + "/bigbluebutton/api/join?meetingID=breakout-1",
+		}}})
+		return nil, nil
+	})
+
+	client := newTestClient(t, server)
+	client.API.Url = apiServer.URL + "/bigbluebutton/api/"
+
+	child, err := client.JoinBreakout("breakout-1")
+	if err != nil {
+		t.Fatalf("JoinBreakout() FAILED: %v", err)
+	}
+	defer child.Leave()
+
+	if child.InternalMeetingID != "breakout-1" || child.InternalUserID != "w_child" || child.GetStatus() != CONNECTED {
+		t.Errorf("JoinBreakout() FAILED: got meeting %s, user %s and status %s, expected breakout-1, w_child and connected", child.InternalMeetingID, child.InternalUserID, child.GetStatus())
+	} else {
+		t.Logf("JoinBreakout() PASSED")
+	}
+}
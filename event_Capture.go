@@ -2,12 +2,12 @@ package bot
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 	"reflect"
 	"time"
 
 	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
 	pad "github.com/bigbluebutton-bot/bigbluebutton-bot/pad"
 
 	convert "github.com/benpate/convert"
@@ -219,26 +219,24 @@ func (c *Client) CreateCapture(short Language, external bool, host string, port
 	lang := c.LanguageShortToName(short)
 
 	//Subscribe to captions, pads and pads-sessions
-	//Subscribe to captions
-	if err := c.ddpSubscribe(bbb.CaptionsSub, nil); err != nil {
-		return nil, err
-	}
-	captionsCollection := c.ddpClient.CollectionByName("captions")
-	captionsCollection.AddUpdateListener(c.ddpEventHandler)
-
-	//Subscribe to pads
-	if err := c.ddpSubscribe(bbb.PadsSub, nil); err != nil {
-		return nil, err
-	}
-	padsCollection := c.ddpClient.CollectionByName("pads")
-	padsCollection.AddUpdateListener(c.ddpEventHandler)
-
-	//Subscribe to pads-sessions
-	if err := c.ddpSubscribe(bbb.PadsSessionsSub, nil); err != nil {
-		return nil, err
+	handles := []ddpHandle{}
+	for _, sub := range []bbb.SubType{bbb.CaptionsSub, bbb.PadsSub, bbb.PadsSessionsSub} {
+		handle, err := c.ddpSubscribe(sub, nil)
+		if err != nil {
+			c.unsubscribeCapture(handles)
+			return nil, err
+		}
+		handles = append(handles, handle)
 	}
 	padsSessionsCollection := c.ddpClient.CollectionByName("pads-sessions")
-	padsSessionsCollection.AddUpdateListener(c.ddpEventHandler)
+
+	// Unsubscribe again if the capture could not be created
+	created := false
+	defer func() {
+		if !created {
+			c.unsubscribeCapture(handles)
+		}
+	}()
 
 	//Create caption and add this bot as owner to it
 	_, err := c.ddpCall(bbb.CreateGroupCall, string(short), "captions", lang)
@@ -272,7 +270,7 @@ func (c *Client) CreateCapture(short Language, external bool, host string, port
 		padId = result.(string)
 		break
 	}
-	fmt.Println("padID: " + padId)
+	c.log().Debug("got pad id", "pad", padId, "language", string(short))
 
 	_, err = c.ddpCall(bbb.CreateSessionCall, string(short))
 	if err != nil {
@@ -312,14 +310,14 @@ func (c *Client) CreateCapture(short Language, external bool, host string, port
 		time.Sleep(100 * time.Millisecond)
 
 		if (getsessionIDtry % 10) == 9 {
-			fmt.Println("Retry to create and subscribe to pads-sessions")
+			c.log().Debug("retry to create and subscribe to pads-sessions", "pad", padId)
 			_, err = c.ddpCall(bbb.CreateSessionCall, string(short))
 			if err != nil {
-				fmt.Println("Failed to create pad session")
+				c.log().Warn("failed to create pad session", "pad", padId, "error", err)
 			}
 
-			if err := c.ddpSubscribe(bbb.PadsSessionsSub, nil); err != nil {
-				fmt.Println("Failed to subscribe to pads-sessions")
+			if err := c.ddpResubscribe(bbb.PadsSessionsSub); err != nil {
+				c.log().Warn("failed to subscribe to pads-sessions", "pad", padId, "error", err)
 			}
 		}
 
@@ -327,12 +325,14 @@ func (c *Client) CreateCapture(short Language, external bool, host string, port
 			return nil, errors.New("timeout to get sessionID")
 		}
 	}
-	fmt.Println("sessionID: " + sessionID)
+	c.log().Debug("got pad session", "pad", padId, "sessionID", sessionID)
 
 	capturePad := pad.NewPad(string(short), lang, c.PadURL, c.PadWSURL, c.SessionToken, padId, sessionID, c.SessionCookie, external, host, port)
+	capturePad.SetLogger(logger.With(c.logger, "meeting", c.ExternalMeetingID, "user", c.InternalUserID))
 	if err := capturePad.Connect(); err != nil {
 		return nil, err
 	}
+	created = true
 
 	// Add capturePad to the list of pads
 	c.padMutex.Lock()
@@ -349,6 +349,8 @@ func (c *Client) CreateCapture(short Language, external bool, host string, port
 			}
 		}
 		c.padMutex.Unlock()
+
+		c.unsubscribeCapture(handles)
 	})
 
 	return capturePad, nil
@@ -360,3 +362,10 @@ func (c *Client) GetCaptures() []*pad.Pad {
 
 	return c.captures
 }
+
+// Unsubscribe from the collections subscribed by CreateCapture
+func (c *Client) unsubscribeCapture(handles []ddpHandle) {
+	for i := len(handles) - 1; i >= 0; i-- {
+		c.ddpUnsubscribe(handles[i])
+	}
+}
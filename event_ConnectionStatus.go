@@ -1,19 +1,17 @@
 package bot
 
 import (
-	ddp "github.com/gopackage/ddp"
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
 	"reflect"
 )
 
 type statusListener func(StatusType)
 
 // OnStatus in order to receive status changes.
+// While the client is in a meeting, a lost connection is reported as RECONNECTING
+// until the session was resumed. Then CONNECTED is reported again.
 func (c *Client) OnStatus(listener statusListener) {
-	if c.events["OnStatus"] == nil {
-		c.ddpClient.AddStatusListener(c.ddpEventHandler)
-	}
-
-	c.events["OnStatus"] = append(c.events["OnStatus"], listener)
+	c.addListener("OnStatus", listener)
 }
 
 // Will be emited by ddpClient
@@ -36,16 +34,29 @@ func (e *ddpEventHandler) Status(status int) {
 		st = DISCONNECTED
 	}
 
+	// The ddpClient reconnects by itself. CONNECTED is reported after the session was resumed (see Connected).
+	if e.client.isSessionActive() {
+		if st == CONNECTED {
+			return
+		}
+		e.startResync()
+		st = RECONNECTING
+	}
+
 	e.client.updateStatus(st)
 }
 
 // informs all status listeners with the new client status.
 func (c *Client) updateStatus(status StatusType) {
-	if c.Status == status {
+	c.statusMutex.Lock()
+	if c.status == status {
+		c.statusMutex.Unlock()
 		return
 	}
-	c.Status = status
-	for _, event := range c.events["OnStatus"] {
+	c.status = status
+	c.statusMutex.Unlock()
+
+	for _, event := range c.getListeners("OnStatus") {
 
 		// call event(status)
 		f := reflect.TypeOf(event)
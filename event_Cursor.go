@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"errors"
+	"reflect"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+type cursorListener func(cursors []bbb.Cursor)
+
+// OnCursor in order to receive the cursor positions of the other users.
+func (c *Client) OnCursor(listener cursorListener) error {
+	return c.addSubscribedListener("OnCursor", listener, func() error {
+		_, err := c.ddpSubscribe(bbb.StreamCursorSub, c.updateCursor)
+		return err
+	})
+}
+
+// informs all listeners with the new cursor positions.
+func (c *Client) updateCursor(collection string, operation string, id string, doc ddp.Update) {
+	if doc == nil {
+		return
+	}
+	msg := bbb.ConvertInToStreamMessage(doc)
+	if msg.EventName != "message" {
+		return
+	}
+	cursors := bbb.ConvertInToCursors(msg)
+
+	// Inform all listeners
+	for _, event := range c.getListeners("OnCursor") {
+
+		// call event(cursors)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Slice { //parameter 0 is of type slice ([]bbb.Cursor)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(cursors)})
+				}
+			}
+		}
+	}
+}
+
+// Move the cursor of the bot on the whiteboard. x and y are in percent of the slide size.
+func (c *Client) SendCursorPosition(whiteboardID string, xPercent float64, yPercent float64) error {
+	cursor := bbb.CursorSend{
+		XPercent:     xPercent,
+		YPercent:     yPercent,
+		WhiteboardID: whiteboardID,
+	}
+
+	_, err := c.ddpCall(bbb.StreamCursorCall, "publish", cursor)
+	if err != nil {
+		return errors.New("could not send cursor position: " + err.Error())
+	}
+
+	return nil
+}
@@ -5,7 +5,7 @@ import (
 	"reflect"
 	"time"
 
-	ddp "github.com/gopackage/ddp"
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
 
 	"github.com/benpate/convert"
 	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
@@ -15,31 +15,26 @@ type groupChatMsgListener func(msg bbb.Message)
 
 // OnGroupChatMsg in order to receive GroupChatMsg changes.
 func (c *Client) OnGroupChatMsg(listener groupChatMsgListener) error {
-	if c.events["OnGroupChatMsg"] == nil {
-
-		if err := c.ddpSubscribe(bbb.GroupChatSub, nil); err != nil {
+	return c.addSubscribedListener("OnGroupChatMsg", listener, func() error {
+		if _, err := c.ddpSubscribe(bbb.GroupChatSub, nil); err != nil {
 			return err
 		}
 
-		if err := c.ddpSubscribe(bbb.GroupChatMsgSub, c.updateGroupChatMsg); err != nil {
-			return err
-		}
-	}
-
-	c.events["OnGroupChatMsg"] = append(c.events["OnGroupChatMsg"], listener)
-
-	return nil
+		_, err := c.ddpSubscribe(bbb.GroupChatMsgSub, c.updateGroupChatMsg)
+		return err
+	})
 }
 
 // informs all listeners with the new infos.
 func (c *Client) updateGroupChatMsg(collection string, operation string, id string, doc ddp.Update) {
-	if doc == nil || doc["id"] == nil {
+	// Messages sent again after a reconnect were already received
+	if operation == resyncOperation || doc == nil || doc["id"] == nil {
 		return
 	}
 	msg := bbb.ConvertInToMessage(doc)
 
 	// Inform all listeners
-	for _, event := range c.events["OnGroupChatMsg"] {
+	for _, event := range c.getListeners("OnGroupChatMsg") {
 
 		// call event(infos)
 		f := reflect.TypeOf(event)
@@ -75,3 +70,144 @@ func (c *Client) SendChatMsg(message string, chatId string) error {
 
 	return nil
 }
+
+// OnPublicChatMsg in order to receive the messages of users in the public chat.
+func (c *Client) OnPublicChatMsg(listener groupChatMsgListener) error {
+	return c.OnGroupChatMsg(func(msg bbb.Message) {
+		if msg.IsPublic() && !msg.IsSystem() {
+			listener(msg)
+		}
+	})
+}
+
+// OnPrivateChatMsg in order to receive the messages of the private chats of the bot.
+func (c *Client) OnPrivateChatMsg(listener groupChatMsgListener) error {
+	return c.OnGroupChatMsg(func(msg bbb.Message) {
+		if !msg.IsPublic() && !msg.IsSystem() {
+			listener(msg)
+		}
+	})
+}
+
+// OnSystemChatMsg in order to receive the messages of the server (e.g. poll results or "chat was cleared").
+func (c *Client) OnSystemChatMsg(listener groupChatMsgListener) error {
+	return c.OnGroupChatMsg(func(msg bbb.Message) {
+		if msg.IsSystem() {
+			listener(msg)
+		}
+	})
+}
+
+// GetPublicChatID returns the chat id of the public chat
+func (c *Client) GetPublicChatID() string {
+	return bbb.PublicChatID
+}
+
+// SendPublicChatMsg sends the message to the public chat
+func (c *Client) SendPublicChatMsg(message string) error {
+	return c.SendChatMsg(message, bbb.PublicChatID)
+}
+
+// SendPrivateChatMsg sends the message to the user (user id). The private chat is created if needed.
+func (c *Client) SendPrivateChatMsg(message string, userID string) error {
+	chatID, err := c.OpenPrivateChat(userID)
+	if err != nil {
+		return err
+	}
+	return c.SendChatMsg(message, chatID)
+}
+
+// OpenPrivateChat returns the chat id of the private chat with the user (user id).
+// The private chat is created if there is none yet.
+func (c *Client) OpenPrivateChat(userID string) (string, error) {
+	state, err := c.GetMeetingState()
+	if err != nil {
+		return "", err
+	}
+
+	if chatID, found := c.findPrivateChat(state, userID); found {
+		return chatID, nil
+	}
+
+	receiver, found := state.User(userID)
+	if !found {
+		return "", errors.New("could not create private chat: user " + userID + " not found")
+	}
+	// The HTML5 client gives the user document of the receiver
+	_, err = c.ddpCall(bbb.CreateGroupChatCall, receiver)
+	if err != nil {
+		return "", errors.New("could not create private chat: " + err.Error())
+	}
+
+	// The new chat is added to the group-chat collection
+	for i := 0; i < 100; i++ {
+		if chatID, found := c.findPrivateChat(state, userID); found {
+			return chatID, nil
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	return "", errors.New("could not create private chat: timeout")
+}
+
+// Returns the chat id of the private chat with the user
+func (c *Client) findPrivateChat(state *MeetingState, userID string) (string, bool) {
+	for _, chat := range state.Chats() {
+		if chat.Access == bbb.PrivateAccess && chat.HasParticipant(userID) && chat.HasParticipant(c.InternalUserID) {
+			return chat.ChatId, true
+		}
+	}
+	return "", false
+}
+
+// FetchChatHistory returns the messages of the chat which were sent before the bot joined (oldest first).
+func (c *Client) FetchChatHistory(chatID string) ([]bbb.Message, error) {
+	// The server returns 100 messages per page. The first page is 1.
+	const messagesPerPage = 100
+
+	messages := []bbb.Message{}
+	for page := 1; ; page++ {
+		result, err := c.ddpCall(bbb.FetchMessagePerPageCall, chatID, page)
+		if err != nil {
+			return messages, errors.New("could not fetch chat history: " + err.Error())
+		}
+
+		docs, ok := result.([]interface{})
+		if !ok {
+			return messages, nil
+		}
+		for _, doc := range docs {
+			if content, ok := doc.(map[string]interface{}); ok {
+				messages = append(messages, bbb.ConvertInToMessage(content))
+			}
+		}
+
+		if len(docs) < messagesPerPage {
+			return messages, nil
+		}
+	}
+}
+
+// StartTyping shows the other users that the bot is typing in the chat
+func (c *Client) StartTyping(chatID string) error {
+	_, err := c.ddpCall(bbb.StartUserTypingCall, chatID)
+	if err != nil {
+		return errors.New("could not start typing: " + err.Error())
+	}
+
+	return nil
+}
+
+// StopTyping removes the typing indicator of the bot
+func (c *Client) StopTyping() error {
+	_, err := c.ddpCall(bbb.StopUserTypingCall)
+	if err != nil {
+		return errors.New("could not stop typing: " + err.Error())
+	}
+
+	return nil
+}
+
+// ClearPublicChat removes all messages of the public chat. The bot has to be a moderator.
+func (c *Client) ClearPublicChat() error {
+	return c.moderatorCall("clear public chat", bbb.ClearPublicChatHistoryCall)
+}
@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"testing"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for OpenPrivateChat with a user that exists before the first call (the chat is created once)
+func TestOpenPrivateChat(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("users", "doc1", map[string]interface{}{"userId": "w_1", "name": "Alice"})
+	server.HandleMethod("createGroupChat", func(params []interface{}) (interface{}, error) {
+		server.Add("group-chat", "doc2", map[string]interface{}{
+			"chatId": "chat-1",
+			"access": bbb.PrivateAccess,
+			"users":  []interface{}{"w_bot", "w_1"},
+		})
+		return nil, nil
+	})
+	client := newTestClient(t, server)
+
+	for i := 0; i < 2; i++ {
+		chatID, err := client.OpenPrivateChat("w_1")
+		if err != nil || chatID != "chat-1" {
+			t.Errorf("OpenPrivateChat() %d FAILED: got %q (%v), expected chat-1", i, chatID, err)
+		}
+	}
+	if calls := countMessages(server, "method", "createGroupChat"); calls != 1 {
+		t.Errorf("OpenPrivateChat() FAILED: server got %d createGroupChat calls, expected 1", calls)
+	}
+
+	if _, err := client.OpenPrivateChat("w_2"); err == nil {
+		t.Errorf("OpenPrivateChat() FAILED: no error for an unknown user")
+	} else {
+		t.Logf("OpenPrivateChat() PASSED")
+	}
+}
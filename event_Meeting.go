@@ -0,0 +1,255 @@
+package bot
+
+import (
+	"reflect"
+	"time"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// client.OnMeetingEnded(func() {
+// 	fmt.Println("The meeting has ended")
+// })
+// client.OnEjected(func(reason string) {
+// 	fmt.Println("The bot was removed from the meeting: " + reason)
+// })
+// err = client.OnTimeRemaining(func(timeRemaining time.Duration) {
+// 	fmt.Println("The meeting ends in " + timeRemaining.String())
+// })
+// if err != nil {
+// 	panic(err)
+// }
+
+type timeRemainingListener func(timeRemaining time.Duration)
+type recordingStatusListener func(recording bbb.RecordMeeting)
+type meetingEndedListener func()
+type ejectedListener func(reason string)
+
+// OnTimeRemaining in order to receive the remaining time of the meeting.
+// The time is 0 if the meeting has no duration.
+func (c *Client) OnTimeRemaining(listener timeRemainingListener) error {
+	return c.addSubscribedListener("OnTimeRemaining", listener, func() error {
+		_, err := c.ddpSubscribe(bbb.MeetingTimeRemainingSub, c.updateTimeRemaining)
+		return err
+	})
+}
+
+// OnRecordingStatusChanged in order to receive when the recording of the meeting is started or stopped.
+// The current status is received after the listener was added.
+func (c *Client) OnRecordingStatusChanged(listener recordingStatusListener) error {
+	c.recordingMutex.Lock()
+	defer c.recordingMutex.Unlock()
+
+	if c.recordingStatus == nil {
+		_, docs, err := c.ddpSubscribeDocs(bbb.RecordMeetingsSub, c.updateRecordingStatus)
+		if err != nil {
+			return err
+		}
+
+		recordingStatus := make(map[string]bbb.RecordMeeting)
+		for id, doc := range docs {
+			recording := bbb.ConvertInToRecordMeeting(doc)
+			if recording.MeetingId != "" && recording.MeetingId != c.InternalMeetingID {
+				continue
+			}
+			recordingStatus[id] = recording
+		}
+		c.recordingStatus = recordingStatus
+	}
+
+	c.addListener("OnRecordingStatusChanged", listener)
+
+	// The current status. Later changes are given by updateRecordingStatus, which waits for recordingMutex.
+	for _, recording := range c.recordingStatus {
+		go listener(recording)
+	}
+
+	return nil
+}
+
+// OnMeetingEnded in order to receive when the meeting has ended.
+// The client is DISCONNECTED afterwards. The listener is kept if the client leaves the meeting.
+func (c *Client) OnMeetingEnded(listener meetingEndedListener) {
+	c.addListener("OnMeetingEnded", listener)
+}
+
+// OnEjected in order to receive when the bot was removed from the meeting by a moderator.
+// The client is DISCONNECTED afterwards. The listener is kept if the client leaves the meeting.
+func (c *Client) OnEjected(listener ejectedListener) {
+	c.addListener("OnEjected", listener)
+}
+
+// Watch the meeting and the own user, to know when the meeting ended or the bot was removed.
+// This is done while the client is in a meeting, even if there are no listeners.
+func (c *Client) watchSession() error {
+	meetings, docs, err := c.ddpSubscribeDocs(bbb.MeetingsSub, c.updateMeetingEnded)
+	if err != nil {
+		return err
+	}
+	// The meeting document is already known, so its removal can be detected
+	collection, _ := c.getSub(bbb.MeetingsSub)
+	for id, doc := range docs {
+		c.updateMeetingEnded(collection, addedOperation, id, doc)
+	}
+
+	if _, err := c.ddpSubscribe(bbb.UsersSub, c.updateEjected); err != nil {
+		c.ddpUnsubscribe(meetings)
+		return err
+	}
+	return nil
+}
+
+// informs all listeners with the remaining time of the meeting
+func (c *Client) updateTimeRemaining(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation || doc == nil {
+		return
+	}
+
+	timeRemaining := bbb.ConvertInToMeetingTimeRemaining(doc)
+	if timeRemaining.MeetingId != "" && timeRemaining.MeetingId != c.InternalMeetingID {
+		return
+	}
+	duration := time.Duration(timeRemaining.TimeRemaining) * time.Second
+
+	for _, event := range c.getListeners("OnTimeRemaining") {
+
+		// call event(duration)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Int64 { //parameter 0 is of type int64 (time.Duration)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(duration)})
+				}
+			}
+		}
+	}
+}
+
+// informs all listeners if the recording was started or stopped
+func (c *Client) updateRecordingStatus(collection string, operation string, id string, doc ddp.Update) {
+	c.recordingMutex.Lock()
+	defer c.recordingMutex.Unlock()
+	if c.recordingStatus == nil {
+		return
+	}
+	if operation == removedOperation || doc == nil {
+		delete(c.recordingStatus, id)
+		return
+	}
+
+	recording := bbb.ConvertInToRecordMeeting(doc)
+	if recording.MeetingId != "" && recording.MeetingId != c.InternalMeetingID {
+		return
+	}
+	before, known := c.recordingStatus[id]
+	c.recordingStatus[id] = recording
+
+	if known && before.Recording == recording.Recording {
+		return
+	}
+
+	for _, event := range c.getListeners("OnRecordingStatusChanged") {
+
+		// call event(recording)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Struct { //parameter 0 is of type struct (bbb.RecordMeeting)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(recording)})
+				}
+			}
+		}
+	}
+}
+
+// The meeting has ended if the meeting document is marked as ended or is removed
+func (c *Client) updateMeetingEnded(collection string, operation string, id string, doc ddp.Update) {
+	c.meetingEventsMutex.Lock()
+	if operation == removedOperation {
+		ended := id == c.meetingDocID
+		c.meetingEventsMutex.Unlock()
+		if ended {
+			c.meetingEnded()
+		}
+		return
+	}
+	if doc == nil {
+		c.meetingEventsMutex.Unlock()
+		return
+	}
+
+	meeting := bbb.ConvertInToMeeting(doc)
+	if meeting.MeetingId != c.InternalMeetingID {
+		c.meetingEventsMutex.Unlock()
+		return
+	}
+	c.meetingDocID = id
+	c.meetingEventsMutex.Unlock()
+
+	if meeting.MeetingEnded {
+		c.meetingEnded()
+	}
+}
+
+// The bot was removed if the own user is marked as ejected
+func (c *Client) updateEjected(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation || doc == nil {
+		return
+	}
+
+	user := bbb.ConvertInToUser(doc)
+	if user.UserID != c.InternalUserID || !user.Ejected {
+		return
+	}
+
+	// Only the first event ends the session (the meeting may end at the same time)
+	if !c.endSession() {
+		return
+	}
+	c.log().Info("the bot was removed from the meeting", "reason", user.EjectedReasonCode)
+
+	reason := user.EjectedReasonCode
+	if reason == "" {
+		reason = user.EjectedReason
+	}
+	for _, event := range c.getListeners("OnEjected") {
+
+		// call event(reason)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.String { //parameter 0 is of type string
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(reason)})
+				}
+			}
+		}
+	}
+
+	c.closeSession()
+}
+
+// informs all listeners that the meeting has ended and disconnects the client
+func (c *Client) meetingEnded() {
+	// Only the first event ends the session (the bot may be removed at the same time)
+	if !c.endSession() {
+		return
+	}
+	c.log().Info("the meeting has ended")
+
+	for _, event := range c.getListeners("OnMeetingEnded") {
+
+		// call event()
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 0 && f.NumOut() == 0 { //inbound parameters == 0, outbound parameters == 0
+				go reflect.ValueOf(event).Call([]reflect.Value{})
+			}
+		}
+	}
+
+	c.closeSession()
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for the end of the meeting and the ejection at the same time (the session is closed once)
+func TestSessionEndsOnce(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		server := ddptest.NewServer()
+		server.Add("meetings", "meeting1", map[string]interface{}{"meetingId": testInternalMeetingID})
+		server.Add("users", "user1", map[string]interface{}{"userId": "w_bot"})
+		client := newTestClient(t, server)
+
+		ended := make(chan string, 2)
+		client.OnMeetingEnded(func() { ended <- "OnMeetingEnded" })
+		client.OnEjected(func(reason string) { ended <- "OnEjected" })
+		if err := client.watchSession(); err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			server.Change("meetings", "meeting1", map[string]interface{}{"meetingEnded": true})
+		}()
+		go func() {
+			defer wg.Done()
+			server.Change("users", "user1", map[string]interface{}{"ejected": true, "ejectedReasonCode": "user_requested_eject_reason"})
+		}()
+		wg.Wait()
+
+		select {
+		case <-ended:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("session end %d FAILED: no event was received", i)
+		}
+		waitFor(t, "DISCONNECTED", func() bool { return client.GetStatus() == DISCONNECTED })
+		select {
+		case event := <-ended:
+			t.Fatalf("session end %d FAILED: got %s after the session was closed", i, event)
+		case <-time.After(20 * time.Millisecond):
+		}
+		server.Close()
+	}
+	t.Logf("session end PASSED")
+}
+
+// Test for a meeting document that exists before the session is watched and is removed later
+func TestMeetingRemoved(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("meetings", "meeting1", map[string]interface{}{"meetingId": testInternalMeetingID})
+	client := newTestClient(t, server)
+
+	ended := make(chan bool, 1)
+	client.OnMeetingEnded(func() { ended <- true })
+	if err := client.watchSession(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.Remove("meetings", "meeting1")
+	select {
+	case <-ended:
+		waitFor(t, "DISCONNECTED", func() bool { return client.GetStatus() == DISCONNECTED })
+		t.Logf("OnMeetingEnded() PASSED")
+	case <-time.After(5 * time.Second):
+		t.Errorf("OnMeetingEnded() FAILED: the removed meeting was not detected")
+	}
+}
+
+// Test for OnRecordingStatusChanged with a recording that was started before the listener was added
+func TestOnRecordingStatusChanged(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("record-meetings", "record1", map[string]interface{}{"meetingId": testInternalMeetingID, "recording": true})
+	client := newTestClient(t, server)
+
+	received := make(chan bbb.RecordMeeting, 2)
+	if err := client.OnRecordingStatusChanged(func(recording bbb.RecordMeeting) {
+		received <- recording
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(recording bool) {
+		t.Helper()
+		select {
+		case got := <-received:
+			if got.Recording != recording {
+				t.Errorf("OnRecordingStatusChanged() FAILED: got recording %t, expected %t", got.Recording, recording)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("OnRecordingStatusChanged() FAILED: got no status, expected recording %t", recording)
+		}
+	}
+
+	// the current status, then the change
+	expect(true)
+	server.Change("record-meetings", "record1", map[string]interface{}{"time": 10})
+	server.Change("record-meetings", "record1", map[string]interface{}{"recording": false})
+	expect(false)
+
+	select {
+	case got := <-received:
+		t.Errorf("OnRecordingStatusChanged() FAILED: got %+v, but the status did not change", got)
+	case <-time.After(50 * time.Millisecond):
+		t.Logf("OnRecordingStatusChanged() PASSED")
+	}
+}
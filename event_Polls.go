@@ -0,0 +1,277 @@
+package bot
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// err = client.OnPollStarted(func(poll bbb.Poll) {
+// 	fmt.Println("New poll: " + poll.Question)
+// 	client.AnswerPoll(poll.ID, poll.Answers[0].ID)
+// })
+// if err != nil {
+// 	panic(err)
+// }
+
+// polls keeps track of the last poll and if it was published
+type polls struct {
+	mu            sync.Mutex
+	lastPoll      bbb.Poll
+	publishedPoll map[string]bool // publishedPoll of the meetings by ddp id
+}
+
+type pollListener func(poll bbb.Poll)
+
+// OnPollStarted in order to receive a new poll the bot can answer (see AnswerPoll).
+func (c *Client) OnPollStarted(listener pollListener) error {
+	return c.addPollListener("OnPollStarted", listener)
+}
+
+// OnPollResultsUpdated in order to receive the votes of the current poll.
+// The results are only sent to the presenter and moderators.
+func (c *Client) OnPollResultsUpdated(listener pollListener) error {
+	return c.addPollListener("OnPollResultsUpdated", listener)
+}
+
+// OnPollPublished in order to receive when the presenter published the results of the poll.
+// The votes are only part of the poll if the bot could receive the results (see OnPollResultsUpdated).
+func (c *Client) OnPollPublished(listener pollListener) error {
+	return c.addPollListener("OnPollPublished", listener)
+}
+
+func (c *Client) addPollListener(event string, listener pollListener) error {
+	if err := c.subscribePolls(); err != nil {
+		return err
+	}
+
+	c.addListener(event, listener)
+
+	return nil
+}
+
+// All poll events share the subscriptions of polls, current-poll and meetings (for publishedPoll).
+func (c *Client) subscribePolls() error {
+	c.pollMutex.Lock()
+	defer c.pollMutex.Unlock()
+
+	if c.polls != nil {
+		return nil
+	}
+
+	// The updates wait for pollMutex (see getPolls), so they get the updates after these documents
+	pollsHandle, pollDocs, err := c.ddpSubscribeDocs(bbb.PollsSub, c.updatePolls)
+	if err != nil {
+		return err
+	}
+	currentPollHandle, currentPollDocs, err := c.ddpSubscribeDocs(bbb.CurrentPollSub, c.updateCurrentPoll)
+	if err != nil {
+		c.ddpUnsubscribe(pollsHandle)
+		return err
+	}
+	_, meetingDocs, err := c.ddpSubscribeDocs(bbb.MeetingsSub, c.updatePollPublished)
+	if err != nil {
+		c.ddpUnsubscribe(pollsHandle)
+		c.ddpUnsubscribe(currentPollHandle)
+		return err
+	}
+
+	// A poll that was published before is not published again
+	p := &polls{
+		publishedPoll: make(map[string]bool),
+	}
+	for id, doc := range meetingDocs {
+		p.publishedPoll[id] = bbb.ConvertInToMeeting(doc).PublishedPoll
+	}
+	// The running poll is published with its results, if they are known
+	for _, doc := range pollDocs {
+		p.lastPoll = bbb.ConvertInToPoll(doc)
+	}
+	for _, doc := range currentPollDocs {
+		p.lastPoll = bbb.ConvertInToPoll(doc)
+	}
+	c.polls = p
+
+	return nil
+}
+
+// informs all listeners of OnPollStarted about a new poll
+func (c *Client) updatePolls(collection string, operation string, id string, doc ddp.Update) {
+	p := c.getPolls()
+	if p == nil || doc == nil || operation == removedOperation {
+		return
+	}
+
+	poll := bbb.ConvertInToPoll(doc)
+	p.mu.Lock()
+	if p.lastPoll.ID != poll.ID {
+		p.lastPoll = poll
+	}
+	p.mu.Unlock()
+
+	if operation == addedOperation {
+		c.emitPollEvent("OnPollStarted", poll)
+	}
+}
+
+// informs all listeners of OnPollResultsUpdated with the votes
+func (c *Client) updateCurrentPoll(collection string, operation string, id string, doc ddp.Update) {
+	p := c.getPolls()
+	if p == nil || doc == nil || operation == removedOperation || operation == resyncOperation {
+		return
+	}
+
+	poll := bbb.ConvertInToPoll(doc)
+	p.mu.Lock()
+	p.lastPoll = poll
+	p.mu.Unlock()
+
+	c.emitPollEvent("OnPollResultsUpdated", poll)
+}
+
+// informs all listeners of OnPollPublished if publishedPoll of the meeting changed to true
+func (c *Client) updatePollPublished(collection string, operation string, id string, doc ddp.Update) {
+	p := c.getPolls()
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if operation == removedOperation || doc == nil {
+		delete(p.publishedPoll, id)
+		p.mu.Unlock()
+		return
+	}
+	meeting := bbb.ConvertInToMeeting(doc)
+	before := p.publishedPoll[id]
+	p.publishedPoll[id] = meeting.PublishedPoll
+	poll := p.lastPoll
+	p.mu.Unlock()
+
+	if meeting.MeetingId != c.InternalMeetingID || before || !meeting.PublishedPoll {
+		return
+	}
+	c.emitPollEvent("OnPollPublished", poll)
+}
+
+func (c *Client) getPolls() *polls {
+	c.pollMutex.Lock()
+	defer c.pollMutex.Unlock()
+	return c.polls
+}
+
+// informs all listeners of the event
+func (c *Client) emitPollEvent(eventName string, poll bbb.Poll) {
+	for _, event := range c.getListeners(eventName) {
+
+		// call event(poll)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Struct { //parameter 0 is of type struct (bbb.Poll)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(poll)})
+				}
+			}
+		}
+	}
+}
+
+//--------------------------------------------------
+// Attendee
+//--------------------------------------------------
+
+// AnswerPoll votes for the answers (bbb.PollAnswer.ID) of the poll.
+// More than one answer is only allowed if the poll IsMultipleResponse.
+func (c *Client) AnswerPoll(pollID string, answerIDs ...int) error {
+	if len(answerIDs) == 0 {
+		return errors.New("could not answer poll: no answer given")
+	}
+
+	_, err := c.ddpCall(bbb.PublishVoteCall, pollID, answerIDs)
+	if err != nil {
+		return errors.New("could not answer poll: " + err.Error())
+	}
+
+	return nil
+}
+
+// AnswerPollWithText answers a poll of the type bbb.PollResponse
+func (c *Client) AnswerPollWithText(pollID string, answer string) error {
+	_, err := c.ddpCall(bbb.PublishTypedVoteCall, pollID, answer)
+	if err != nil {
+		return errors.New("could not answer poll: " + err.Error())
+	}
+
+	return nil
+}
+
+//--------------------------------------------------
+// Presenter
+//--------------------------------------------------
+
+// StartPoll starts a poll with the answers of the poll type (yes/no, A-D, true/false, response, ...).
+// The bot has to be the presenter. Use StartCustomPoll for your own answers.
+func (c *Client) StartPoll(pollType bbb.PollType, question string, secretPoll bool, multipleResponse bool) error {
+	if pollType == bbb.PollCustom {
+		return errors.New("could not start poll: use StartCustomPoll for custom polls")
+	}
+	return c.startPoll(pollType, question, secretPoll, multipleResponse, []string{})
+}
+
+// StartCustomPoll starts a poll with the given answers. The bot has to be the presenter.
+func (c *Client) StartCustomPoll(question string, answers []string, secretPoll bool, multipleResponse bool) error {
+	if len(answers) == 0 {
+		return errors.New("could not start poll: no answers given")
+	}
+	return c.startPoll(bbb.PollCustom, question, secretPoll, multipleResponse, answers)
+}
+
+func (c *Client) startPoll(pollType bbb.PollType, question string, secretPoll bool, multipleResponse bool, answers []string) error {
+	_, err := c.ddpCall(bbb.StartPollCall, bbb.GetPollTypes(), pollType, c.pollRequestID(), secretPoll, question, multipleResponse, answers)
+	if err != nil {
+		return errors.New("could not start poll: " + err.Error())
+	}
+
+	return nil
+}
+
+// StopPoll stops the current poll without publishing the results
+func (c *Client) StopPoll() error {
+	_, err := c.ddpCall(bbb.StopPollCall)
+	if err != nil {
+		return errors.New("could not stop poll: " + err.Error())
+	}
+
+	return nil
+}
+
+// PublishPoll publishes the results of the current poll to the chat and the whiteboard
+func (c *Client) PublishPoll() error {
+	_, err := c.ddpCall(bbb.PublishPollCall)
+	if err != nil {
+		return errors.New("could not publish poll: " + err.Error())
+	}
+
+	return nil
+}
+
+// The HTML5 client starts a poll for the current slide, so the results can be drawn on it.
+// Without a known slide the poll is started for the public chat.
+func (c *Client) pollRequestID() string {
+	state, err := c.GetMeetingState()
+	if err != nil {
+		c.log().Warn("could not get the current slide for the poll", "error", err)
+		return "public"
+	}
+
+	if slide, found := state.CurrentSlide(); found {
+		return slide.ID
+	}
+	return "public"
+}
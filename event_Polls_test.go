@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for the poll events with a poll that is running before the listeners are added
+func TestPollEvents(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("meetings", "meeting1", map[string]interface{}{"meetingId": testInternalMeetingID})
+	server.Add("polls", "poll1", map[string]interface{}{"id": "poll-1", "question": "first"})
+	server.Add("current-poll", "current1", map[string]interface{}{"id": "poll-1", "question": "first", "numRespondents": 3})
+	client := newTestClient(t, server)
+
+	started := make(chan bbb.Poll, 10)
+	published := make(chan bbb.Poll, 10)
+	if err := client.OnPollStarted(func(poll bbb.Poll) { started <- poll }); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.OnPollPublished(func(poll bbb.Poll) { published <- poll }); err != nil {
+		t.Fatal(err)
+	}
+
+	// The running poll is not started again, but published with its results
+	server.Change("meetings", "meeting1", map[string]interface{}{"publishedPoll": true})
+	select {
+	case poll := <-published:
+		if poll.ID != "poll-1" || poll.NumRespondents != 3 {
+			t.Errorf("OnPollPublished() FAILED: got %s with %d respondents, expected poll-1 with 3", poll.ID, poll.NumRespondents)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("OnPollPublished() FAILED: the poll was not published")
+	}
+
+	server.Change("meetings", "meeting1", map[string]interface{}{"publishedPoll": false})
+	server.Add("polls", "poll2", map[string]interface{}{"id": "poll-2", "question": "second"})
+	select {
+	case poll := <-started:
+		if poll.ID != "poll-2" {
+			t.Errorf("OnPollStarted() FAILED: got %s, expected poll-2", poll.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("OnPollStarted() FAILED: the new poll was not started")
+	}
+
+	select {
+	case poll := <-started:
+		t.Errorf("OnPollStarted() FAILED: got %s again", poll.ID)
+	case poll := <-published:
+		t.Errorf("OnPollPublished() FAILED: got %s again", poll.ID)
+	case <-time.After(50 * time.Millisecond):
+		t.Logf("poll events PASSED")
+	}
+}
+
+// Test for StartPoll on the current slide, which is shown before the first call
+func TestStartPoll(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("presentations", "doc1", map[string]interface{}{"id": "presentation-a", "current": true})
+	server.Add("slides", "doc2", map[string]interface{}{"id": "slide-a1", "presentationId": "presentation-a", "num": 1, "current": true})
+	client := newTestClient(t, server)
+
+	if err := client.StartPoll(bbb.PollYesNo, "yes or no?", false, false); err != nil {
+		t.Fatalf("StartPoll() FAILED: %v", err)
+	}
+
+	for _, msg := range server.Messages() {
+		if msg.Msg == "method" && msg.Name == "startPoll" {
+			if len(msg.Params) < 3 || msg.Params[2] != "slide-a1" {
+				t.Errorf("StartPoll() FAILED: got params %v, expected the request id slide-a1", msg.Params)
+			} else {
+				t.Logf("StartPoll() PASSED")
+			}
+			return
+		}
+	}
+	t.Errorf("StartPoll() FAILED: the server got no startPoll call")
+}
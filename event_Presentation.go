@@ -0,0 +1,316 @@
+package bot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// err = client.OnSlideChanged(func(slide bbb.Slide) {
+// 	fmt.Println("Slide " + strconv.Itoa(slide.Num) + ": " + slide.Content)
+// })
+// if err != nil {
+// 	panic(err)
+// }
+
+type slideChangedListener func(slide bbb.Slide)
+
+// OnSlideChanged in order to receive the new slide if the presenter changes the slide.
+func (c *Client) OnSlideChanged(listener slideChangedListener) error {
+	c.presentationMutex.Lock()
+	defer c.presentationMutex.Unlock()
+
+	if c.currentSlides == nil {
+		_, docs, err := c.ddpSubscribeDocs(bbb.SlidesSub, c.updateSlideChanged)
+		if err != nil {
+			return err
+		}
+
+		// The slides that are already shown are not changed
+		currentSlides := make(map[string]bool)
+		for id, doc := range docs {
+			currentSlides[id] = bbb.ConvertInToSlide(doc).Current
+		}
+		c.currentSlides = currentSlides
+	}
+
+	c.addListener("OnSlideChanged", listener)
+
+	return nil
+}
+
+// informs all listeners if a slide becomes the current slide
+func (c *Client) updateSlideChanged(collection string, operation string, id string, doc ddp.Update) {
+	c.presentationMutex.Lock()
+	if c.currentSlides == nil {
+		c.presentationMutex.Unlock()
+		return
+	}
+	if operation == removedOperation || doc == nil {
+		delete(c.currentSlides, id)
+		c.presentationMutex.Unlock()
+		return
+	}
+
+	slide := bbb.ConvertInToSlide(doc)
+	before := c.currentSlides[id]
+	c.currentSlides[id] = slide.Current
+	listeners := c.getListeners("OnSlideChanged")
+	c.presentationMutex.Unlock()
+
+	if before || !slide.Current {
+		return
+	}
+
+	for _, event := range listeners {
+
+		// call event(slide)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Struct { //parameter 0 is of type struct (bbb.Slide)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(slide)})
+				}
+			}
+		}
+	}
+}
+
+// GetPresentations returns all presentations of the meeting
+func (c *Client) GetPresentations() ([]bbb.Presentation, error) {
+	state, err := c.GetMeetingState()
+	if err != nil {
+		return nil, err
+	}
+	return state.Presentations(), nil
+}
+
+// GetSlides returns all slides of the presentation sorted by their number.
+// The slides contain the text (Content) and the urls of the SVG and PNG images.
+func (c *Client) GetSlides(presentationID string) ([]bbb.Slide, error) {
+	state, err := c.GetMeetingState()
+	if err != nil {
+		return nil, err
+	}
+	slides := state.Slides(presentationID)
+	sort.Slice(slides, func(i, j int) bool {
+		return slides[i].Num < slides[j].Num
+	})
+	return slides, nil
+}
+
+// GetCurrentSlide returns the slide which is shown right now
+func (c *Client) GetCurrentSlide() (bbb.Slide, error) {
+	state, err := c.GetMeetingState()
+	if err != nil {
+		return bbb.Slide{}, err
+	}
+	slide, found := state.CurrentSlide()
+	if !found {
+		return bbb.Slide{}, errors.New("there is no current slide")
+	}
+	return slide, nil
+}
+
+// GetSlidePosition returns the visible part of the slide
+func (c *Client) GetSlidePosition(slideID string) (bbb.SlidePosition, error) {
+	if err := c.ddpSubscribeOnce(bbb.SlidePositionsSub); err != nil {
+		return bbb.SlidePosition{}, err
+	}
+
+	for _, doc := range c.ddpClient.CollectionByName("slide-positions").FindAll() {
+		position := bbb.ConvertInToSlidePosition(doc)
+		if position.ID == slideID {
+			return position, nil
+		}
+	}
+	return bbb.SlidePosition{}, errors.New("could not find the position of slide " + slideID)
+}
+
+//--------------------------------------------------
+// Presenter
+//--------------------------------------------------
+
+// IsPresenter returns true if the bot is the presenter of the meeting
+func (c *Client) IsPresenter() bool {
+	state, err := c.GetMeetingState()
+	if err != nil {
+		return false
+	}
+	user, _ := state.User(c.InternalUserID)
+	return user.Presenter
+}
+
+// calls the method as presenter
+func (c *Client) presenterCall(action string, method bbb.CallType, params ...interface{}) error {
+	if !c.IsPresenter() {
+		return errors.New("could not " + action + ": the bot is not the presenter")
+	}
+
+	_, err := c.ddpCall(method, params...)
+	if err != nil {
+		return errors.New("could not " + action + ": " + err.Error())
+	}
+
+	return nil
+}
+
+// SwitchSlide shows the slide with the number (starting with 1) of the current presentation
+func (c *Client) SwitchSlide(slideNumber int) error {
+	if slideNumber < 1 {
+		return errors.New("could not switch slide: the first slide has the number 1")
+	}
+	return c.presenterCall("switch slide", bbb.SwitchSlideCall, slideNumber, bbb.DefaultPresentationPod)
+}
+
+// ZoomSlide shows a part of the slide. widthRatio and heightRatio are in percent (100 shows the whole slide),
+// x and y move the visible part.
+func (c *Client) ZoomSlide(slideNumber int, widthRatio float64, heightRatio float64, x float64, y float64) error {
+	return c.presenterCall("zoom slide", bbb.ZoomSlideCall, slideNumber, bbb.DefaultPresentationPod, widthRatio, heightRatio, x, y)
+}
+
+// SetPresentation shows the presentation
+func (c *Client) SetPresentation(presentationID string) error {
+	return c.presenterCall("set presentation", bbb.SetPresentationCall, presentationID, bbb.DefaultPresentationPod)
+}
+
+// RemovePresentation removes the presentation. Only presentations that are Removable can be removed.
+func (c *Client) RemovePresentation(presentationID string) error {
+	return c.presenterCall("remove presentation", bbb.RemovePresentationCall, presentationID, bbb.DefaultPresentationPod)
+}
+
+// UploadPresentation uploads a document (pdf, office document or image) to the presentation upload endpoint
+// of the BBB-server. The server converts it and adds it to the presentations. The bot has to be the presenter.
+// If current is true, the presentation is shown after the conversion.
+func (c *Client) UploadPresentation(filename string, file io.Reader, current bool, downloadable bool) error {
+	if !c.IsPresenter() {
+		return errors.New("could not upload presentation: the bot is not the presenter")
+	}
+
+	// The upload is authorized by a token, which is requested for the temporary id of the presentation
+	temporaryPresentationID, err := randomID()
+	if err != nil {
+		return errors.New("could not upload presentation: " + err.Error())
+	}
+	token, err := c.requestPresentationUploadToken(filename, temporaryPresentationID)
+	if err != nil {
+		return errors.New("could not upload presentation: " + err.Error())
+	}
+
+	// multipart form like the HTML5 client sends it
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	fields := [][2]string{
+		{"conference", c.InternalMeetingID},
+		{"room", c.InternalMeetingID},
+		{"temporaryPresentationId", temporaryPresentationID},
+		{"pod_id", bbb.DefaultPresentationPod},
+		{"is_downloadable", strconv.FormatBool(downloadable)},
+		{"current", strconv.FormatBool(current)},
+		{"presentation_name", filename},
+	}
+	for _, field := range fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return errors.New("could not upload presentation: " + err.Error())
+		}
+	}
+	part, err := writer.CreateFormFile("fileUpload", filename)
+	if err != nil {
+		return errors.New("could not upload presentation: " + err.Error())
+	}
+	if _, err = io.Copy(part, file); err != nil {
+		return errors.New("could not upload presentation: " + err.Error())
+	}
+	if err = writer.Close(); err != nil {
+		return errors.New("could not upload presentation: " + err.Error())
+	}
+
+	// https://example.com/bigbluebutton/api/ -> https://example.com/bigbluebutton/presentation/<token>/upload
+	uploadURL := strings.TrimSuffix(c.API.Url, "api/") + "presentation/" + url.PathEscape(token) + "/upload"
+
+	req, err := http.NewRequest("POST", uploadURL, body)
+	if err != nil {
+		return errors.New("could not upload presentation: " + err.Error())
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	for _, cookie := range c.SessionCookie {
+		req.AddCookie(cookie)
+	}
+
+	client := new(http.Client)
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.New("could not upload presentation: " + err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return errors.New("could not upload presentation: server returned: " + resp.Status)
+	}
+
+	return nil
+}
+
+// How long the server has to create the upload token
+const presentationUploadTokenTimeout = 5 * time.Second
+
+// Request the token for the upload of a presentation and wait until the server added it to presentation-upload-token
+func (c *Client) requestPresentationUploadToken(filename string, temporaryPresentationID string) (string, error) {
+	// The sub only contains the token of this upload
+	subname, _ := c.getSub(bbb.PresentationUploadTokenSub)
+	args := []interface{}{bbb.DefaultPresentationPod, filename, temporaryPresentationID}
+	id, _, err := c.ddpSendSub(subname, args)
+	if err != nil {
+		return "", err
+	}
+	defer c.ddpSendUnsub(&ddpSubscription{name: subname, args: args, id: id})
+
+	if _, err := c.ddpCall(bbb.RequestPresentationUploadTokenCall, args...); err != nil {
+		return "", err
+	}
+
+	collection := c.ddpClient.CollectionByName(subname)
+	deadline := time.Now().Add(presentationUploadTokenTimeout)
+	for time.Now().Before(deadline) {
+		for _, doc := range collection.FindAll() {
+			// failed is added as soon as bbb-web answered the request
+			if _, answered := doc["failed"]; !answered {
+				continue
+			}
+			token := bbb.ConvertInToPresentationUploadToken(doc)
+			if token.TemporaryPresentationId != temporaryPresentationID || token.Used {
+				continue
+			}
+			if token.Failed {
+				return "", errors.New("the server did not allow the upload")
+			}
+			return token.AuthzToken, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return "", errors.New("the server did not create an upload token")
+}
+
+// Returns a random id with 20 characters (like the ids of the HTML5 client)
+func randomID() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
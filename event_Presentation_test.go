@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for the presentation getters and SwitchSlide with documents that exist before the first call
+func TestPresentationExistingDocs(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("users", "doc1", map[string]interface{}{"userId": "w_bot", "intId": "w_bot", "name": "bot", "presenter": true})
+	server.Add("presentations", "doc2", map[string]interface{}{"id": "presentation-a", "name": "a.pdf", "current": true})
+	server.Add("presentations", "doc3", map[string]interface{}{"id": "presentation-b", "name": "b.pdf"})
+	server.Add("slides", "doc4", map[string]interface{}{"id": "slide-a2", "presentationId": "presentation-a", "num": 2, "current": true})
+	server.Add("slides", "doc5", map[string]interface{}{"id": "slide-a1", "presentationId": "presentation-a", "num": 1})
+	server.Add("slides", "doc6", map[string]interface{}{"id": "slide-b1", "presentationId": "presentation-b", "num": 1, "current": true})
+	server.Add("slide-positions", "doc7", map[string]interface{}{"id": "slide-a2", "presentationId": "presentation-a", "width": 1600, "height": 900})
+
+	client := newTestClient(t, server)
+
+	presentations, err := client.GetPresentations()
+	if err != nil || len(presentations) != 2 {
+		t.Errorf("GetPresentations() FAILED: got %d presentations (%v), expected 2", len(presentations), err)
+	}
+
+	slides, err := client.GetSlides("presentation-a")
+	if err != nil || len(slides) != 2 || slides[0].ID != "slide-a1" || slides[1].ID != "slide-a2" {
+		t.Errorf("GetSlides() FAILED: got %+v (%v), expected slide-a1 and slide-a2", slides, err)
+	}
+
+	slide, err := client.GetCurrentSlide()
+	if err != nil || slide.ID != "slide-a2" {
+		t.Errorf("GetCurrentSlide() FAILED: got %s (%v), expected slide-a2", slide.ID, err)
+	}
+
+	position, err := client.GetSlidePosition("slide-a2")
+	if err != nil || position.Width != 1600 || position.Height != 900 {
+		t.Errorf("GetSlidePosition() FAILED: got %+v (%v), expected 1600x900", position, err)
+	}
+
+	if err := client.SwitchSlide(1); err != nil {
+		t.Errorf("SwitchSlide() FAILED: %v", err)
+	}
+	if err := client.ZoomSlide(1, 50, 50, 10, 20); err != nil {
+		t.Errorf("ZoomSlide() FAILED: %v", err)
+	}
+
+	calls := [][]interface{}{}
+	for _, msg := range server.Messages() {
+		if msg.Msg == "method" && (msg.Name == "switchSlide" || msg.Name == "zoomSlide") {
+			calls = append(calls, msg.Params)
+		}
+	}
+	expected := [][]interface{}{
+		{float64(1), bbb.DefaultPresentationPod},
+		{float64(1), bbb.DefaultPresentationPod, float64(50), float64(50), float64(10), float64(20)},
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("SwitchSlide() and ZoomSlide() FAILED: server got %v, expected %v", calls, expected)
+	} else {
+		t.Logf("presentation getters PASSED")
+	}
+}
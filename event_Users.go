@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"reflect"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// err = client.OnUserJoined(func(event bot.UserEvent) {
+// 	fmt.Println(event.After.Name + " joined the meeting")
+// })
+// if err != nil {
+// 	panic(err)
+// }
+
+type UserChangeType string
+
+const (
+	UserRoleChanged      UserChangeType = "role"
+	UserPresenterChanged UserChangeType = "presenter"
+	UserLockChanged      UserChangeType = "locked"
+	UserEmojiChanged     UserChangeType = "emoji"
+	UserRaiseHandChanged UserChangeType = "raiseHand"
+)
+
+// UserEvent is given to the OnUserJoined, OnUserLeft and OnUserChanged listeners.
+// Before is empty for joined users, After is the last known state for users that left.
+type UserEvent struct {
+	Before  bbb.User
+	After   bbb.User
+	Changes []UserChangeType // only for OnUserChanged
+}
+
+type userEventListener func(event UserEvent)
+
+// OnUserJoined in order to receive the users joining the meeting.
+// Users that are already in the meeting when the listener is added are not reported.
+func (c *Client) OnUserJoined(listener userEventListener) error {
+	return c.addUserEventListener("OnUserJoined", listener)
+}
+
+// OnUserLeft in order to receive the users leaving the meeting.
+func (c *Client) OnUserLeft(listener userEventListener) error {
+	return c.addUserEventListener("OnUserLeft", listener)
+}
+
+// OnUserChanged in order to receive role, presenter, lock, emoji and raise hand changes of users.
+func (c *Client) OnUserChanged(listener userEventListener) error {
+	return c.addUserEventListener("OnUserChanged", listener)
+}
+
+// All user events share one subscription of the users collection
+func (c *Client) addUserEventListener(event string, listener userEventListener) error {
+	c.userEventsMutex.Lock()
+	defer c.userEventsMutex.Unlock()
+
+	if c.userEvents == nil {
+		_, docs, err := c.ddpSubscribeDocs(bbb.UsersSub, c.updateUserEvents)
+		if err != nil {
+			return err
+		}
+
+		// The users already in the meeting are known before the listeners are informed.
+		// updateUserEvents waits for userEventsMutex, so it gets the updates after these documents.
+		c.userEvents = make(map[string]bbb.User)
+		for id, doc := range docs {
+			c.userEvents[id] = bbb.ConvertInToUser(doc)
+		}
+	}
+
+	c.addListener(event, listener)
+
+	return nil
+}
+
+// informs all listeners with the changes of the users.
+func (c *Client) updateUserEvents(collection string, operation string, id string, doc ddp.Update) {
+	c.userEventsMutex.Lock()
+	defer c.userEventsMutex.Unlock()
+
+	if c.userEvents == nil {
+		return
+	}
+
+	before, known := c.userEvents[id]
+
+	if operation == removedOperation {
+		delete(c.userEvents, id)
+		if known && !hasLeft(before) {
+			c.emitUserEvent("OnUserLeft", UserEvent{Before: before, After: before})
+		}
+		return
+	}
+	if doc == nil {
+		return
+	}
+
+	after := bbb.ConvertInToUser(doc)
+	c.userEvents[id] = after
+
+	switch {
+	case (!known || hasLeft(before)) && !hasLeft(after):
+		c.emitUserEvent("OnUserJoined", UserEvent{Before: before, After: after})
+	case known && !hasLeft(before) && hasLeft(after):
+		c.emitUserEvent("OnUserLeft", UserEvent{Before: before, After: after})
+	case known:
+		if changes := diffUser(before, after); len(changes) > 0 {
+			c.emitUserEvent("OnUserChanged", UserEvent{Before: before, After: after, Changes: changes})
+		}
+	}
+}
+
+func hasLeft(user bbb.User) bool {
+	return user.Left || user.LoggedOut
+}
+
+// Returns all changes between before and after that are reported by OnUserChanged
+func diffUser(before bbb.User, after bbb.User) []UserChangeType {
+	changes := make([]UserChangeType, 0)
+	if before.Role != after.Role {
+		changes = append(changes, UserRoleChanged)
+	}
+	if before.Presenter != after.Presenter {
+		changes = append(changes, UserPresenterChanged)
+	}
+	if before.Locked != after.Locked {
+		changes = append(changes, UserLockChanged)
+	}
+	if before.Emoji != after.Emoji {
+		changes = append(changes, UserEmojiChanged)
+	}
+	if before.RaiseHand != after.RaiseHand || (before.Emoji == "raiseHand") != (after.Emoji == "raiseHand") {
+		changes = append(changes, UserRaiseHandChanged)
+	}
+	return changes
+}
+
+// informs all listeners of the event
+func (c *Client) emitUserEvent(eventName string, userEvent UserEvent) {
+	for _, event := range c.getListeners(eventName) {
+
+		// call event(userEvent)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Struct { //parameter 0 is of type struct (UserEvent)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(userEvent)})
+				}
+			}
+		}
+	}
+}
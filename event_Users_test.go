@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for the user events with a user that is in the meeting before the listeners are added
+func TestUserEvents(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("users", "doc1", map[string]interface{}{"userId": "w_1", "name": "Alice", "role": bbb.RoleViewer})
+	client := newTestClient(t, server)
+
+	received := make(chan string, 20)
+	listener := func(name string) userEventListener {
+		return func(event UserEvent) {
+			changes := ""
+			for _, change := range event.Changes {
+				changes += " " + string(change)
+			}
+			received <- name + " " + event.After.UserID + changes
+		}
+	}
+	if err := client.OnUserJoined(listener("joined")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.OnUserLeft(listener("left")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.OnUserChanged(listener("changed")); err != nil {
+		t.Fatal(err)
+	}
+
+	server.Add("users", "doc2", map[string]interface{}{"userId": "w_2", "name": "Bob"})
+	server.Change("users", "doc1", map[string]interface{}{"role": bbb.RoleModerator})
+	server.Change("users", "doc2", map[string]interface{}{"presenter": true})
+	server.Change("users", "doc2", map[string]interface{}{"presenter": false, "emoji": "raiseHand"})
+	server.Change("users", "doc2", map[string]interface{}{"loggedOut": true})
+	server.Remove("users", "doc1")
+
+	// The listeners are called in their own goroutines, so only the events are compared
+	expected := []string{
+		"changed w_1 role",
+		"changed w_2 presenter",
+		"changed w_2 presenter emoji raiseHand",
+		"joined w_2",
+		"left w_1",
+		"left w_2",
+	}
+	got := []string{}
+	for len(got) < len(expected) {
+		select {
+		case event := <-received:
+			got = append(got, event)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("user events FAILED: got %v, expected %v", got, expected)
+		}
+	}
+	select {
+	case event := <-received:
+		got = append(got, event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("user events FAILED: got %v, expected %v", got, expected)
+	} else {
+		t.Logf("user events PASSED")
+	}
+}
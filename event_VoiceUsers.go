@@ -0,0 +1,236 @@
+package bot
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// err = client.OnTalkingStopped(func(event bot.VoiceEvent) {
+// 	fmt.Println(event.User.Name + " talked for " + event.Duration.String())
+// })
+// if err != nil {
+// 	panic(err)
+// }
+
+// VoiceEvent is given to the OnTalkingStarted, OnTalkingStopped and OnMuteChanged listeners.
+type VoiceEvent struct {
+	Time      time.Time     // when the change was received
+	VoiceUser bbb.VoiceUser // state after the change
+	User      bbb.User      // user of the voice user (empty if the user is unknown, e.g. dial-in)
+	Duration  time.Duration // only for OnTalkingStopped: how long the user talked
+}
+
+// voiceActivity keeps track of the voice users and how long they talked
+type voiceActivity struct {
+	mu           sync.Mutex
+	voiceUsers   map[string]bbb.VoiceUser // last known state by ddp id
+	talkingSince map[string]time.Time     // by user id (intId)
+	talkTime     map[string]time.Duration // by user id (intId)
+}
+
+type voiceEventListener func(event VoiceEvent)
+
+// OnTalkingStarted in order to receive when a user starts talking.
+func (c *Client) OnTalkingStarted(listener voiceEventListener) error {
+	return c.addVoiceEventListener("OnTalkingStarted", listener)
+}
+
+// OnTalkingStopped in order to receive when a user stops talking.
+func (c *Client) OnTalkingStopped(listener voiceEventListener) error {
+	return c.addVoiceEventListener("OnTalkingStopped", listener)
+}
+
+// OnMuteChanged in order to receive when a user mutes or unmutes the microphone.
+func (c *Client) OnMuteChanged(listener voiceEventListener) error {
+	return c.addVoiceEventListener("OnMuteChanged", listener)
+}
+
+// GetTalkTimes returns how long each user talked (by user id). Talking is counted from the first
+// call of GetTalkTimes or of one of the voice event listeners. The current talk is included.
+func (c *Client) GetTalkTimes() (map[string]time.Duration, error) {
+	if err := c.subscribeVoiceActivity(); err != nil {
+		return nil, err
+	}
+
+	c.voiceMutex.Lock()
+	va := c.voiceActivity
+	c.voiceMutex.Unlock()
+	if va == nil {
+		return map[string]time.Duration{}, nil
+	}
+
+	va.mu.Lock()
+	defer va.mu.Unlock()
+
+	now := time.Now()
+	talkTimes := make(map[string]time.Duration, len(va.talkTime))
+	for userID, duration := range va.talkTime {
+		talkTimes[userID] = duration
+	}
+	for userID, since := range va.talkingSince {
+		talkTimes[userID] += now.Sub(since)
+	}
+	return talkTimes, nil
+}
+
+// ResetTalkTimes sets the talk time of all users to 0
+func (c *Client) ResetTalkTimes() {
+	c.voiceMutex.Lock()
+	va := c.voiceActivity
+	c.voiceMutex.Unlock()
+	if va == nil {
+		return
+	}
+
+	va.mu.Lock()
+	defer va.mu.Unlock()
+
+	now := time.Now()
+	va.talkTime = make(map[string]time.Duration)
+	for userID := range va.talkingSince {
+		va.talkingSince[userID] = now
+	}
+}
+
+func (c *Client) addVoiceEventListener(event string, listener voiceEventListener) error {
+	if err := c.subscribeVoiceActivity(); err != nil {
+		return err
+	}
+
+	c.addListener(event, listener)
+
+	return nil
+}
+
+// All voice events share one subscription of the voice-users collection.
+// The users collection is needed to resolve the users.
+func (c *Client) subscribeVoiceActivity() error {
+	c.voiceMutex.Lock()
+	defer c.voiceMutex.Unlock()
+
+	if c.voiceActivity != nil {
+		return nil
+	}
+
+	users, err := c.ddpSubscribe(bbb.UsersSub, nil)
+	if err != nil {
+		return err
+	}
+	// updateVoiceActivity waits for voiceMutex, so it gets the updates after these documents
+	_, docs, err := c.ddpSubscribeDocs(bbb.VoiceUsersSub, c.updateVoiceActivity)
+	if err != nil {
+		c.ddpUnsubscribe(users)
+		return err
+	}
+
+	// The voice users already in the meeting are known before the listeners are informed
+	va := &voiceActivity{
+		voiceUsers:   make(map[string]bbb.VoiceUser),
+		talkingSince: make(map[string]time.Time),
+		talkTime:     make(map[string]time.Duration),
+	}
+	now := time.Now()
+	for id, doc := range docs {
+		voiceUser := bbb.ConvertInToVoiceUser(doc)
+		va.voiceUsers[id] = voiceUser
+		if voiceUser.Talking {
+			va.talkingSince[voiceUser.IntId] = now
+		}
+	}
+	c.voiceActivity = va
+
+	return nil
+}
+
+// informs all listeners with the changes of the voice users.
+func (c *Client) updateVoiceActivity(collection string, operation string, id string, doc ddp.Update) {
+	c.voiceMutex.Lock()
+	va := c.voiceActivity
+	c.voiceMutex.Unlock()
+	if va == nil {
+		return
+	}
+
+	now := time.Now()
+
+	va.mu.Lock()
+	defer va.mu.Unlock()
+
+	before, known := va.voiceUsers[id]
+
+	var after bbb.VoiceUser
+	if operation == removedOperation {
+		delete(va.voiceUsers, id)
+		if !known {
+			return
+		}
+		// The user left the audio, so the user does not talk anymore
+		after = before
+		after.Talking = false
+	} else if doc != nil {
+		after = bbb.ConvertInToVoiceUser(doc)
+		va.voiceUsers[id] = after
+	} else {
+		return
+	}
+
+	event := VoiceEvent{
+		Time:      now,
+		VoiceUser: after,
+	}
+
+	if !before.Talking && after.Talking {
+		va.talkingSince[after.IntId] = now
+		event.User = c.findUser(after.IntId)
+		c.emitVoiceEvent("OnTalkingStarted", event)
+	}
+	if before.Talking && !after.Talking {
+		if since, found := va.talkingSince[after.IntId]; found {
+			event.Duration = now.Sub(since)
+			va.talkTime[after.IntId] += event.Duration
+			delete(va.talkingSince, after.IntId)
+		}
+		event.User = c.findUser(after.IntId)
+		c.emitVoiceEvent("OnTalkingStopped", event)
+	}
+	if known && before.Muted != after.Muted {
+		event.User = c.findUser(after.IntId)
+		event.Duration = 0
+		c.emitVoiceEvent("OnMuteChanged", event)
+	}
+}
+
+// Returns the user with the user id. The users collection is read as it was received,
+// so a user that joined just before the voice user is known, even if its update is not handled yet.
+func (c *Client) findUser(userID string) bbb.User {
+	doc, found := c.ddpEventHandler.findDoc("users", func(doc ddp.Update) bool {
+		return doc["userId"] == userID
+	})
+	if !found {
+		return bbb.User{}
+	}
+	return bbb.ConvertInToUser(doc)
+}
+
+// informs all listeners of the event
+func (c *Client) emitVoiceEvent(eventName string, voiceEvent VoiceEvent) {
+	for _, event := range c.getListeners(eventName) {
+
+		// call event(voiceEvent)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Struct { //parameter 0 is of type struct (VoiceEvent)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(voiceEvent)})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for the voice events and the talk times with a user that talks before the listeners are added
+func TestVoiceEvents(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("users", "user1", map[string]interface{}{"userId": "w_1", "name": "Alice"})
+	server.Add("voice-users", "voice1", map[string]interface{}{"intId": "w_1", "talking": true})
+	client := newTestClient(t, server)
+
+	started := make(chan VoiceEvent, 10)
+	stopped := make(chan VoiceEvent, 10)
+	muted := make(chan VoiceEvent, 10)
+	if err := client.OnTalkingStarted(func(event VoiceEvent) { started <- event }); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.OnTalkingStopped(func(event VoiceEvent) { stopped <- event }); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.OnMuteChanged(func(event VoiceEvent) { muted <- event }); err != nil {
+		t.Fatal(err)
+	}
+
+	receive := func(name string, events chan VoiceEvent) VoiceEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s FAILED: no event was received", name)
+		}
+		return VoiceEvent{}
+	}
+
+	// The user talked before, so the talk is counted from the listener
+	time.Sleep(10 * time.Millisecond)
+	server.Change("voice-users", "voice1", map[string]interface{}{"talking": false})
+	event := receive("OnTalkingStopped()", stopped)
+	if event.User.Name != "Alice" || event.Duration < 10*time.Millisecond {
+		t.Errorf("OnTalkingStopped() FAILED: got user %q after %s, expected Alice after at least 10ms", event.User.Name, event.Duration)
+	}
+
+	// A user that joined after the listeners were added
+	server.Add("users", "user2", map[string]interface{}{"userId": "w_2", "name": "Bob"})
+	server.Add("voice-users", "voice2", map[string]interface{}{"intId": "w_2"})
+	server.Change("voice-users", "voice2", map[string]interface{}{"talking": true})
+	if event := receive("OnTalkingStarted()", started); event.User.Name != "Bob" {
+		t.Errorf("OnTalkingStarted() FAILED: got user %q, expected Bob", event.User.Name)
+	}
+	server.Change("voice-users", "voice1", map[string]interface{}{"muted": true})
+	if event := receive("OnMuteChanged()", muted); event.User.Name != "Alice" || !event.VoiceUser.Muted {
+		t.Errorf("OnMuteChanged() FAILED: got user %q muted %t, expected Alice muted", event.User.Name, event.VoiceUser.Muted)
+	}
+
+	talkTimes, err := client.GetTalkTimes()
+	if err != nil || talkTimes["w_1"] < 10*time.Millisecond {
+		t.Errorf("GetTalkTimes() FAILED: got %v (%v), expected at least 10ms for w_1", talkTimes, err)
+	} else {
+		t.Logf("voice events PASSED")
+	}
+}
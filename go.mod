@@ -3,21 +3,22 @@ module github.com/CharfedinIssawi/bigbluebutton-bot
 go 1.20
 
 require (
+	github.com/apex/log v1.9.0
 	github.com/benpate/convert v0.13.5
 	github.com/bigbluebutton-bot/golang-socketio v0.0.0-20231114184021-0b9dca893d46
-	github.com/gopackage/ddp v0.0.6
 	github.com/gorilla/websocket v1.5.1
 	github.com/pion/interceptor v0.1.25
+	github.com/pion/rtp v1.8.3
 	github.com/pion/sdp/v3 v3.0.6
 	github.com/pion/webrtc/v3 v3.2.22
 	golang.org/x/net v0.26.0
 	google.golang.org/grpc v1.59.0
 	google.golang.org/protobuf v1.33.0
+	gopkg.in/hraban/opus.v2 v2.0.0-20230925203106-0188a62cb302
 	gopkg.in/src-d/go-git.v4 v4.13.1
 )
 
 require (
-	github.com/apex/log v1.9.0 // indirect
 	github.com/benpate/derp v0.22.2 // indirect
 	github.com/benpate/null v0.6.4 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -34,7 +35,6 @@ require (
 	github.com/pion/mdns v0.0.8 // indirect
 	github.com/pion/randutil v0.1.0 // indirect
 	github.com/pion/rtcp v1.2.12 // indirect
-	github.com/pion/rtp v1.8.3 // indirect
 	github.com/pion/sctp v1.8.8 // indirect
 	github.com/pion/srtp/v2 v2.0.18 // indirect
 	github.com/pion/stun v0.6.1 // indirect
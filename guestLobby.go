@@ -0,0 +1,200 @@
+package bot
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// // Admission bot: only allow guests of the allowlist
+// err = client.OnGuestWaiting(func(guest bbb.GuestUser) {
+// 	if allowlist[guest.Name] {
+// 		client.ApproveGuests(guest.IntId)
+// 	} else {
+// 		client.DenyGuests(guest.IntId)
+// 	}
+// })
+// if err != nil {
+// 	panic(err)
+// }
+
+// How often the guest status is requested while the bot waits in the lobby (the HTML5 client does the same)
+const guestWaitInterval = time.Second * 5
+
+type guestStatusListener func(status bbb.GuestStatus)
+type guestWaitingListener func(guest bbb.GuestUser)
+
+// OnGuestStatus in order to receive the guest status while the bot waits in the lobby during Join.
+// The listener is kept if the client leaves the meeting.
+func (c *Client) OnGuestStatus(listener guestStatusListener) {
+	c.addListener("OnGuestStatus", listener)
+}
+
+// Wait in the guest lobby until a moderator approved or denied the bot or GuestWaitTimeout is reached
+func (c *Client) waitInGuestLobby(status bbb.GuestStatus, sessionToken string) error {
+	deadline := time.Now().Add(c.GuestWaitTimeout)
+	c.log().Info("waiting for approval of a moderator", "timeout", c.GuestWaitTimeout.String())
+	c.emitGuestStatus(status)
+
+	for {
+		switch status {
+		case bbb.GuestStatusAllow:
+			return nil
+		case bbb.GuestStatusDeny:
+			return errors.New("could not join: the bot was denied by a moderator")
+		}
+
+		if time.Now().After(deadline) {
+			return errors.New("could not join: timeout while waiting for approval of a moderator")
+		}
+		time.Sleep(guestWaitInterval)
+
+		newStatus, _, err := c.API.GuestWait(sessionToken)
+		if err != nil {
+			return errors.New("could not get guest status: " + err.Error())
+		}
+		if bbb.GuestStatus(newStatus) != status {
+			status = bbb.GuestStatus(newStatus)
+			c.emitGuestStatus(status)
+		}
+	}
+}
+
+// informs all listeners with the guest status
+func (c *Client) emitGuestStatus(status bbb.GuestStatus) {
+	for _, event := range c.getListeners("OnGuestStatus") {
+
+		// call event(status)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.String { //parameter 0 is of type string (bbb.GuestStatus)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(status)})
+				}
+			}
+		}
+	}
+}
+
+//--------------------------------------------------
+// Moderator
+//--------------------------------------------------
+
+// OnGuestWaiting in order to receive a new guest in the lobby. The bot has to be a moderator.
+func (c *Client) OnGuestWaiting(listener guestWaitingListener) error {
+	if err := c.requireModerator("receive guests"); err != nil {
+		return err
+	}
+
+	return c.addSubscribedListener("OnGuestWaiting", listener, func() error {
+		_, err := c.ddpSubscribe(bbb.GuestUserSub, c.updateGuestWaiting)
+		return err
+	})
+}
+
+// informs all listeners about a new guest in the lobby
+func (c *Client) updateGuestWaiting(collection string, operation string, id string, doc ddp.Update) {
+	if operation != addedOperation || doc == nil {
+		return
+	}
+
+	guest := bbb.ConvertInToGuestUser(doc)
+	if guest.Approved || guest.Denied {
+		return
+	}
+
+	for _, event := range c.getListeners("OnGuestWaiting") {
+
+		// call event(guest)
+		f := reflect.TypeOf(event)
+		if f.Kind() == reflect.Func { //is function
+			if f.NumIn() == 1 && f.NumOut() == 0 { //inbound parameters == 1, outbound parameters == 0
+				if f.In(0).Kind() == reflect.Struct { //parameter 0 is of type struct (bbb.GuestUser)
+					go reflect.ValueOf(event).Call([]reflect.Value{reflect.ValueOf(guest)})
+				}
+			}
+		}
+	}
+}
+
+// GetWaitingGuests returns all guests in the lobby. The bot has to be a moderator.
+func (c *Client) GetWaitingGuests() ([]bbb.GuestUser, error) {
+	if err := c.requireModerator("get waiting guests"); err != nil {
+		return nil, err
+	}
+
+	if err := c.ddpSubscribeOnce(bbb.GuestUserSub); err != nil {
+		return nil, err
+	}
+
+	// The documents as they were received (a copy, so the ddpClient can change the collection meanwhile)
+	collection, _ := c.getSub(bbb.GuestUserSub)
+	docs, _ := c.ddpEventHandler.getDocs(collection)
+
+	guests := []bbb.GuestUser{}
+	for _, doc := range docs {
+		guest := bbb.ConvertInToGuestUser(doc)
+		if !guest.Approved && !guest.Denied {
+			guests = append(guests, guest)
+		}
+	}
+	return guests, nil
+}
+
+// ApproveGuests lets the guests (user ids) join the meeting. The bot has to be a moderator.
+func (c *Client) ApproveGuests(userIDs ...string) error {
+	return c.setGuestStatus(bbb.GuestStatusAllow, userIDs)
+}
+
+// DenyGuests denies the guests (user ids). The bot has to be a moderator.
+func (c *Client) DenyGuests(userIDs ...string) error {
+	return c.setGuestStatus(bbb.GuestStatusDeny, userIDs)
+}
+
+// ApproveAllGuests lets all guests in the lobby join the meeting. The bot has to be a moderator.
+func (c *Client) ApproveAllGuests() error {
+	return c.setGuestStatus(bbb.GuestStatusAllow, nil)
+}
+
+// DenyAllGuests denies all guests in the lobby. The bot has to be a moderator.
+func (c *Client) DenyAllGuests() error {
+	return c.setGuestStatus(bbb.GuestStatusDeny, nil)
+}
+
+// Set the status of the guests with the user ids (all guests if userIDs is nil)
+func (c *Client) setGuestStatus(status bbb.GuestStatus, userIDs []string) error {
+	waiting, err := c.GetWaitingGuests()
+	if err != nil {
+		return err
+	}
+
+	guests := []bbb.GuestUser{}
+	if userIDs == nil {
+		guests = waiting
+	} else {
+		for _, userID := range userIDs {
+			found := false
+			for _, guest := range waiting {
+				if guest.IntId == userID {
+					guests = append(guests, guest)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return errors.New("could not set guest status: guest " + userID + " is not waiting")
+			}
+		}
+	}
+	if len(guests) == 0 {
+		return nil
+	}
+
+	return c.moderatorCall("set guest status", bbb.AllowPendingUsersCall, guests, status)
+}
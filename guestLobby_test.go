@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"testing"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for GetWaitingGuests and ApproveGuests with guests that are in the lobby before the first call
+func TestWaitingGuests(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("users", "doc1", map[string]interface{}{"userId": "w_bot", "role": bbb.RoleModerator})
+	server.Add("guest-user", "doc2", map[string]interface{}{"intId": "w_1", "name": "Alice", "guest": true})
+	server.Add("guest-user", "doc3", map[string]interface{}{"intId": "w_2", "name": "Bob", "guest": true, "approved": true})
+	client := newTestClient(t, server)
+
+	guests, err := client.GetWaitingGuests()
+	if err != nil || len(guests) != 1 || guests[0].IntId != "w_1" {
+		t.Errorf("GetWaitingGuests() FAILED: got %+v (%v), expected w_1", guests, err)
+	}
+
+	if err := client.ApproveGuests("w_2"); err == nil {
+		t.Errorf("ApproveGuests() FAILED: no error for a guest that is not waiting")
+	}
+	if err := client.ApproveGuests("w_1"); err != nil {
+		t.Errorf("ApproveGuests() FAILED: %v", err)
+	}
+
+	if calls := countMessages(server, "method", "allowPendingUsers"); calls != 1 {
+		t.Errorf("ApproveGuests() FAILED: server got %d allowPendingUsers calls, expected 1", calls)
+	} else {
+		t.Logf("GetWaitingGuests() and ApproveGuests() PASSED")
+	}
+}
@@ -0,0 +1,308 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Logger is used by the bot.Client, bot.AudioClient and pad.Pad for all log messages.
+// It is compatible with *slog.Logger (log/slog), so slog.Default() can be used as Logger.
+// args are key value pairs: logger.Info("connected", "meeting", meetingID, "user", userID)
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Level of a log message. The values are the same as the slog.Level values.
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	case l < LevelError:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+// LevelEnabler can be implemented by a Logger, to tell which levels it writes (the loggers of New and Nop do).
+// With does not redact the messages of other levels, because they are dropped anyway.
+type LevelEnabler interface {
+	Enabled(level Level) bool
+}
+
+//--------------------------------------------------
+// Text logger
+//--------------------------------------------------
+
+// textLogger writes one line per message: 2006/01/02 15:04:05 INFO msg key=value key2=value2
+type textLogger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+}
+
+// New returns a Logger which writes all messages with at least the given level as text lines to w.
+func New(w io.Writer, level Level) Logger {
+	return &textLogger{
+		w:     w,
+		level: level,
+	}
+}
+
+// Default returns a Logger which writes all messages with at least the level INFO to stderr.
+func Default() Logger {
+	return New(os.Stderr, LevelInfo)
+}
+
+func (l *textLogger) Debug(msg string, args ...any) { l.log(LevelDebug, msg, args) }
+func (l *textLogger) Info(msg string, args ...any)  { l.log(LevelInfo, msg, args) }
+func (l *textLogger) Warn(msg string, args ...any)  { l.log(LevelWarn, msg, args) }
+func (l *textLogger) Error(msg string, args ...any) { l.log(LevelError, msg, args) }
+
+// Enabled returns true if messages of the level are written
+func (l *textLogger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+func (l *textLogger) log(level Level, msg string, args []any) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString(time.Now().Format("2006/01/02 15:04:05"))
+	line.WriteString(" ")
+	line.WriteString(level.String())
+	line.WriteString(" ")
+	line.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		line.WriteString(" ")
+		if i+1 < len(args) {
+			line.WriteString(fmt.Sprint(args[i]))
+			line.WriteString("=")
+			line.WriteString(fmt.Sprintf("%q", fmt.Sprint(args[i+1])))
+		} else {
+			line.WriteString("!BADKEY=")
+			line.WriteString(fmt.Sprintf("%q", fmt.Sprint(args[i])))
+		}
+	}
+	line.WriteString("\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.w, line.String())
+}
+
+//--------------------------------------------------
+// Nop logger
+//--------------------------------------------------
+
+type nopLogger struct{}
+
+// Nop returns a Logger which drops all messages.
+func Nop() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(msg string, args ...any) {}
+func (nopLogger) Info(msg string, args ...any)  {}
+func (nopLogger) Warn(msg string, args ...any)  {}
+func (nopLogger) Error(msg string, args ...any) {}
+
+// Enabled returns false, no level is written
+func (nopLogger) Enabled(level Level) bool { return false }
+
+//--------------------------------------------------
+// Fields and redaction
+//--------------------------------------------------
+
+// fieldLogger adds fields to every message and redacts secrets before calling the next logger.
+type fieldLogger struct {
+	next   Logger
+	fields []any
+}
+
+// With returns a Logger which adds the key value pairs in fields to every message.
+// Values of secrets (tokens, passwords, cookies, ...) are redacted, before they are given to l.
+func With(l Logger, fields ...any) Logger {
+	if l == nil {
+		l = Nop()
+	}
+	// Do not wrap twice
+	if fl, ok := l.(*fieldLogger); ok {
+		return &fieldLogger{
+			next:   fl.next,
+			fields: append(append([]any{}, fl.fields...), fields...),
+		}
+	}
+	return &fieldLogger{
+		next:   l,
+		fields: fields,
+	}
+}
+
+func (l *fieldLogger) Debug(msg string, args ...any) {
+	if l.Enabled(LevelDebug) {
+		l.next.Debug(RedactString(msg), l.args(args)...)
+	}
+}
+
+func (l *fieldLogger) Info(msg string, args ...any) {
+	if l.Enabled(LevelInfo) {
+		l.next.Info(RedactString(msg), l.args(args)...)
+	}
+}
+
+func (l *fieldLogger) Warn(msg string, args ...any) {
+	if l.Enabled(LevelWarn) {
+		l.next.Warn(RedactString(msg), l.args(args)...)
+	}
+}
+
+func (l *fieldLogger) Error(msg string, args ...any) {
+	if l.Enabled(LevelError) {
+		l.next.Error(RedactString(msg), l.args(args)...)
+	}
+}
+
+// Enabled returns true if the next logger writes messages of the level.
+// Loggers that do not implement LevelEnabler (e.g. *slog.Logger) get all messages.
+func (l *fieldLogger) Enabled(level Level) bool {
+	if enabler, ok := l.next.(LevelEnabler); ok {
+		return enabler.Enabled(level)
+	}
+	return true
+}
+
+func (l *fieldLogger) args(args []any) []any {
+	return Redact(append(append([]any{}, l.fields...), args...))
+}
+
+// Keys which values are never logged. "sessions" are the etherpad sessions of the pads-sessions documents.
+var secretKeys = []string{"token", "secret", "password", "cookie", "sessionid", "sessions", "checksum", "credential"}
+
+// Values of url parameters, cookies and json fields with these names are replaced
+var secretPattern = regexp.MustCompile(`(?i)((?:session_?token|auth_?token|token|secret|password|sessionID|checksum|credential)"?\s*[=:]\s*"?)([^&\s",;]+)`)
+
+const redacted = "[REDACTED]"
+
+// Maps, slices and structs nested deeper than this are not logged
+const redactDepth = 8
+
+// Redact replaces the values of all secret keys in the key value pairs of args.
+// Secrets inside of string values (e.g. urls with ?sessionToken=...) are replaced as well.
+// Maps and structs are redacted by their keys and field names, slices by their elements.
+func Redact(args []any) []any {
+	out := make([]any, len(args))
+	for i := 0; i < len(args); i++ {
+		if i%2 == 0 && i+1 < len(args) && isSecretKey(fmt.Sprint(args[i])) {
+			out[i] = args[i]
+			out[i+1] = redacted
+			i++
+			continue
+		}
+		out[i] = redactValue(args[i], 0)
+	}
+	return out
+}
+
+// Returns value without secrets. Maps and structs are returned as map[string]any and slices as []any.
+func redactValue(value any, depth int) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return RedactString(v)
+	case error:
+		return RedactString(v.Error())
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return value
+		}
+	case reflect.Array, reflect.Struct:
+	default:
+		return value
+	}
+	if s, ok := value.(fmt.Stringer); ok {
+		return RedactString(s.String())
+	}
+	if depth >= redactDepth {
+		return redacted
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return redactValue(rv.Elem().Interface(), depth+1)
+	case reflect.Map:
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if isSecretKey(key) {
+				out[key] = redacted
+			} else {
+				out[key] = redactValue(iter.Value().Interface(), depth+1)
+			}
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = redactValue(rv.Index(i).Interface(), depth+1)
+		}
+		return out
+	default:
+		out := make(map[string]any)
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if isSecretKey(field.Name) {
+				out[field.Name] = redacted
+			} else {
+				out[field.Name] = redactValue(rv.Field(i).Interface(), depth+1)
+			}
+		}
+		return out
+	}
+}
+
+// RedactString replaces the values of secrets in s (e.g. "sessionToken=abc" -> "sessionToken=[REDACTED]")
+func RedactString(s string) string {
+	return secretPattern.ReplaceAllString(s, "${1}"+redacted)
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
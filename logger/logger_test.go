@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Test for RedactString
+func TestRedactString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "wss://example.com/bbb-webrtc-sfu?sessionToken=gtxiomrffih2b8qr",
+			expected: "wss://example.com/bbb-webrtc-sfu?sessionToken=[REDACTED]",
+		},
+		{
+			input:    "auth_session?padName=g.9d4O2LRqTkIfh6bM$notes&sessionID=s.4918c0b0&lang=en",
+			expected: "auth_session?padName=g.9d4O2LRqTkIfh6bM$notes&sessionID=[REDACTED]&lang=en",
+		},
+		{
+			input:    `{"padId":"g.abc","token":"t.oNTJCeHhA5x2lI9rM5st"}`,
+			expected: `{"padId":"g.abc","token":"[REDACTED]"}`,
+		},
+		{
+			input:    "nothing to hide",
+			expected: "nothing to hide",
+		},
+	}
+
+	for num, test := range tests {
+		result := RedactString(test.input)
+		if result != test.expected {
+			t.Errorf("RedactString() %d FAILED: got %s, expected %s", num, result, test.expected)
+		} else {
+			t.Logf("RedactString() %d PASSED", num)
+		}
+	}
+}
+
+// Test for With (fields, redaction and levels)
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	l := With(New(&buf, LevelInfo), "meeting", "meetingID")
+
+	l.Debug("not logged")
+	l.Info("joined", "user", "w_abc", "sessionToken", "gtxiomrffih2b8qr")
+
+	out := buf.String()
+	if strings.Contains(out, "not logged") {
+		t.Errorf("With() FAILED: debug message was logged: %s", out)
+	}
+	if !strings.Contains(out, `INFO joined meeting="meetingID" user="w_abc" sessionToken="[REDACTED]"`) {
+		t.Errorf("With() FAILED: wrong output: %s", out)
+	}
+	if strings.Contains(out, "gtxiomrffih2b8qr") {
+		t.Errorf("With() FAILED: token was logged: %s", out)
+	}
+}
+
+// error that counts how often it was formatted
+type countingError struct {
+	calls *int
+}
+
+func (e countingError) Error() string {
+	*e.calls++
+	return "failed"
+}
+
+// Test for With with disabled levels (the fields are not redacted)
+func TestWithDisabledLevel(t *testing.T) {
+	calls := 0
+	err := countingError{calls: &calls}
+
+	var buf bytes.Buffer
+	l := With(New(&buf, LevelWarn), "meeting", "meetingID")
+	l.Debug("not logged", "error", err)
+	l.Info("not logged", "error", err)
+	With(Nop(), "error", err).Error("not logged")
+	if calls != 0 || buf.Len() != 0 {
+		t.Errorf("With() FAILED: the error was formatted %d times and %q was logged, expected 0 times and nothing", calls, buf.String())
+	}
+
+	l.Warn("logged", "error", err)
+	if calls != 1 || !strings.Contains(buf.String(), `WARN logged meeting="meetingID" error="failed"`) {
+		t.Errorf("With() FAILED: the error was formatted %d times and %q was logged, expected once and the warning", calls, buf.String())
+	} else {
+		t.Logf("With() disabled levels PASSED")
+	}
+}
+
+// Test for Redact with documents (maps, slices and structs)
+func TestRedact(t *testing.T) {
+	type update map[string]interface{}
+	type user struct {
+		Name      string
+		AuthToken string
+		Cookies   []string
+		hidden    string
+	}
+
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{
+			input:    update{"meetingId": "m1", "sessions": []interface{}{map[string]interface{}{"g.abc$en": "s.4918c0b0"}}},
+			expected: "map[meetingId:m1 sessions:[REDACTED]]",
+		},
+		{
+			input:    []interface{}{update{"url": "https://example.com/?sessionToken=gtxiomrffih2b8qr"}},
+			expected: "[map[url:https://example.com/?sessionToken=[REDACTED]]]",
+		},
+		{
+			input:    &user{Name: "bot", AuthToken: "t.oNTJCeHhA5x2lI9rM5st", Cookies: []string{"JSESSIONID=abc"}, hidden: "x"},
+			expected: "map[AuthToken:[REDACTED] Cookies:[REDACTED] Name:bot]",
+		},
+		{
+			input:    42,
+			expected: "42",
+		},
+	}
+
+	for num, test := range tests {
+		result := fmt.Sprint(Redact([]any{"doc", test.input})[1])
+		if result != test.expected {
+			t.Errorf("Redact() %d FAILED: got %s, expected %s", num, result, test.expected)
+		} else {
+			t.Logf("Redact() %d PASSED", num)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package bot
+
+import (
+	"sync"
+
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// state, err := client.GetMeetingState()
+// if err != nil {
+// 	panic(err)
+// }
+// for _, user := range state.Moderators() {
+// 	fmt.Println(user.Name)
+// }
+
+// MeetingState is a typed copy of the collections of the joined meeting.
+// It is updated by the ddp subscriptions and can be used from any goroutine.
+type MeetingState struct {
+	mu sync.RWMutex
+
+	// all documents by their ddp id
+	users         map[string]bbb.User
+	voiceUsers    map[string]bbb.VoiceUser
+	meetings      map[string]bbb.Meeting
+	chats         map[string]bbb.Chat
+	breakouts     map[string]bbb.Breakout
+	presentations map[string]bbb.Presentation
+	slides        map[string]bbb.Slide
+	polls         map[string]bbb.Poll
+}
+
+func newMeetingState() *MeetingState {
+	return &MeetingState{
+		users:         make(map[string]bbb.User),
+		voiceUsers:    make(map[string]bbb.VoiceUser),
+		meetings:      make(map[string]bbb.Meeting),
+		chats:         make(map[string]bbb.Chat),
+		breakouts:     make(map[string]bbb.Breakout),
+		presentations: make(map[string]bbb.Presentation),
+		slides:        make(map[string]bbb.Slide),
+		polls:         make(map[string]bbb.Poll),
+	}
+}
+
+// GetMeetingState returns the state of the joined meeting.
+// The first call subscribes to all needed collections and returns their current documents.
+// The state is removed on Leave.
+func (c *Client) GetMeetingState() (*MeetingState, error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if c.meetingState != nil {
+		return c.meetingState, nil
+	}
+
+	s := newMeetingState()
+	subs := []struct {
+		sub     bbb.SubType
+		updater updaterfunc
+	}{
+		{bbb.UsersSub, s.updateUsers},
+		{bbb.VoiceUsersSub, s.updateVoiceUsers},
+		{bbb.MeetingsSub, s.updateMeetings},
+		{bbb.GroupChatSub, s.updateChats},
+		{bbb.BreakoutsSub, s.updateBreakouts},
+		{bbb.PresentationsSub, s.updatePresentations},
+		{bbb.SlidesSub, s.updateSlides},
+		{bbb.PollsSub, s.updatePolls},
+	}
+	handles := []ddpHandle{}
+	for _, sub := range subs {
+		// The updates wait until the state has the documents the collection already has
+		s.mu.Lock()
+		handle, docs, err := c.ddpSubscribeDocs(sub.sub, s.locked(sub.updater))
+		if err != nil {
+			s.mu.Unlock()
+			// Unsubscribe from the collections that were already subscribed
+			for _, done := range handles {
+				c.ddpUnsubscribe(done)
+			}
+			return nil, err
+		}
+		collection, _ := c.getSub(sub.sub)
+		for id, doc := range docs {
+			sub.updater(collection, addedOperation, id, doc)
+		}
+		s.mu.Unlock()
+		handles = append(handles, handle)
+	}
+
+	c.meetingState = s
+	return s, nil
+}
+
+//--------------------------------------------------
+// Updaters (must be called with mu locked)
+//--------------------------------------------------
+
+// Returns the updater that locks mu
+func (s *MeetingState) locked(updater updaterfunc) updaterfunc {
+	return func(collection string, operation string, id string, doc ddp.Update) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		updater(collection, operation, id, doc)
+	}
+}
+
+func (s *MeetingState) updateUsers(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation {
+		delete(s.users, id)
+	} else if doc != nil {
+		s.users[id] = bbb.ConvertInToUser(doc)
+	}
+}
+
+func (s *MeetingState) updateVoiceUsers(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation {
+		delete(s.voiceUsers, id)
+	} else if doc != nil {
+		s.voiceUsers[id] = bbb.ConvertInToVoiceUser(doc)
+	}
+}
+
+func (s *MeetingState) updateMeetings(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation {
+		delete(s.meetings, id)
+	} else if doc != nil {
+		s.meetings[id] = bbb.ConvertInToMeeting(doc)
+	}
+}
+
+func (s *MeetingState) updateChats(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation {
+		delete(s.chats, id)
+	} else if doc != nil {
+		s.chats[id] = bbb.ConvertInToChat(doc)
+	}
+}
+
+func (s *MeetingState) updateBreakouts(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation {
+		delete(s.breakouts, id)
+	} else if doc != nil {
+		s.breakouts[id] = bbb.ConvertInToBreakout(doc)
+	}
+}
+
+func (s *MeetingState) updatePresentations(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation {
+		delete(s.presentations, id)
+	} else if doc != nil {
+		s.presentations[id] = bbb.ConvertInToPresentation(doc)
+	}
+}
+
+func (s *MeetingState) updateSlides(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation {
+		delete(s.slides, id)
+	} else if doc != nil {
+		s.slides[id] = bbb.ConvertInToSlide(doc)
+	}
+}
+
+func (s *MeetingState) updatePolls(collection string, operation string, id string, doc ddp.Update) {
+	if operation == removedOperation {
+		delete(s.polls, id)
+	} else if doc != nil {
+		s.polls[id] = bbb.ConvertInToPoll(doc)
+	}
+}
+
+//--------------------------------------------------
+// Queries (all return copies)
+//--------------------------------------------------
+
+// Users returns all users of the meeting
+func (s *MeetingState) Users() []bbb.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]bbb.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	return users
+}
+
+// User returns the user with the internal user id
+func (s *MeetingState) User(userID string) (bbb.User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.UserID == userID {
+			return user, true
+		}
+	}
+	return bbb.User{}, false
+}
+
+// UserByExtID returns the user with the external user id (userID param of the join api call)
+func (s *MeetingState) UserByExtID(extID string) (bbb.User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.ExtID == extID {
+			return user, true
+		}
+	}
+	return bbb.User{}, false
+}
+
+// Moderators returns all users with the role MODERATOR
+func (s *MeetingState) Moderators() []bbb.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]bbb.User, 0)
+	for _, user := range s.users {
+		if user.Role == bbb.RoleModerator {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+// Presenters returns all users which are presenter
+func (s *MeetingState) Presenters() []bbb.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]bbb.User, 0)
+	for _, user := range s.users {
+		if user.Presenter {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+// VoiceUsers returns all users in the audio conference
+func (s *MeetingState) VoiceUsers() []bbb.VoiceUser {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	voiceUsers := make([]bbb.VoiceUser, 0, len(s.voiceUsers))
+	for _, voiceUser := range s.voiceUsers {
+		voiceUsers = append(voiceUsers, voiceUser)
+	}
+	return voiceUsers
+}
+
+// VoiceUser returns the voice user of the user with the internal user id
+func (s *MeetingState) VoiceUser(userID string) (bbb.VoiceUser, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, voiceUser := range s.voiceUsers {
+		if voiceUser.IntId == userID {
+			return voiceUser, true
+		}
+	}
+	return bbb.VoiceUser{}, false
+}
+
+// TalkingUsers returns all users which are talking right now
+func (s *MeetingState) TalkingUsers() []bbb.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]bbb.User, 0)
+	for _, voiceUser := range s.voiceUsers {
+		if !voiceUser.Talking {
+			continue
+		}
+		for _, user := range s.users {
+			if user.UserID == voiceUser.IntId {
+				users = append(users, user)
+				break
+			}
+		}
+	}
+	return users
+}
+
+// Meeting returns the joined meeting
+func (s *MeetingState) Meeting() (bbb.Meeting, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, meeting := range s.meetings {
+		return meeting, true
+	}
+	return bbb.Meeting{}, false
+}
+
+// Chats returns the public chat and all private chats of the bot
+func (s *MeetingState) Chats() []bbb.Chat {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chats := make([]bbb.Chat, 0, len(s.chats))
+	for _, chat := range s.chats {
+		chats = append(chats, chat)
+	}
+	return chats
+}
+
+// Breakouts returns all breakout rooms of the meeting
+func (s *MeetingState) Breakouts() []bbb.Breakout {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	breakouts := make([]bbb.Breakout, 0, len(s.breakouts))
+	for _, breakout := range s.breakouts {
+		breakouts = append(breakouts, breakout)
+	}
+	return breakouts
+}
+
+// Presentations returns all uploaded presentations
+func (s *MeetingState) Presentations() []bbb.Presentation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	presentations := make([]bbb.Presentation, 0, len(s.presentations))
+	for _, presentation := range s.presentations {
+		presentations = append(presentations, presentation)
+	}
+	return presentations
+}
+
+// CurrentPresentation returns the presentation which is shown right now
+func (s *MeetingState) CurrentPresentation() (bbb.Presentation, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, presentation := range s.presentations {
+		if presentation.Current {
+			return presentation, true
+		}
+	}
+	return bbb.Presentation{}, false
+}
+
+// Slides returns all slides of the presentation
+func (s *MeetingState) Slides(presentationID string) []bbb.Slide {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	slides := make([]bbb.Slide, 0)
+	for _, slide := range s.slides {
+		if slide.PresentationId == presentationID {
+			slides = append(slides, slide)
+		}
+	}
+	return slides
+}
+
+// CurrentSlide returns the slide which is shown right now
+func (s *MeetingState) CurrentSlide() (bbb.Slide, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, slide := range s.slides {
+		if !slide.Current {
+			continue
+		}
+		// The slides of the other presentations keep their current page
+		if presentation, found := s.presentation(slide.PresentationId); found && !presentation.Current {
+			continue
+		}
+		return slide, true
+	}
+	return bbb.Slide{}, false
+}
+
+// Returns the presentation with the id. The presentations are stored by their ddp id, which is not the same.
+func (s *MeetingState) presentation(presentationID string) (bbb.Presentation, bool) {
+	for _, presentation := range s.presentations {
+		if presentation.ID == presentationID {
+			return presentation, true
+		}
+	}
+	return bbb.Presentation{}, false
+}
+
+// Polls returns all running polls
+func (s *MeetingState) Polls() []bbb.Poll {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	polls := make([]bbb.Poll, 0, len(s.polls))
+	for _, poll := range s.polls {
+		polls = append(polls, poll)
+	}
+	return polls
+}
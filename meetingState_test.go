@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"testing"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	ddp "github.com/bigbluebutton-bot/bigbluebutton-bot/ddp"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for CurrentSlide with more than one presentation
+func TestCurrentSlide(t *testing.T) {
+	tests := []struct {
+		current  string // id of the current presentation
+		expected string // id of the expected slide
+	}{
+		{
+			current:  "presentation-a",
+			expected: "slide-a2",
+		},
+		{
+			current:  "presentation-b",
+			expected: "slide-b5",
+		},
+	}
+
+	for num, test := range tests {
+		s := newMeetingState()
+
+		// The ddp ids of the documents are not the ids of the presentations
+		s.updatePresentations("presentations", addedOperation, "doc1", ddp.Update{
+			"id":      "presentation-a",
+			"current": test.current == "presentation-a",
+		})
+		s.updatePresentations("presentations", addedOperation, "doc2", ddp.Update{
+			"id":      "presentation-b",
+			"current": test.current == "presentation-b",
+		})
+
+		// Every presentation keeps its current slide
+		s.updateSlides("slides", addedOperation, "doc3", ddp.Update{"id": "slide-a1", "presentationId": "presentation-a", "num": 1})
+		s.updateSlides("slides", addedOperation, "doc4", ddp.Update{"id": "slide-a2", "presentationId": "presentation-a", "num": 2, "current": true})
+		s.updateSlides("slides", addedOperation, "doc5", ddp.Update{"id": "slide-b5", "presentationId": "presentation-b", "num": 5, "current": true})
+
+		// The slides are stored in a map, so the order is random
+		for i := 0; i < 10; i++ {
+			slide, found := s.CurrentSlide()
+			if !found || slide.ID != test.expected {
+				t.Fatalf("CurrentSlide() %d FAILED: got %s (found %t), expected %s", num, slide.ID, found, test.expected)
+			}
+		}
+		t.Logf("CurrentSlide() %d PASSED", num)
+	}
+}
+
+// Test for GetMeetingState with documents that exist before the subscription
+func TestGetMeetingStateExistingDocs(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("users", "doc1", map[string]interface{}{"userId": "w_1", "intId": "w_1", "name": "Alice", "role": "MODERATOR"})
+	server.Add("users", "doc2", map[string]interface{}{"userId": "w_2", "intId": "w_2", "name": "Bob", "role": "VIEWER"})
+	server.Add("meetings", "doc3", map[string]interface{}{"meetingId": testInternalMeetingID})
+
+	client := newTestClient(t, server)
+
+	// another feature subscribed to the users already
+	if _, err := client.ddpSubscribe(bbb.UsersSub, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := client.GetMeetingState()
+	if err != nil {
+		t.Fatalf("GetMeetingState() FAILED: %v", err)
+	}
+	if users := state.Users(); len(users) != 2 {
+		t.Errorf("GetMeetingState() FAILED: got %d users, expected 2", len(users))
+	}
+	if moderators := state.Moderators(); len(moderators) != 1 || moderators[0].Name != "Alice" {
+		t.Errorf("GetMeetingState() FAILED: got moderators %+v, expected Alice", moderators)
+	}
+	if meeting, found := state.Meeting(); !found || meeting.MeetingId != testInternalMeetingID {
+		t.Errorf("GetMeetingState() FAILED: got meeting %+v (found %t), expected %s", meeting, found, testInternalMeetingID)
+	}
+
+	// the later updates are added to the documents
+	server.Remove("users", "doc2")
+	server.Add("users", "doc4", map[string]interface{}{"userId": "w_3", "intId": "w_3", "name": "Carol", "role": "VIEWER"})
+	server.Change("users", "doc1", map[string]interface{}{"name": "Alice B."})
+	waitFor(t, "the updates of the users", func() bool {
+		user, found := state.User("w_1")
+		_, removed := state.User("w_2")
+		_, added := state.User("w_3")
+		return found && user.Name == "Alice B." && !removed && added
+	})
+	t.Logf("GetMeetingState() PASSED")
+}
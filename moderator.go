@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"errors"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// err = client.MuteAllUsers()
+// if err != nil {
+// 	fmt.Println(err)
+// }
+// err = client.SetLockSettings(bbb.MeetingLockSettingsProps{
+// 	DisablePrivateChat: true,
+// })
+// if err != nil {
+// 	fmt.Println(err)
+// }
+
+// ErrNotModerator is returned by the moderator actions if the bot is not a moderator of the meeting.
+var ErrNotModerator = errors.New("the bot is not a moderator of the meeting")
+
+// IsModerator returns true if the bot is a moderator of the meeting
+func (c *Client) IsModerator() bool {
+	state, err := c.GetMeetingState()
+	if err != nil {
+		return false
+	}
+	user, _ := state.User(c.InternalUserID)
+	return user.Role == bbb.RoleModerator
+}
+
+// Returns an error if the bot is not a moderator. The server ignores most of the calls of viewers without an error.
+func (c *Client) requireModerator(action string) error {
+	if !c.IsModerator() {
+		return errors.New("could not " + action + ": " + ErrNotModerator.Error())
+	}
+	return nil
+}
+
+// calls the method as moderator
+func (c *Client) moderatorCall(action string, method bbb.CallType, params ...interface{}) error {
+	if err := c.requireModerator(action); err != nil {
+		return err
+	}
+
+	_, err := c.ddpCall(method, params...)
+	if err != nil {
+		return errors.New("could not " + action + ": " + err.Error())
+	}
+
+	return nil
+}
+
+// MuteAllUsers mutes all users of the meeting
+func (c *Client) MuteAllUsers() error {
+	return c.moderatorCall("mute all users", bbb.MuteAllUsersCall)
+}
+
+// MuteAllUsersExceptPresenter mutes all users of the meeting except the presenter
+func (c *Client) MuteAllUsersExceptPresenter() error {
+	return c.moderatorCall("mute all users except presenter", bbb.MuteAllExceptPresenterCall)
+}
+
+// MuteUser mutes or unmutes the user. Unmuting other users is only possible if the meeting allows it
+// (AllowModsToUnmuteUsers of bbb.MeetingUsersProp).
+func (c *Client) MuteUser(userID string, mute bool) error {
+	if userID == c.InternalUserID {
+		_, err := c.ddpCall(bbb.ToggleVoiceCall, userID, mute)
+		if err != nil {
+			return errors.New("could not mute user: " + err.Error())
+		}
+		return nil
+	}
+	return c.moderatorCall("mute user", bbb.ToggleVoiceCall, userID, mute)
+}
+
+// SetLockSettings sets the lock settings of the meeting (SetBy is set by the server)
+func (c *Client) SetLockSettings(lockSettings bbb.MeetingLockSettingsProps) error {
+	return c.moderatorCall("set lock settings", bbb.ToggleLockSettingsCall, lockSettings)
+}
+
+// SetGuestPolicy sets how guests are allowed to join the meeting
+func (c *Client) SetGuestPolicy(guestPolicy bbb.GuestPolicy) error {
+	switch guestPolicy {
+	case bbb.GuestPolicyAlwaysAccept, bbb.GuestPolicyAlwaysDeny, bbb.GuestPolicyAskModerator:
+	default:
+		return errors.New("could not set guest policy: unknown guest policy " + string(guestPolicy))
+	}
+	return c.moderatorCall("set guest policy", bbb.ChangeGuestPolicyCall, guestPolicy)
+}
+
+// SetWebcamsOnlyForModerator sets if the webcams of the viewers are only shown to moderators
+func (c *Client) SetWebcamsOnlyForModerator(webcamsOnlyForModerator bool) error {
+	return c.moderatorCall("set webcams only for moderator", bbb.ToggleWebcamsOnlyForModeratorCall, webcamsOnlyForModerator)
+}
+
+// EjectUser removes the user from the meeting. If ban is true, the user can not join again.
+func (c *Client) EjectUser(userID string, ban bool) error {
+	return c.moderatorCall("eject user", bbb.RemoveUserCall, userID, ban)
+}
+
+// ChangeRole changes the role of the user to bbb.RoleModerator or bbb.RoleViewer
+func (c *Client) ChangeRole(userID string, role string) error {
+	if role != bbb.RoleModerator && role != bbb.RoleViewer {
+		return errors.New("could not change role: unknown role " + role)
+	}
+	return c.moderatorCall("change role", bbb.ChangeRoleCall, userID, role)
+}
+
+// AssignPresenter makes the user the presenter of the meeting
+func (c *Client) AssignPresenter(userID string) error {
+	return c.moderatorCall("assign presenter", bbb.AssignPresenterCall, userID)
+}
@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"testing"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for IsModerator and the moderator actions with a role that is changed after the first call
+func TestIsModerator(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("users", "doc1", map[string]interface{}{"userId": "w_bot", "name": "bot", "role": bbb.RoleModerator})
+	client := newTestClient(t, server)
+
+	if !client.IsModerator() {
+		t.Errorf("IsModerator() FAILED: got false, expected true")
+	}
+	if err := client.EjectUser("w_1", false); err != nil {
+		t.Errorf("EjectUser() FAILED: %v", err)
+	}
+
+	server.Change("users", "doc1", map[string]interface{}{"role": bbb.RoleViewer})
+	waitFor(t, "the new role", func() bool { return !client.IsModerator() })
+	if err := client.AssignPresenter("w_1"); err == nil {
+		t.Errorf("AssignPresenter() FAILED: no error for a viewer")
+	}
+
+	if removes := countMessages(server, "method", "removeUser"); removes != 1 {
+		t.Errorf("EjectUser() FAILED: server got %d removeUser calls, expected 1", removes)
+	} else if presenters := countMessages(server, "method", "assignPresenter"); presenters != 0 {
+		t.Errorf("AssignPresenter() FAILED: server got %d assignPresenter calls, expected 0", presenters)
+	} else {
+		t.Logf("IsModerator() PASSED")
+	}
+}
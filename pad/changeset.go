@@ -11,6 +11,7 @@ import (
 	"syscall"
 	"time"
 
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
 	ch "github.com/bigbluebutton-bot/bigbluebutton-bot/pad/changesetproto"
 
 	"google.golang.org/grpc"
@@ -35,6 +36,8 @@ type ChangesetClient struct {
 	client ch.ChangesetClient
 	ctx    context.Context
 	cancel context.CancelFunc
+
+	logger logger.Logger
 }
 
 func NewChangesetClient(ip string, port string) *ChangesetClient {
@@ -44,7 +47,17 @@ func NewChangesetClient(ip string, port string) *ChangesetClient {
 
 		Changsetserverpath: "./.changsetserver",
 		Downloadeurl:       "https://github.com/bigbluebutton-bot/changeset-grpc",
+
+		logger: logger.Default(),
+	}
+}
+
+// SetLogger sets the logger for the changeset client. *slog.Logger can be used.
+func (cc *ChangesetClient) SetLogger(l logger.Logger) {
+	if l == nil {
+		l = logger.Nop()
 	}
+	cc.logger = l
 }
 
 type submoduleInfo struct {
@@ -143,7 +156,7 @@ func (cc *ChangesetClient) downloadAndInstallChangesetServer() error {
 	}
 
 	// install etherpad
-	if err := installEtherpad(cc.Changsetserverpath); err != nil {
+	if err := installEtherpad(cc.Changsetserverpath, cc.logger); err != nil {
 		return err
 	}
 
@@ -174,7 +187,7 @@ func (cc *ChangesetClient) StartChangesetServer() error {
 		// run the command
 		err = cc.changsetServerProcess.Run()
 		if err != nil {
-			fmt.Println("error while starting the changeset server: ", err)
+			cc.logger.Error("error while starting the changeset server", "error", err)
 			return
 		}
 	}()
@@ -196,7 +209,7 @@ func (cc *ChangesetClient) StartChangesetServer() error {
 	for i := 0; i < 10; i++ {
 		err = cc.autoConnect()
 		if err != nil {
-			fmt.Println("could not connect to changeset server: ", err)
+			cc.logger.Warn("could not connect to changeset server", "error", err)
 		}
 		// send ping
 		_, err = cc.client.Ping(cc.ctx, &ch.Nothing{})
@@ -210,7 +223,7 @@ func (cc *ChangesetClient) StartChangesetServer() error {
 		}
 	}
 
-	fmt.Println("Changeset server started successfully")
+	cc.logger.Info("changeset server started successfully", "host", cc.ip, "port", cc.port)
 	return nil
 }
 
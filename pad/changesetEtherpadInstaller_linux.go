@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
 )
 
-func installEtherpad(folderPath string) error {
+func installEtherpad(folderPath string, log logger.Logger) error {
 	// Source constants and useful functions.
 	// Note: This might not have an effect in Go since environment variables sourced here won't be available to subsequent commands.
 	cmd := exec.Command("sh", "-c", `. src/bin/functions.sh`)
@@ -18,7 +20,7 @@ func installEtherpad(folderPath string) error {
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to source constants and functions: %v", err)
 	}
-	fmt.Println("sourced constants and functions")
+	log.Info("sourced constants and functions")
 
 	// Prepare the environment by installing dependencies.
 	cmd = exec.Command("sh", "-c", `src/bin/installDeps.sh`)
@@ -28,7 +30,7 @@ func installEtherpad(folderPath string) error {
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to install dependencies: %v", err)
 	}
-	fmt.Println("installed dependencies")
+	log.Info("installed dependencies")
 
 	return nil
 }
\ No newline at end of file
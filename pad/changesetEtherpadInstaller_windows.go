@@ -10,6 +10,8 @@ import (
 	"strings"
 	"syscall"
 	"unsafe"
+
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
 )
 
 type SHELLEXECUTEINFO struct {
@@ -47,7 +49,7 @@ func ShellExecuteEx(lpExecInfo *SHELLEXECUTEINFO) bool {
 }
 
 
-func installEtherpad(folderPath string) error {
+func installEtherpad(folderPath string, log logger.Logger) error {
 
 		// Check if the file or directory exists
 		path := strings.ReplaceAll(folderPath, "/", `\`) + `\etherpad-lite\node_modules\ep_etherpad-lite`
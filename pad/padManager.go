@@ -3,7 +3,6 @@ package pad
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
@@ -16,6 +15,8 @@ import (
 	goSocketio "github.com/bigbluebutton-bot/golang-socketio"
 	goSocketioTransport "github.com/bigbluebutton-bot/golang-socketio/transport"
 	"golang.org/x/net/publicsuffix"
+
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
 )
 
 type Status int
@@ -55,6 +56,9 @@ type Pad struct {
 
 	// status of the pad
 	status Status
+
+	// all log messages are written to the logger (see SetLogger)
+	logger logger.Logger
 }
 
 // Create new pad
@@ -102,7 +106,24 @@ func NewPad(short, lang, url string, wsURL string, sessionToken string, padId st
 		LanguageName:      lang,
 
 		status: DISCONNECTED,
+
+		logger: logger.Default(),
+	}
+}
+
+// SetLogger sets the logger for the pad and its changeset client. *slog.Logger can be used.
+// Tokens and other secrets are redacted before they are logged.
+func (p *Pad) SetLogger(l logger.Logger) {
+	if l == nil {
+		l = logger.Nop()
 	}
+	p.logger = l
+	p.ChangesetClient.SetLogger(logger.With(l, "component", "changeset"))
+}
+
+// Returns the logger with the fields of the pad
+func (p *Pad) log() logger.Logger {
+	return logger.With(p.logger, "pad", p.PadId, "language", p.ShortLanguageName)
 }
 
 func getCookieByName(cookies []*http.Cookie, name string) string {
@@ -199,7 +220,7 @@ func (p *Pad) Connect() error {
 		p.status = DISCONNECTED
 		return err
 	} else {
-		fmt.Println("Connecting...")
+		p.log().Debug("connecting")
 	}
 
 	return nil
@@ -220,7 +241,7 @@ func (p *Pad) OnDisconnect(f func()) {
 }
 
 func (p *Pad) onConnect(h *goSocketio.Channel) {
-	fmt.Println("Connected")
+	p.log().Info("connected")
 	// set status
 	p.status = CONNECTED
 
@@ -255,7 +276,7 @@ func (p *Pad) onConnect(h *goSocketio.Channel) {
 }
 
 func (p *Pad) onDisconnect(h *goSocketio.Channel) {
-	fmt.Println("Disconnected")
+	p.log().Info("disconnected")
 
 	p.status = DISCONNECTED
 
@@ -269,13 +290,11 @@ func (p *Pad) onInitMessage(h *goSocketio.Channel, args ReceveClientReady) {
 		p.Text = args.Data.CollabClientVars.InitialAttributedText.Text
 		p.Attribs = args.Data.CollabClientVars.InitialAttributedText.Attribs
 		p.BaseRev = args.Data.CollabClientVars.Rev
-		fmt.Println("author:", p.AuthorID)
-		fmt.Printf("old text (already in pad):\"%s\"\n", p.Text)
-		fmt.Println("attribs:", p.Attribs)
+		p.log().Debug("initialized", "author", p.AuthorID, "textLength", len(p.Text), "attribs", p.Attribs)
 
 		// Connect to server
 		if err := p.ChangesetClient.Connect(); err != nil {
-			fmt.Println(err)
+			p.log().Error("could not connect to changeset server", "error", err)
 			p.Client.Close()
 			return
 		}
@@ -287,7 +306,6 @@ func (p *Pad) onInitMessage(h *goSocketio.Channel, args ReceveClientReady) {
 }
 
 func (p *Pad) onMessage(h *goSocketio.Channel, mapData interface{}) {
-	fmt.Println(mapData)
 	// Convert map to json string
 	jsonStr, err := json.Marshal(mapData)
 	if err != nil {
@@ -307,7 +325,7 @@ func (p *Pad) onMessage(h *goSocketio.Channel, mapData interface{}) {
 	}
 
 	// Switch datatype
-	fmt.Println(datatype.Data.Type)
+	p.log().Debug("message received", "type", datatype.Data.Type)
 
 }
 
@@ -352,8 +370,6 @@ func (p *Pad) SetText(text string) error {
 	newtext := text
 	oldtext := p.Text
 
-	fmt.Println("Old text: ", oldtext)
-	fmt.Println("New text: ", newtext)
 
 	// changeset := generateChangeset(p.Text, text)
 	changeset, err := p.ChangesetClient.GenerateChangeset(oldtext, newtext, p.Attribs)
@@ -361,7 +377,7 @@ func (p *Pad) SetText(text string) error {
 		return err
 	}
 
-	fmt.Println("Generated changeset: ", changeset)
+	p.log().Debug("generated changeset", "oldLength", len(oldtext), "newLength", len(newtext), "changesetLength", len(changeset))
 
 	p.Text = text
 	// "Z:1>5*0+5$Hello"
@@ -400,12 +416,6 @@ func (p *Pad) SetText(text string) error {
 			},
 		},
 	}
-	// conver commandTyping to json
-	jsonmsg, err := json.Marshal(commandTyping)
-	if err == nil {
-		fmt.Println(string(jsonmsg))
-	}
-
 	err = p.Client.Emit("message", commandTyping)
 	if err != nil {
 		return err
@@ -0,0 +1,86 @@
+package sfutest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/media/oggreader"
+	"github.com/pion/webrtc/v3/pkg/media/oggwriter"
+)
+
+// The duration of a packet, if it is not known from the ogg file
+const defaultPacketDuration = 20 * time.Millisecond
+
+// Packet is an opus packet of an ogg file
+type Packet struct {
+	Data     []byte
+	Duration time.Duration
+}
+
+// ReadOpusFile reads the opus packets of an ogg file (one packet per page, like pion's oggwriter writes them)
+func ReadOpusFile(path string) ([]Packet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("could not open opus file: " + err.Error())
+	}
+	defer file.Close()
+
+	reader, _, err := oggreader.NewWith(file)
+	if err != nil {
+		return nil, errors.New("could not read opus file: " + err.Error())
+	}
+
+	packets := []Packet{}
+	var lastGranule uint64
+	for {
+		data, header, err := reader.ParseNextPage()
+		if errors.Is(err, io.EOF) {
+			return packets, nil
+		}
+		if err != nil {
+			return nil, errors.New("could not read opus file: " + err.Error())
+		}
+		if bytes.HasPrefix(data, []byte("OpusTags")) || len(data) == 0 {
+			continue
+		}
+
+		// the granule position counts the samples at 48 kHz
+		duration := defaultPacketDuration
+		if header.GranulePosition > lastGranule {
+			duration = time.Duration(header.GranulePosition-lastGranule) * time.Second / 48000
+		}
+		lastGranule = header.GranulePosition
+
+		packets = append(packets, Packet{Data: data, Duration: duration})
+	}
+}
+
+// WriteOpusFile writes the opus packets into an ogg file (48 kHz), e.g. to create a known file for a test
+func WriteOpusFile(path string, channels uint16, packets []Packet) error {
+	writer, err := oggwriter.New(path, 48000, channels)
+	if err != nil {
+		return errors.New("could not create opus file: " + err.Error())
+	}
+
+	var timestamp uint32
+	for i, packet := range packets {
+		duration := packet.Duration
+		if duration <= 0 {
+			duration = defaultPacketDuration
+		}
+		timestamp += uint32(duration * 48000 / time.Second)
+		err := writer.WriteRTP(&rtp.Packet{
+			Header:  rtp.Header{SequenceNumber: uint16(i), Timestamp: timestamp},
+			Payload: packet.Data,
+		})
+		if err != nil {
+			writer.Close()
+			return errors.New("could not write opus file: " + err.Error())
+		}
+	}
+	return writer.Close()
+}
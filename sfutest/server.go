@@ -0,0 +1,210 @@
+// Package sfutest is a local stand-in for bbb-webrtc-sfu (and the parts of the bbb api the audio needs),
+// so the audio of the bot can be tested without a bbb server.
+//
+// The server answers getMeetings and stuns of the api and speaks the signalling protocol of the audio
+// (start, startResponse, subscriberAnswer, iceCandidate, webRTCAudioSuccess, ping and pong).
+// Every listener gets a pion peer connection that streams the opus packets of an ogg file.
+package sfutest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/api"
+)
+
+//  EXAMPLE in a test
+// --------------------
+// server, err := sfutest.NewServer(sfutest.Config{OpusFile: "testdata/audio.ogg"})
+// if err != nil {
+// 	t.Fatal(err)
+// }
+// defer server.Close()
+// client, err := bot.NewClient(..., server.APIURL, "secret", server.WebSocketURL)
+// ...
+// audioClient.ICE.IncludeLoopback = true
+// audioClient.ICE.RequireTURN = false
+
+// Config configures a Server
+type Config struct {
+	// the ogg/opus file that is streamed to every listener
+	OpusFile string
+	// start the file again at the end (otherwise the stream stops)
+	Loop bool
+
+	// the meeting that getMeetings returns
+	MeetingID         string
+	InternalMeetingID string
+	VoiceBridge       int
+}
+
+// Server is a local bbb-webrtc-sfu. It is started by NewServer.
+type Server struct {
+	// the urls for bot.NewClient
+	URL          string // http://127.0.0.1:port
+	APIURL       string // URL + /bigbluebutton/api/
+	WebSocketURL string // ws://127.0.0.1:port/bbb-webrtc-sfu
+
+	config  Config
+	packets []Packet
+
+	httpServer *httptest.Server
+	upgrader   websocket.Upgrader
+
+	mutex    *sync.Mutex
+	sessions []*session
+	messages []string // the ids of the received signalling messages
+}
+
+// NewServer starts a Server on a local port. The opus file is read completely.
+func NewServer(config Config) (*Server, error) {
+	if config.MeetingID == "" {
+		config.MeetingID = "sfutest-meeting"
+	}
+	if config.InternalMeetingID == "" {
+		config.InternalMeetingID = "sfutest-internal-meeting"
+	}
+	if config.VoiceBridge == 0 {
+		config.VoiceBridge = 70000
+	}
+
+	packets, err := ReadOpusFile(config.OpusFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) == 0 {
+		return nil, errors.New("opus file has no packets: " + config.OpusFile)
+	}
+
+	s := &Server{
+		config:  config,
+		packets: packets,
+
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+
+		mutex:    new(sync.Mutex),
+		sessions: []*session{},
+		messages: []string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bigbluebutton/api/getMeetings", s.handleGetMeetings)
+	mux.HandleFunc("/bigbluebutton/api/stuns", s.handleStuns)
+	mux.HandleFunc("/bbb-webrtc-sfu", s.handleWebSocket)
+	s.httpServer = httptest.NewServer(mux)
+
+	s.URL = s.httpServer.URL
+	s.APIURL = s.URL + "/bigbluebutton/api/"
+	s.WebSocketURL = "ws" + strings.TrimPrefix(s.URL, "http") + "/bbb-webrtc-sfu"
+	return s, nil
+}
+
+// MeetingID returns the (external) meeting id of the meeting of getMeetings
+func (s *Server) MeetingID() string {
+	return s.config.MeetingID
+}
+
+// InternalMeetingID returns the internal meeting id of the meeting of getMeetings
+func (s *Server) InternalMeetingID() string {
+	return s.config.InternalMeetingID
+}
+
+// Packets returns the opus packets that are streamed (in order)
+func (s *Server) Packets() []Packet {
+	return append([]Packet{}, s.packets...)
+}
+
+// Messages returns the ids of all signalling messages the server received (e.g. start, subscriberAnswer, ping)
+func (s *Server) Messages() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.messages...)
+}
+
+// Sessions returns how many listeners connected so far
+func (s *Server) Sessions() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.sessions)
+}
+
+// Disconnect closes the websockets and peer connections of all listeners (e.g. to test a rejoin)
+func (s *Server) Disconnect() {
+	s.mutex.Lock()
+	sessions := append([]*session{}, s.sessions...)
+	s.mutex.Unlock()
+
+	for _, session := range sessions {
+		session.close()
+	}
+}
+
+// Close disconnects all listeners and stops the server
+func (s *Server) Close() {
+	s.Disconnect()
+	s.httpServer.Close()
+}
+
+// getMeetings with the one meeting of the config. The checksum is not checked.
+func (s *Server) handleGetMeetings(w http.ResponseWriter, r *http.Request) {
+	response := api.Responsegetmeetings{
+		ReturnCode: "SUCCESS",
+		Meetings: []api.Meeting{{
+			MeetingName: s.config.MeetingID,
+			MeetingID:   s.config.MeetingID,
+			InternalID:  s.config.InternalMeetingID,
+			CreateTime:  time.Now().UnixMilli(),
+			VoiceBridge: s.config.VoiceBridge,
+			Running:     true,
+		}},
+	}
+
+	data, err := xml.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	w.Write(data)
+}
+
+// stuns without STUN and TURN servers (everything is local)
+func (s *Server) handleStuns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stunServers":         []interface{}{},
+		"turnServers":         []interface{}{},
+		"remoteIceCandidates": []interface{}{},
+	})
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	session := newSession(s, conn)
+	s.mutex.Lock()
+	s.sessions = append(s.sessions, session)
+	s.mutex.Unlock()
+
+	session.readLoop()
+}
+
+// Remember the id of a received message
+func (s *Server) received(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.messages = append(s.messages, id)
+}
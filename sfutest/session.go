@@ -0,0 +1,271 @@
+package sfutest
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media"
+)
+
+// How long the peer connection of a listener may take to connect
+const connectTimeout = 30 * time.Second
+
+// session is one websocket connection of a listener with its peer connection
+type session struct {
+	server *Server
+
+	conn       *websocket.Conn
+	writeMutex *sync.Mutex
+
+	mutex             *sync.Mutex
+	peerConnection    *webrtc.PeerConnection
+	track             *webrtc.TrackLocalStaticSample
+	voiceBridge       int
+	remoteSet         bool
+	pendingCandidates []webrtc.ICECandidateInit
+
+	closeOnce *sync.Once
+	done      chan struct{}
+}
+
+// A signalling message of the bot
+type message struct {
+	ID                string                   `json:"id"`
+	Type              string                   `json:"type"`
+	Role              string                   `json:"role"`
+	InternalMeetingID string                   `json:"internalMeetingId"`
+	VoiceBridge       int                      `json:"voiceBridge"`
+	SdpOffer          string                   `json:"sdpOffer"` // start (publisher) and subscriberAnswer
+	Candidate         *webrtc.ICECandidateInit `json:"candidate"`
+}
+
+func newSession(server *Server, conn *websocket.Conn) *session {
+	return &session{
+		server: server,
+
+		conn:       conn,
+		writeMutex: new(sync.Mutex),
+
+		mutex:             new(sync.Mutex),
+		pendingCandidates: []webrtc.ICECandidateInit{},
+
+		closeOnce: new(sync.Once),
+		done:      make(chan struct{}),
+	}
+}
+
+// Read the messages until the websocket is closed
+func (s *session) readLoop() {
+	defer s.close()
+
+	for {
+		var msg message
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		s.server.received(msg.ID)
+
+		var err error
+		switch msg.ID {
+		case "start":
+			err = s.start(msg)
+		case "subscriberAnswer":
+			err = s.subscriberAnswer(msg)
+		case "iceCandidate":
+			err = s.addCandidate(msg)
+		case "ping":
+			err = s.send(map[string]interface{}{"id": "pong"})
+		case "stop":
+			return
+		}
+
+		if err != nil {
+			s.send(map[string]interface{}{
+				"id":    "webRTCAudioError",
+				"type":  "audio",
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+}
+
+func (s *session) send(message interface{}) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	return s.conn.WriteJSON(message)
+}
+
+// start of a listener: make the offer with the audio track
+func (s *session) start(msg message) error {
+	if msg.Role != "recv" {
+		return errors.New("only listen only (role recv) is supported, not " + msg.Role)
+	}
+	if msg.InternalMeetingID != s.server.config.InternalMeetingID || msg.VoiceBridge != s.server.config.VoiceBridge {
+		return errors.New("unknown meeting or voice bridge")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.peerConnection != nil {
+		return errors.New("already started")
+	}
+	s.voiceBridge = msg.VoiceBridge
+
+	// everything is local: loopback candidates and no STUN/TURN servers
+	settings := webrtc.SettingEngine{}
+	settings.SetIncludeLoopbackCandidate(true)
+	settings.SetNetworkTypes([]webrtc.NetworkType{webrtc.NetworkTypeUDP4})
+	mediaEngine := &webrtc.MediaEngine{}
+	if err := mediaEngine.RegisterDefaultCodecs(); err != nil {
+		return err
+	}
+	webrtcapi := webrtc.NewAPI(webrtc.WithMediaEngine(mediaEngine), webrtc.WithSettingEngine(settings))
+
+	peerConnection, err := webrtcapi.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		return err
+	}
+	s.peerConnection = peerConnection
+
+	track, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{
+		MimeType:  webrtc.MimeTypeOpus,
+		ClockRate: 48000,
+		Channels:  2,
+	}, "audio", "sfutest")
+	if err != nil {
+		return err
+	}
+	s.track = track
+	if _, err := peerConnection.AddTransceiverFromTrack(track, webrtc.RTPTransceiverInit{Direction: webrtc.RTPTransceiverDirectionSendonly}); err != nil {
+		return err
+	}
+
+	peerConnection.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		switch state {
+		case webrtc.PeerConnectionStateConnected:
+			go s.stream()
+		case webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateClosed:
+			s.close()
+		}
+	})
+
+	// the offer with all candidates (the sfu does not trickle)
+	offer, err := peerConnection.CreateOffer(nil)
+	if err != nil {
+		return err
+	}
+	gatherComplete := webrtc.GatheringCompletePromise(peerConnection)
+	if err := peerConnection.SetLocalDescription(offer); err != nil {
+		return err
+	}
+	select {
+	case <-gatherComplete:
+	case <-time.After(connectTimeout):
+		return errors.New("timeout while gathering ice candidates")
+	}
+
+	return s.send(map[string]interface{}{
+		"id":        "startResponse",
+		"type":      "audio",
+		"role":      "recv",
+		"response":  "accepted",
+		"sdpAnswer": peerConnection.LocalDescription().SDP,
+	})
+}
+
+// subscriberAnswer: the answer of the listener
+func (s *session) subscriberAnswer(msg message) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.peerConnection == nil {
+		return errors.New("subscriberAnswer before start")
+	}
+	err := s.peerConnection.SetRemoteDescription(webrtc.SessionDescription{
+		Type: webrtc.SDPTypeAnswer,
+		SDP:  msg.SdpOffer,
+	})
+	if err != nil {
+		return err
+	}
+	s.remoteSet = true
+
+	for _, candidate := range s.pendingCandidates {
+		if err := s.peerConnection.AddICECandidate(candidate); err != nil {
+			return err
+		}
+	}
+	s.pendingCandidates = []webrtc.ICECandidateInit{}
+	return nil
+}
+
+// iceCandidate of the listener (trickle ICE)
+func (s *session) addCandidate(msg message) error {
+	if msg.Candidate == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.remoteSet {
+		s.pendingCandidates = append(s.pendingCandidates, *msg.Candidate)
+		return nil
+	}
+	return s.peerConnection.AddICECandidate(*msg.Candidate)
+}
+
+// Tell the listener that the audio flows and stream the packets of the file
+func (s *session) stream() {
+	s.mutex.Lock()
+	track := s.track
+	voiceBridge := s.voiceBridge
+	s.mutex.Unlock()
+
+	if err := s.send(map[string]interface{}{
+		"id":          "webRTCAudioSuccess",
+		"type":        "audio",
+		"voiceBridge": voiceBridge,
+		"success":     "MEDIA_FLOWING",
+	}); err != nil {
+		s.close()
+		return
+	}
+
+	for {
+		for _, packet := range s.server.packets {
+			if err := track.WriteSample(media.Sample{Data: packet.Data, Duration: packet.Duration}); err != nil {
+				return
+			}
+			select {
+			case <-s.done:
+				return
+			case <-time.After(packet.Duration):
+			}
+		}
+		if !s.server.config.Loop {
+			return
+		}
+	}
+}
+
+// Close the websocket and the peer connection
+func (s *session) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.writeMutex.Lock()
+		s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		s.writeMutex.Unlock()
+		s.conn.Close()
+
+		s.mutex.Lock()
+		peerConnection := s.peerConnection
+		s.mutex.Unlock()
+		if peerConnection != nil {
+			peerConnection.Close()
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package transcribe
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+	logger "github.com/bigbluebutton-bot/bigbluebutton-bot/logger"
+)
+
+// CaptionWriter is where the captions are written to (e.g. the *pad.Pad of client.CreateCapture)
+type CaptionWriter interface {
+	SetText(text string) error
+}
+
+// Pipeline is an audio.Sink that feeds the audio into a Transcriber and writes the results to the captions.
+// Every final result is a line of the captions. The partial result is shown in the last line until it is final.
+type Pipeline struct {
+	mutex *sync.Mutex
+
+	transcriber Transcriber
+	resampler   *audio.Resampler
+	captions    CaptionWriter
+
+	maxLines      int
+	maxLineLength int
+
+	lines   []string // final results
+	partial string   // the last partial result
+	written string   // text of the captions
+
+	onResult []func(Result)
+	done     chan struct{}
+	closed   bool
+
+	logger logger.Logger
+}
+
+// NewPipeline creates a Pipeline and starts to write the results of the transcriber to the captions.
+// captions can be nil, if only OnResult is used.
+func NewPipeline(transcriber Transcriber, captions CaptionWriter) *Pipeline {
+	p := &Pipeline{
+		mutex: new(sync.Mutex),
+
+		transcriber: transcriber,
+		resampler:   audio.NewSpeechResampler(transcriber),
+		captions:    captions,
+
+		lines:    []string{},
+		onResult: []func(Result){},
+		done:     make(chan struct{}),
+
+		logger: logger.Default(),
+	}
+	go p.resultLoop()
+	return p
+}
+
+// SetLogger sets the logger of the pipeline. *slog.Logger can be used.
+func (p *Pipeline) SetLogger(l logger.Logger) {
+	if l == nil {
+		l = logger.Nop()
+	}
+	p.mutex.Lock()
+	p.logger = l
+	p.mutex.Unlock()
+}
+
+// SetMaxLines sets how many lines are kept in the captions. The older lines are removed. 0 keeps all lines.
+func (p *Pipeline) SetMaxLines(lines int) {
+	p.mutex.Lock()
+	p.maxLines = lines
+	p.mutex.Unlock()
+}
+
+// SetMaxLineLength sets after how many characters a line is wrapped (at a space). 0 does not wrap.
+func (p *Pipeline) SetMaxLineLength(length int) {
+	p.mutex.Lock()
+	p.maxLineLength = length
+	p.mutex.Unlock()
+}
+
+// OnResult is called for every result of the transcriber (partial and final)
+func (p *Pipeline) OnResult(fun func(Result)) {
+	p.mutex.Lock()
+	p.onResult = append(p.onResult, fun)
+	p.mutex.Unlock()
+}
+
+// WritePCM resamples the frame and writes it to the transcriber
+func (p *Pipeline) WritePCM(frame audio.PCMFrame) error {
+	return p.resampler.WritePCM(frame)
+}
+
+// Close closes the transcriber and waits until its last results are written to the captions
+func (p *Pipeline) Close() error {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		<-p.done
+		return nil
+	}
+	p.closed = true
+	p.mutex.Unlock()
+
+	err := p.resampler.Close()
+	<-p.done
+	return err
+}
+
+// Text returns the text of the captions
+func (p *Pipeline) Text() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return captionText(p.lines, p.partial, p.maxLines, p.maxLineLength)
+}
+
+// Reads the results until the transcriber is closed
+func (p *Pipeline) resultLoop() {
+	defer close(p.done)
+
+	for result := range p.transcriber.Results() {
+		p.mutex.Lock()
+		listeners := append([]func(Result){}, p.onResult...)
+		p.mutex.Unlock()
+		for _, listener := range listeners {
+			go listener(result)
+		}
+
+		if err := p.addResult(result); err != nil {
+			p.log().Warn("could not write captions", "error", err)
+		}
+	}
+}
+
+// Adds the result to the lines and writes the captions if they changed.
+// The captions are written without holding the mutex, because SetText can take long (e.g. a request to the pad).
+// addResult is only called by resultLoop, so the captions are written in order.
+func (p *Pipeline) addResult(result Result) error {
+	p.mutex.Lock()
+	text := strings.Join(strings.Fields(result.Text), " ")
+	if result.Final {
+		p.partial = ""
+		if text != "" {
+			p.lines = append(p.lines, text)
+		}
+		// every line is at least one line of the captions, so older lines are not needed
+		if p.maxLines > 0 && len(p.lines) > p.maxLines {
+			p.lines = p.lines[len(p.lines)-p.maxLines:]
+		}
+	} else {
+		p.partial = text
+	}
+
+	writer := p.captions
+	captions := captionText(p.lines, p.partial, p.maxLines, p.maxLineLength)
+	changed := captions != p.written
+	p.mutex.Unlock()
+
+	if writer == nil || !changed {
+		return nil
+	}
+	if err := writer.SetText(captions); err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	p.written = captions
+	p.mutex.Unlock()
+	return nil
+}
+
+func (p *Pipeline) log() logger.Logger {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.logger
+}
+
+// Returns the text of the captions: one line per final result and the partial result in the last line.
+// Lines longer than maxLineLength are wrapped and only the last maxLines lines are kept (0 for no limit).
+func captionText(lines []string, partial string, maxLines int, maxLineLength int) string {
+	all := []string{}
+	for _, line := range lines {
+		all = append(all, wrapLine(line, maxLineLength)...)
+	}
+	if partial != "" {
+		all = append(all, wrapLine(partial, maxLineLength)...)
+	}
+
+	if maxLines > 0 && len(all) > maxLines {
+		all = all[len(all)-maxLines:]
+	}
+	return strings.Join(all, "\n")
+}
+
+// Wraps the line at the spaces. A word longer than maxLength is not split.
+func wrapLine(line string, maxLength int) []string {
+	if maxLength <= 0 || len([]rune(line)) <= maxLength {
+		return []string{line}
+	}
+
+	wrapped := []string{}
+	current := ""
+	for _, word := range strings.Fields(line) {
+		if current == "" {
+			current = word
+			continue
+		}
+		if len([]rune(current))+1+len([]rune(word)) > maxLength {
+			wrapped = append(wrapped, current)
+			current = word
+			continue
+		}
+		current += " " + word
+	}
+	if current != "" {
+		wrapped = append(wrapped, current)
+	}
+	return wrapped
+}
@@ -0,0 +1,221 @@
+package transcribe
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+)
+
+// Test for captionText (line handling of the captions)
+func TestCaptionText(t *testing.T) {
+	tests := []struct {
+		lines         []string
+		partial       string
+		maxLines      int
+		maxLineLength int
+		expected      string
+	}{
+		{
+			lines:    []string{},
+			partial:  "",
+			expected: "",
+		},
+		{
+			lines:    []string{},
+			partial:  "hello",
+			expected: "hello",
+		},
+		{
+			lines:    []string{"hello world", "how are you"},
+			partial:  "fine",
+			expected: "hello world\nhow are you\nfine",
+		},
+		{
+			lines:    []string{"one", "two", "three"},
+			partial:  "four",
+			maxLines: 2,
+			expected: "three\nfour",
+		},
+		{
+			lines:         []string{"the quick brown fox jumps"},
+			partial:       "",
+			maxLineLength: 10,
+			expected:      "the quick\nbrown fox\njumps",
+		},
+		{
+			lines:         []string{"a verylongword b"},
+			partial:       "",
+			maxLineLength: 5,
+			expected:      "a\nverylongword\nb",
+		},
+		{
+			lines:         []string{"the quick brown fox jumps"},
+			partial:       "over the dog",
+			maxLines:      2,
+			maxLineLength: 10,
+			expected:      "over the\ndog",
+		},
+	}
+
+	for num, test := range tests {
+		result := captionText(test.lines, test.partial, test.maxLines, test.maxLineLength)
+		if result != test.expected {
+			t.Errorf("captionText() %d FAILED: got %q, expected %q", num, result, test.expected)
+		} else {
+			t.Logf("captionText() %d PASSED", num)
+		}
+	}
+}
+
+// Transcriber that returns the results given by the test
+type testTranscriber struct {
+	mutex   *sync.Mutex
+	frames  []audio.PCMFrame
+	results chan Result
+	closed  bool
+}
+
+func newTestTranscriber() *testTranscriber {
+	return &testTranscriber{
+		mutex:   new(sync.Mutex),
+		frames:  []audio.PCMFrame{},
+		results: make(chan Result, 100),
+	}
+}
+
+func (t *testTranscriber) WritePCM(frame audio.PCMFrame) error {
+	t.mutex.Lock()
+	t.frames = append(t.frames, frame)
+	t.mutex.Unlock()
+	return nil
+}
+
+func (t *testTranscriber) Close() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.closed {
+		t.closed = true
+		close(t.results)
+	}
+	return nil
+}
+
+func (t *testTranscriber) Results() <-chan Result {
+	return t.results
+}
+
+// CaptionWriter that remembers every text.
+// It reads the text of the pipeline while it is written, like a slow writer that is used by others meanwhile.
+type testCaptions struct {
+	mutex    *sync.Mutex
+	pipeline *Pipeline
+	written  []string
+}
+
+func (c *testCaptions) SetText(text string) error {
+	c.pipeline.Text()
+
+	c.mutex.Lock()
+	c.written = append(c.written, text)
+	c.mutex.Unlock()
+	return nil
+}
+
+// Test for Pipeline (results written to the captions)
+func TestPipeline(t *testing.T) {
+	tests := []struct {
+		results  []Result
+		maxLines int
+		expected []string // every text written to the captions
+	}{
+		{
+			results: []Result{
+				{Text: "hello"},
+				{Text: "hello wor"},
+				{Text: "hello  world ", Final: true},
+			},
+			expected: []string{"hello", "hello wor", "hello world"},
+		},
+		{
+			results: []Result{
+				{Text: "same"},
+				{Text: "same"},
+				{Text: "", Final: true},
+			},
+			expected: []string{"same", ""},
+		},
+		{
+			results: []Result{
+				{Text: "one", Final: true},
+				{Text: "two", Final: true},
+				{Text: "three"},
+			},
+			maxLines: 2,
+			expected: []string{"one", "one\ntwo", "two\nthree"},
+		},
+	}
+
+	for num, test := range tests {
+		transcriber := newTestTranscriber()
+		captions := &testCaptions{mutex: new(sync.Mutex), written: []string{}}
+		p := NewPipeline(transcriber, captions)
+		captions.pipeline = p
+		p.SetMaxLines(test.maxLines)
+
+		wg := new(sync.WaitGroup)
+		wg.Add(len(test.results))
+		p.OnResult(func(Result) {
+			wg.Done()
+		})
+
+		for _, result := range test.results {
+			transcriber.results <- result
+		}
+		if err := p.Close(); err != nil {
+			t.Errorf("Pipeline %d FAILED: Close() returned %v", num, err)
+			continue
+		}
+		wg.Wait()
+
+		captions.mutex.Lock()
+		written := captions.written
+		captions.mutex.Unlock()
+		if !reflect.DeepEqual(written, test.expected) {
+			t.Errorf("Pipeline %d FAILED: got %q, expected %q", num, written, test.expected)
+		} else if p.Text() != test.expected[len(test.expected)-1] {
+			t.Errorf("Pipeline %d FAILED: Text() got %q, expected %q", num, p.Text(), test.expected[len(test.expected)-1])
+		} else {
+			t.Logf("Pipeline %d PASSED", num)
+		}
+	}
+}
+
+// Test for Pipeline.WritePCM (the transcriber gets 16 kHz mono)
+func TestPipelineWritePCM(t *testing.T) {
+	transcriber := newTestTranscriber()
+	p := NewPipeline(transcriber, nil)
+
+	// 20ms of 48 kHz stereo
+	frame := audio.PCMFrame{Samples: make([]int16, 960*2), SampleRate: 48000, Channels: 2}
+	if err := p.WritePCM(frame); err != nil {
+		t.Fatalf("Pipeline.WritePCM() FAILED: %v", err)
+	}
+	p.Close()
+
+	transcriber.mutex.Lock()
+	defer transcriber.mutex.Unlock()
+	samples := 0
+	for _, frame := range transcriber.frames {
+		if frame.SampleRate != audio.SpeechSampleRate || frame.Channels != audio.SpeechChannels {
+			t.Fatalf("Pipeline.WritePCM() FAILED: got %d Hz %d channels, expected %d Hz %d channels", frame.SampleRate, frame.Channels, audio.SpeechSampleRate, audio.SpeechChannels)
+		}
+		samples += len(frame.Samples)
+	}
+	if samples != 320 {
+		t.Errorf("Pipeline.WritePCM() FAILED: got %d samples, expected 320", samples)
+	} else {
+		t.Logf("Pipeline.WritePCM() PASSED")
+	}
+}
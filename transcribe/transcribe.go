@@ -0,0 +1,28 @@
+// Package transcribe turns the meeting audio into text (speech-to-text) and writes it as live captions.
+package transcribe
+
+import (
+	"time"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+)
+
+// Result is a transcription of a part of the audio
+type Result struct {
+	Text     string
+	Language string
+	// Final is false for a partial result. A partial result is replaced by the next result.
+	// A final result does not change anymore.
+	Final bool
+	// Start and End of the text in the audio (since the transcriber was started), if the transcriber knows them
+	Start time.Duration
+	End   time.Duration
+}
+
+// Transcriber is a speech-to-text engine that works on a stream of audio.
+// It gets the audio as PCM with audio.SpeechSampleRate (16 kHz) and audio.SpeechChannels (mono).
+// The results are sent to the Results channel, which has to be closed after Close (and after the last results).
+type Transcriber interface {
+	audio.Sink
+	Results() <-chan Result
+}
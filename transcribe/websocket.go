@@ -0,0 +1,173 @@
+package transcribe
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+)
+
+// How long Close waits for the last results of the server
+const webSocketCloseTimeout = 5 * time.Second
+
+// WebSocketTranscriber is a Transcriber for a speech-to-text server with the websocket protocol of vosk-server
+// (e.g. ws://localhost:2700). Other servers (e.g. whisper) can use the same protocol:
+//   - the client sends {"config": {"sample_rate": 16000, "language": "en"}}
+//   - the client sends the audio as binary messages (16 bit little endian PCM, mono)
+//   - the server answers with {"partial": "..."} or with a final {"text": "...", "result": [{"start": 0.5, "end": 0.9, "word": "..."}]}
+//   - the client sends {"eof": 1} and the server sends the last result
+type WebSocketTranscriber struct {
+	writeMutex *sync.Mutex
+	conn       *websocket.Conn
+	language   string
+	closed     bool
+
+	results chan Result
+	done    chan struct{}
+}
+
+type webSocketConfig struct {
+	Config webSocketConfigData `json:"config"`
+}
+
+type webSocketConfigData struct {
+	SampleRate int    `json:"sample_rate"`
+	Language   string `json:"language,omitempty"`
+}
+
+type webSocketEOF struct {
+	EOF int `json:"eof"`
+}
+
+type webSocketResult struct {
+	Partial *string               `json:"partial"`
+	Text    *string               `json:"text"`
+	Result  []webSocketResultWord `json:"result"`
+}
+
+type webSocketResultWord struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Word  string  `json:"word"`
+	Conf  float64 `json:"conf"`
+}
+
+// DialWebSocketTranscriber connects to the speech-to-text server. language can be empty, if the server has only one.
+func DialWebSocketTranscriber(url string, language string) (*WebSocketTranscriber, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, errors.New("could not connect to speech-to-text server: " + err.Error())
+	}
+
+	config := webSocketConfig{
+		Config: webSocketConfigData{
+			SampleRate: audio.SpeechSampleRate,
+			Language:   language,
+		},
+	}
+	if err := conn.WriteJSON(config); err != nil {
+		conn.Close()
+		return nil, errors.New("could not send config to speech-to-text server: " + err.Error())
+	}
+
+	t := &WebSocketTranscriber{
+		writeMutex: new(sync.Mutex),
+		conn:       conn,
+		language:   language,
+
+		results: make(chan Result, 100),
+		done:    make(chan struct{}),
+	}
+	go t.readLoop()
+	return t, nil
+}
+
+// Results returns the channel of the results. It is closed when the connection ends.
+func (t *WebSocketTranscriber) Results() <-chan Result {
+	return t.results
+}
+
+// WritePCM sends the audio to the server. It has to be 16 kHz mono (see audio.NewSpeechResampler).
+func (t *WebSocketTranscriber) WritePCM(frame audio.PCMFrame) error {
+	if frame.SampleRate != audio.SpeechSampleRate || frame.Channels != audio.SpeechChannels {
+		return errors.New("speech-to-text server needs 16 kHz mono audio")
+	}
+
+	data := make([]byte, len(frame.Samples)*2)
+	for i, sample := range frame.Samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+	}
+
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+	if t.closed {
+		return errors.New("speech-to-text connection is closed")
+	}
+	return t.conn.WriteMessage(websocket.BinaryMessage, data)
+}
+
+// Close asks the server for the last result and closes the connection
+func (t *WebSocketTranscriber) Close() error {
+	t.writeMutex.Lock()
+	if t.closed {
+		t.writeMutex.Unlock()
+		return nil
+	}
+	t.closed = true
+	err := t.conn.WriteJSON(webSocketEOF{EOF: 1})
+	t.writeMutex.Unlock()
+
+	// the server closes the connection after the last result
+	if err == nil {
+		select {
+		case <-t.done:
+		case <-time.After(webSocketCloseTimeout):
+		}
+	}
+
+	if closeErr := t.conn.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	<-t.done
+	return err
+}
+
+// Reads the results until the connection is closed
+func (t *WebSocketTranscriber) readLoop() {
+	defer close(t.done)
+	defer close(t.results)
+
+	for {
+		var message webSocketResult
+		if err := t.conn.ReadJSON(&message); err != nil {
+			return
+		}
+
+		switch {
+		case message.Text != nil:
+			result := Result{
+				Text:     *message.Text,
+				Language: t.language,
+				Final:    true,
+			}
+			if len(message.Result) > 0 {
+				result.Start = seconds(message.Result[0].Start)
+				result.End = seconds(message.Result[len(message.Result)-1].End)
+			}
+			t.results <- result
+		case message.Partial != nil:
+			t.results <- Result{
+				Text:     *message.Partial,
+				Language: t.language,
+			}
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,122 @@
+package transcribe
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/audio"
+)
+
+// Speech-to-text server with the protocol of vosk-server.
+// It answers every audio message with a partial result and the eof with a final result.
+// The config and the audio it received are sent to received.
+func newTestWebSocketServer(t *testing.T, received chan<- interface{}) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("test server: could not upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		var config webSocketConfig
+		if err := conn.ReadJSON(&config); err != nil {
+			t.Errorf("test server: could not read config: %v", err)
+			return
+		}
+		received <- config
+
+		samples := []int16{}
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			if messageType == websocket.BinaryMessage {
+				for i := 0; i+1 < len(data); i += 2 {
+					samples = append(samples, int16(binary.LittleEndian.Uint16(data[i:])))
+				}
+				conn.WriteMessage(websocket.TextMessage, []byte(`{"partial": "hello"}`))
+				continue
+			}
+
+			// eof
+			received <- samples
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"text": "hello world", "result": [{"start": 0.5, "end": 0.9, "word": "hello"}, {"start": 1.0, "end": 1.25, "word": "world"}]}`))
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		}
+	}))
+}
+
+// Test for WebSocketTranscriber (config, audio and results)
+func TestWebSocketTranscriber(t *testing.T) {
+	received := make(chan interface{}, 2)
+	server := newTestWebSocketServer(t, received)
+	defer server.Close()
+
+	transcriber, err := DialWebSocketTranscriber("ws"+strings.TrimPrefix(server.URL, "http"), "en")
+	if err != nil {
+		t.Fatalf("DialWebSocketTranscriber() FAILED: %v", err)
+	}
+
+	config := (<-received).(webSocketConfig)
+	if config.Config.SampleRate != audio.SpeechSampleRate || config.Config.Language != "en" {
+		t.Errorf("DialWebSocketTranscriber() FAILED: got config %+v, expected %d Hz and language en", config.Config, audio.SpeechSampleRate)
+	}
+
+	if err := transcriber.WritePCM(audio.PCMFrame{Samples: make([]int16, 960), SampleRate: 48000, Channels: 1}); err == nil {
+		t.Errorf("WebSocketTranscriber.WritePCM() FAILED: 48 kHz audio was not rejected")
+	}
+	if err := transcriber.WritePCM(audio.PCMFrame{Samples: []int16{1, -1, 32767, -32768}, SampleRate: audio.SpeechSampleRate, Channels: audio.SpeechChannels}); err != nil {
+		t.Fatalf("WebSocketTranscriber.WritePCM() FAILED: %v", err)
+	}
+
+	// the partial result is read before the eof is sent
+	select {
+	case result := <-transcriber.Results():
+		if result.Text != "hello" || result.Final {
+			t.Errorf("WebSocketTranscriber.Results() FAILED: got %+v, expected partial result hello", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("WebSocketTranscriber.Results() FAILED: no partial result")
+	}
+
+	if err := transcriber.Close(); err != nil {
+		t.Errorf("WebSocketTranscriber.Close() FAILED: %v", err)
+	}
+
+	samples := (<-received).([]int16)
+	if len(samples) != 4 || samples[0] != 1 || samples[1] != -1 || samples[2] != 32767 || samples[3] != -32768 {
+		t.Errorf("WebSocketTranscriber.WritePCM() FAILED: server got %v, expected [1 -1 32767 -32768]", samples)
+	}
+
+	results := []Result{}
+	for result := range transcriber.Results() {
+		results = append(results, result)
+	}
+	expected := Result{
+		Text:     "hello world",
+		Language: "en",
+		Final:    true,
+		Start:    500 * time.Millisecond,
+		End:      1250 * time.Millisecond,
+	}
+	if len(results) != 1 || results[0] != expected {
+		t.Errorf("WebSocketTranscriber.Results() FAILED: got %+v, expected %+v", results, expected)
+	} else {
+		t.Logf("WebSocketTranscriber PASSED")
+	}
+
+	if err := transcriber.WritePCM(audio.PCMFrame{Samples: []int16{0}, SampleRate: audio.SpeechSampleRate, Channels: audio.SpeechChannels}); err == nil {
+		t.Errorf("WebSocketTranscriber.WritePCM() FAILED: no error after Close()")
+	}
+}
@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+)
+
+//  EXAMPLE in main.go
+// --------------------
+// slide, err := client.GetCurrentSlide()
+// if err != nil {
+// 	panic(err)
+// }
+// err = client.DrawAnnotations(slide.ID,
+// 	bbb.NewShapeAnnotation(bbb.AnnotationRectangle, 0xff0000, 0.5, []float64{10, 10, 50, 30}, false),
+// 	bbb.NewTextAnnotation("Hello", 12, 12, 30, 10, 0x000000, 2),
+// )
+// if err != nil {
+// 	panic(err)
+// }
+
+// DrawAnnotations draws the annotations (see bbb.NewShapeAnnotation, bbb.NewTextAnnotation and
+// bbb.NewStickyNoteAnnotation) on the whiteboard (the id of the slide). The bot has to be the presenter
+// or needs access to the whiteboard (see AddWhiteboardAccess).
+func (c *Client) DrawAnnotations(whiteboardID string, annotations ...bbb.Annotation) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	// The HTML5 client uses the user id and the time as id of an annotation
+	timestemp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	// The annotations of the caller are not changed
+	annotations = append([]bbb.Annotation{}, annotations...)
+	for i := range annotations {
+		if annotations[i].ID == "" {
+			annotations[i].ID = c.InternalUserID + "-" + timestemp + "-" + strconv.Itoa(i)
+		}
+		annotations[i].WbID = whiteboardID
+		annotations[i].UserID = c.InternalUserID
+		info := make(map[string]interface{}, len(annotations[i].AnnotationInfo)+2)
+		for key, value := range annotations[i].AnnotationInfo {
+			info[key] = value
+		}
+		info["id"] = annotations[i].ID
+		info["whiteboardId"] = whiteboardID
+		annotations[i].AnnotationInfo = info
+	}
+
+	_, err := c.ddpCall(bbb.SendBulkAnnotationsCall, annotations)
+	if err != nil {
+		return errors.New("could not draw annotations: " + err.Error())
+	}
+
+	return nil
+}
+
+// UndoAnnotation removes the last annotation the bot has drawn on the whiteboard
+func (c *Client) UndoAnnotation(whiteboardID string) error {
+	_, err := c.ddpCall(bbb.UndoAnnotationCall, whiteboardID)
+	if err != nil {
+		return errors.New("could not undo annotation: " + err.Error())
+	}
+
+	return nil
+}
+
+// ClearWhiteboard removes all annotations from the whiteboard. Only the presenter can remove
+// the annotations of all users, others only remove their own annotations.
+func (c *Client) ClearWhiteboard(whiteboardID string) error {
+	_, err := c.ddpCall(bbb.ClearWhiteboardCall, whiteboardID)
+	if err != nil {
+		return errors.New("could not clear whiteboard: " + err.Error())
+	}
+
+	return nil
+}
+
+// AddWhiteboardAccess lets all users draw on the whiteboard (multi-user whiteboard). The bot has to be the presenter.
+func (c *Client) AddWhiteboardAccess(whiteboardID string) error {
+	return c.presenterCall("add whiteboard access", bbb.AddGlobalAccessCall, whiteboardID)
+}
+
+// RemoveWhiteboardAccess lets only the presenter draw on the whiteboard. The bot has to be the presenter.
+func (c *Client) RemoveWhiteboardAccess(whiteboardID string) error {
+	return c.presenterCall("remove whiteboard access", bbb.RemoveGlobalAccessCall, whiteboardID)
+}
+
+// GetWhiteboardUsers returns the ids of the users that can draw on the whiteboard besides the presenter
+func (c *Client) GetWhiteboardUsers(whiteboardID string) ([]string, error) {
+	if err := c.ddpSubscribeOnce(bbb.WhiteboardMultiUserSub); err != nil {
+		return nil, err
+	}
+
+	// The documents as they were received (a copy, so the ddpClient can change the collection meanwhile)
+	collection, _ := c.getSub(bbb.WhiteboardMultiUserSub)
+	docs, _ := c.ddpEventHandler.getDocs(collection)
+
+	for _, doc := range docs {
+		multiUser := bbb.ConvertInToWhiteboardMultiUser(doc)
+		if multiUser.WhiteboardId == whiteboardID {
+			return multiUser.MultiUser, nil
+		}
+	}
+	return []string{}, nil
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	bbb "github.com/bigbluebutton-bot/bigbluebutton-bot/bbb"
+	"github.com/bigbluebutton-bot/bigbluebutton-bot/ddptest"
+)
+
+// Test for the annotation events of one slide
+func TestSlideAnnotations(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	added := make(chan []bbb.Annotation, 10)
+	removed := make(chan bbb.AnnotationsRemoved, 10)
+	if err := client.OnSlideAnnotationsAdded("slide-1", func(annotations []bbb.Annotation) { added <- annotations }); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.OnSlideAnnotationsRemoved("slide-1", func(r bbb.AnnotationsRemoved) { removed <- r }); err != nil {
+		t.Fatal(err)
+	}
+
+	collection := "stream-annotations-" + testInternalMeetingID
+	server.Stream(collection, "added", map[string]interface{}{"annotations": []interface{}{
+		map[string]interface{}{"id": "a1", "annotationType": bbb.AnnotationLine, "wbId": "slide-1"},
+		map[string]interface{}{"id": "a2", "annotationType": bbb.AnnotationLine, "wbId": "slide-2"},
+	}})
+	server.Stream(collection, "removed", map[string]interface{}{"whiteboardId": "slide-2", "shapeId": "a2"})
+	server.Stream(collection, "removed", map[string]interface{}{"whiteboardId": "slide-1", "shapeId": "a1"})
+
+	select {
+	case annotations := <-added:
+		if len(annotations) != 1 || annotations[0].ID != "a1" {
+			t.Errorf("OnSlideAnnotationsAdded() FAILED: got %+v, expected a1", annotations)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("OnSlideAnnotationsAdded() FAILED: no annotations were received")
+	}
+	select {
+	case r := <-removed:
+		if r.ShapeID != "a1" {
+			t.Errorf("OnSlideAnnotationsRemoved() FAILED: got %+v, expected a1", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("OnSlideAnnotationsRemoved() FAILED: no removal was received")
+	}
+
+	select {
+	case annotations := <-added:
+		t.Errorf("OnSlideAnnotationsAdded() FAILED: got %+v, expected no more annotations", annotations)
+	case r := <-removed:
+		t.Errorf("OnSlideAnnotationsRemoved() FAILED: got %+v, expected no more removals", r)
+	case <-time.After(50 * time.Millisecond):
+		t.Logf("annotation events PASSED")
+	}
+}
+
+// Test for DrawAnnotations (the annotations of the caller are not changed) and GetWhiteboardUsers
+func TestDrawAnnotations(t *testing.T) {
+	server := ddptest.NewServer()
+	defer server.Close()
+	server.Add("whiteboard-multi-user", "doc1", map[string]interface{}{"whiteboardId": "slide-1", "multiUser": []interface{}{"w_1"}})
+	client := newTestClient(t, server)
+
+	annotation := bbb.NewShapeAnnotation(bbb.AnnotationLine, 0xff0000, 1, []float64{1, 2, 3, 4}, false)
+	info := map[string]interface{}{}
+	for key, value := range annotation.AnnotationInfo {
+		info[key] = value
+	}
+	annotations := []bbb.Annotation{annotation}
+	if err := client.DrawAnnotations("slide-1", annotations...); err != nil {
+		t.Fatalf("DrawAnnotations() FAILED: %v", err)
+	}
+	if annotations[0].ID != "" || annotations[0].WbID != "" || !reflect.DeepEqual(annotations[0].AnnotationInfo, info) {
+		t.Errorf("DrawAnnotations() FAILED: the annotation of the caller was changed to %+v", annotations[0])
+	}
+
+	for _, msg := range server.Messages() {
+		if msg.Msg != "method" || msg.Name != "sendBulkAnnotations" {
+			continue
+		}
+		sent, _ := msg.Params[0].([]interface{})
+		if len(sent) != 1 || sent[0].(map[string]interface{})["wbId"] != "slide-1" || sent[0].(map[string]interface{})["userId"] != "w_bot" {
+			t.Errorf("DrawAnnotations() FAILED: got %v, expected one annotation of w_bot on slide-1", sent)
+		}
+	}
+	if calls := countMessages(server, "method", "sendBulkAnnotations"); calls != 1 {
+		t.Errorf("DrawAnnotations() FAILED: server got %d sendBulkAnnotations calls, expected 1", calls)
+	}
+
+	users, err := client.GetWhiteboardUsers("slide-1")
+	if err != nil || !reflect.DeepEqual(users, []string{"w_1"}) {
+		t.Errorf("GetWhiteboardUsers() FAILED: got %v (%v), expected [w_1]", users, err)
+	} else {
+		t.Logf("DrawAnnotations() and GetWhiteboardUsers() PASSED")
+	}
+}